@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/pkg/errors"
+
+	"hexbot/internal/config"
+	"hexbot/internal/export"
+	"hexbot/internal/palette"
+)
+
+// runConvert translates a palette file from one format to another without touching the database.
+// Formats default to the files' extensions; "-" reads standard input or writes standard output.
+func runConvert(cfg *config.Config, log *logging.Logger, args []string) error {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	from := fs.String("from", "", "format of the input; gpl, ase, paintnet, css, scss, json or csv")
+	to := fs.String("to", "", "format of the output; any input format or svg")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		return errors.New("usage: convert [-from format] [-to format] input output")
+	}
+	in, out := fs.Arg(0), fs.Arg(1)
+
+	set, _, err := readPalette(in, *from)
+	if err != nil {
+		return err
+	}
+
+	f, err := paletteFormat(out, *to)
+	if err != nil {
+		return err
+	}
+	if out == "-" {
+		return export.Write(os.Stdout, f, *set)
+	}
+	file, err := os.Create(out)
+	if err != nil {
+		return errors.Wrap(err, "problem creating output file")
+	}
+	if err := export.Write(file, f, *set); err != nil {
+		file.Close()
+		return err
+	}
+	return errors.Wrap(file.Close(), "problem closing output file")
+}
+
+// runImport stores a palette file as a curated palette, credited to the file it came from.
+func runImport(cfg *config.Config, log *logging.Logger, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", "", "format of the file, from its extension when unset")
+	name := fs.String("name", "", "palette name, instead of the one in the file")
+	source := fs.String("source", "", "where the palette came from, the file name when unset")
+	author := fs.String("author", "", "who made the palette")
+	license := fs.String("license", "", "the palette's license")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("usage: import [flags] file")
+	}
+	path := fs.Arg(0)
+
+	set, f, err := readPalette(path, *format)
+	if err != nil {
+		return err
+	}
+	if *name != "" {
+		set.Name = *name
+	}
+	if set.Name == "" {
+		set.Name = filepath.Base(path)
+	}
+	origin := palette.Origin{Source: *source, Format: string(f), Author: *author, License: *license}
+	if origin.Source == "" {
+		origin.Source = filepath.Base(path)
+	}
+
+	ctx := context.Background()
+	database, err := newDB(ctx, cfg, log)
+	if err != nil {
+		return errors.Wrap(err, "problem creating database")
+	}
+	defer database.Disconnect(context.Background())
+
+	s, err := newColourService(cfg, log, database, nil)
+	if err != nil {
+		return errors.Wrap(err, "problem creating colour service")
+	}
+	doc, err := s.ImportPalette(ctx, set, origin)
+	if err != nil {
+		return err
+	}
+	log.Info("imported " + set.Name + " as palette " + doc.ID.Hex())
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// readPalette reads a palette file, or standard input for "-", in format or the one its extension implies.
+func readPalette(path, format string) (*export.Set, export.Format, error) {
+	f, err := paletteFormat(path, format)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var r io.Reader = os.Stdin
+	name := "standard input"
+	if path != "-" {
+		name = path
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, "", errors.Wrap(err, "problem opening palette")
+		}
+		defer file.Close()
+		r = file
+	}
+	set, err := export.Read(r, f)
+	if err != nil {
+		return nil, "", errors.Wrap(err, name)
+	}
+	return set, f, nil
+}
+
+func paletteFormat(path, format string) (export.Format, error) {
+	if format != "" {
+		return export.ParseFormat(format)
+	}
+	if path == "-" {
+		return "", errors.New("a format is needed to use standard input or output")
+	}
+	return export.FormatFromPath(path)
+}
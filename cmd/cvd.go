@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/config"
+	"hexbot/internal/palette"
+	"hexbot/internal/service"
+	"hexbot/internal/theme"
+)
+
+// runCVD shows how colours look with each colour vision deficiency. A single colour argument is
+// simulated; several, a stored -palette or a -theme built from a seed are checked for entries that
+// become indistinguishable.
+func runCVD(cfg *config.Config, log *logging.Logger, args []string) error {
+	fs := flag.NewFlagSet("cvd", flag.ExitOnError)
+	defaultThreshold := cfg.CVDThreshold
+	if defaultThreshold <= 0 {
+		defaultThreshold = palette.DefaultCVDThreshold
+	}
+	threshold := fs.Float64("threshold", defaultThreshold, "CIEDE2000 difference below which two colours are flagged")
+	id := fs.String("palette", "", "check the stored palette with this id")
+	var seed colourFlag
+	fs.Var(&seed, "theme", "check the light and dark roles of the theme built from this colour")
+	level := fs.String("level", cfg.ThemeLevel, "WCAG level of the -theme")
+	fs.Parse(args)
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	s := service.NewColourService(log, nil, nil)
+
+	switch {
+	case *id != "":
+		oid, err := primitive.ObjectIDFromHex(*id)
+		if err != nil {
+			return errors.Wrap(err, "invalid -palette")
+		}
+		ctx := context.Background()
+		database, err := newDB(ctx, cfg, log)
+		if err != nil {
+			return errors.Wrap(err, "problem creating database")
+		}
+		defer database.Disconnect(context.Background())
+
+		reports, err := service.NewColourService(log, database, nil).CheckPaletteCVD(ctx, oid, *threshold)
+		if err != nil {
+			return err
+		}
+		return enc.Encode(reports)
+
+	case seed.c != nil:
+		l, err := theme.ParseLevel(*level)
+		if err != nil {
+			return err
+		}
+		t, err := s.Theme(*seed.c, l)
+		if err != nil {
+			return err
+		}
+		return enc.Encode(map[string]interface{}{
+			"light": s.CheckCVD(t.Light.Colours(), *threshold),
+			"dark":  s.CheckCVD(t.Dark.Colours(), *threshold),
+		})
+	}
+
+	if fs.NArg() == 0 {
+		return errors.New("expected colours, -palette or -theme")
+	}
+	colours := make([]colour.Colour, fs.NArg())
+	for i, arg := range fs.Args() {
+		c, err := colour.Parse(arg)
+		if err != nil {
+			return err
+		}
+		colours[i] = c
+	}
+	if len(colours) == 1 {
+		return enc.Encode(s.SimulateCVD(colours[0]))
+	}
+	return enc.Encode(s.CheckCVD(colours, *threshold))
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/config"
+	"hexbot/internal/db"
+	"hexbot/internal/export"
+	"hexbot/internal/service"
+)
+
+// runExport writes a stored palette, or every saved colour matching the query flags, in a palette format.
+func runExport(cfg *config.Config, log *logging.Logger, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	q := addQueryFlags(fs)
+	format := fs.String("format", string(export.JSON), "gpl, ase, paintnet, css, scss, svg or json")
+	id := fs.String("palette", "", "export the stored palette with this id instead of saved colours")
+	out := fs.String("o", "", "file to write, standard output when unset")
+	cvd := fs.Bool("cvd", false, "include a colour vision deficiency report in formats that can carry one")
+	fs.Parse(args)
+
+	f, err := export.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	database, err := newDB(ctx, cfg, log)
+	if err != nil {
+		return errors.Wrap(err, "problem creating database")
+	}
+	defer database.Disconnect(context.Background())
+
+	s, err := newColourService(cfg, log, database, nil)
+	if err != nil {
+		return errors.Wrap(err, "problem creating colour service")
+	}
+	opts := service.ExportOptions{CVD: *cvd, CVDThreshold: cfg.CVDThreshold}
+
+	var set *export.Set
+	if *id != "" {
+		oid, err := primitive.ObjectIDFromHex(*id)
+		if err != nil {
+			return errors.Wrap(err, "invalid -palette")
+		}
+		set, err = s.ExportPalette(ctx, oid, opts)
+	} else {
+		query := q.query()
+		// Every matching colour is exported, so -limit is ignored and pages are as large as allowed.
+		query.Limit = db.MaxLimit
+		set, err = s.ExportColours(ctx, query, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return export.Write(os.Stdout, f, *set)
+	}
+	file, err := os.Create(*out)
+	if err != nil {
+		return errors.Wrap(err, "problem creating output file")
+	}
+	if err := export.Write(file, f, *set); err != nil {
+		file.Close()
+		return err
+	}
+	return errors.Wrap(file.Close(), "problem closing output file")
+}
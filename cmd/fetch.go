@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"strings"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/pkg/errors"
+
+	"hexbot/internal/config"
+	"hexbot/internal/handler"
+	"hexbot/internal/hexbot"
+	"hexbot/internal/render"
+)
+
+// runFetch fetches one batch of colours from Hexbot and saves it, optionally drawing it as a canvas.
+func runFetch(cfg *config.Config, log *logging.Logger, args []string) error {
+	fs := flag.NewFlagSet("fetch", flag.ExitOnError)
+	count := fs.Int("count", 1, "number of colours to fetch, 1 to 1000")
+	width := fs.Int("width", 0, "width of the area to place colours in, requires -height")
+	height := fs.Int("height", 0, "height of the area to place colours in, requires -width")
+	seed := fs.String("seed", "", "comma separated hex colours to draw from, e.g. FF7F50,FFD700")
+	canvas := fs.String("canvas", "", "also draw the batch at its coordinates to this png file, requires -width and -height")
+	fs.Parse(args)
+
+	hc, err := newHexbotClient(cfg, log)
+	if err != nil {
+		return errors.Wrap(err, "problem creating hexbot client")
+	}
+
+	ctx := context.Background()
+	database, err := newDB(ctx, cfg, log)
+	if err != nil {
+		return errors.Wrap(err, "problem creating database")
+	}
+	defer database.Disconnect(context.Background())
+
+	s, err := newColourService(cfg, log, database, hc)
+	if err != nil {
+		return errors.Wrap(err, "problem creating colour service")
+	}
+	h := handler.NewHandle(log, s)
+
+	opts := hexbot.FetchOptions{Count: *count, Width: *width, Height: *height}
+	if *seed != "" {
+		opts.Seed = strings.Split(*seed, ",")
+	}
+	if *canvas != "" && (opts.Width == 0 || opts.Height == 0) {
+		return errors.New("-canvas requires -width and -height")
+	}
+	if err := h.GetHexFromHexbot(ctx, opts); err != nil {
+		return err
+	}
+
+	if *canvas == "" {
+		return nil
+	}
+	img, err := render.Canvas(s.Colours(), opts.Width, opts.Height, 0)
+	if err != nil {
+		return err
+	}
+	return writePNG(*canvas, img)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/config"
+	"hexbot/internal/db"
+)
+
+// rangeFlag parses "min:max" into a db.Range. It stays nil when the flag is not given.
+type rangeFlag struct {
+	r *db.Range
+}
+
+func (f *rangeFlag) String() string {
+	if f.r == nil {
+		return ""
+	}
+	return strconv.FormatFloat(f.r.Min, 'f', -1, 64) + ":" + strconv.FormatFloat(f.r.Max, 'f', -1, 64)
+}
+
+func (f *rangeFlag) Set(v string) error {
+	parts := strings.Split(v, ":")
+	if len(parts) != 2 {
+		return errors.Errorf("range %q must look like min:max", v)
+	}
+	min, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return errors.Errorf("range %q must look like min:max", v)
+	}
+	max, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return errors.Errorf("range %q must look like min:max", v)
+	}
+	f.r = &db.Range{Min: min, Max: max}
+	return nil
+}
+
+// timeFlag parses an RFC 3339 timestamp.
+type timeFlag struct {
+	t time.Time
+}
+
+func (f *timeFlag) String() string {
+	if f.t.IsZero() {
+		return ""
+	}
+	return f.t.Format(time.RFC3339)
+}
+
+func (f *timeFlag) Set(v string) (err error) {
+	f.t, err = time.Parse(time.RFC3339, v)
+	return err
+}
+
+// colourFlag parses any notation understood by colour.Parse. It stays nil when the flag is not given.
+type colourFlag struct {
+	c *colour.Colour
+}
+
+func (f *colourFlag) String() string {
+	if f.c == nil {
+		return ""
+	}
+	return f.c.Hex()
+}
+
+func (f *colourFlag) Set(v string) error {
+	c, err := colour.Parse(v)
+	if err != nil {
+		return err
+	}
+	f.c = &c
+	return nil
+}
+
+// queryFlags are the filters shared by every command that reads saved colours.
+type queryFlags struct {
+	from, to                     timeFlag
+	r, g, b, hue, sat, light     rangeFlag
+	hex                          colourFlag
+	source, name, sortBy, cursor *string
+	asc                          *bool
+	limit                        *int
+}
+
+func addQueryFlags(fs *flag.FlagSet) *queryFlags {
+	f := &queryFlags{}
+	fs.Var(&f.from, "from", "only colours fetched at or after this RFC 3339 time")
+	fs.Var(&f.to, "to", "only colours fetched before this RFC 3339 time")
+	fs.Var(&f.r, "r", "red component range, min:max out of 255")
+	fs.Var(&f.g, "g", "green component range, min:max out of 255")
+	fs.Var(&f.b, "b", "blue component range, min:max out of 255")
+	fs.Var(&f.hue, "hue", "hue range in degrees, min:max; 330:30 wraps through red")
+	fs.Var(&f.sat, "saturation", "saturation range in percent, min:max")
+	fs.Var(&f.light, "lightness", "lightness range in percent, min:max")
+	fs.Var(&f.hex, "hex", "only this exact colour, e.g. #A1B2C3")
+	f.source = fs.String("source", "", "only colours from this source")
+	f.name = fs.String("name", "", "only colours nearest to this colour name, e.g. \"steel blue\"")
+	f.sortBy = fs.String("sort", db.SortFetchedAt, "sort by fetched_at, h, s or l")
+	f.asc = fs.Bool("asc", false, "sort ascending instead of descending")
+	f.limit = fs.Int("limit", db.DefaultLimit, "page size")
+	f.cursor = fs.String("cursor", "", "next_cursor from the previous page")
+	return f
+}
+
+func (f *queryFlags) query() db.ColourQuery {
+	return db.ColourQuery{
+		From:       f.from.t,
+		To:         f.to.t,
+		Colour:     f.hex.c,
+		Source:     *f.source,
+		Name:       *f.name,
+		R:          f.r.r,
+		G:          f.g.r,
+		B:          f.b.r,
+		Hue:        f.hue.r,
+		Saturation: f.sat.r,
+		Lightness:  f.light.r,
+		SortBy:     *f.sortBy,
+		Ascending:  *f.asc,
+		Limit:      *f.limit,
+		Cursor:     *f.cursor,
+	}
+}
+
+// runList prints one page of saved colours as JSON.
+func runList(cfg *config.Config, log *logging.Logger, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	q := addQueryFlags(fs)
+	fs.Parse(args)
+
+	ctx := context.Background()
+	database, err := newDB(ctx, cfg, log)
+	if err != nil {
+		return errors.Wrap(err, "problem creating database")
+	}
+	defer database.Disconnect(context.Background())
+
+	page, err := database.FindColours(ctx, q.query())
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Colours    []db.ColourDocument `json:"colours"`
+		NextCursor string              `json:"next_cursor,omitempty"`
+	}{page.Colours, page.NextCursor})
+}
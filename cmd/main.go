@@ -1,15 +1,152 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"os"
+
 	"github.com/River-Island/product-backbone-v2/logging"
-	"hexbot/internal/handler"
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/config"
+	"hexbot/internal/db"
+	"hexbot/internal/hexbot"
+	"hexbot/internal/names"
+	"hexbot/internal/service"
+)
+
+// version and commit identify the build, set with -ldflags "-X main.version=... -X main.commit=...".
+var (
+	version = "dev"
+	commit  = ""
 )
 
-func main(){
+// commands maps each subcommand to its entry point. Running without a subcommand fetches once.
+var commands = map[string]func(cfg *config.Config, log *logging.Logger, args []string) error{
+	"convert":      runConvert,
+	"cvd":          runCVD,
+	"export":       runExport,
+	"fetch":        runFetch,
+	"import":       runImport,
+	"list":         runList,
+	"palette":      runPalette,
+	"render":       runRender,
+	"serve":        runServe,
+	"theme":        runTheme,
+	"timelapse":    runTimelapse,
+	"serve-hexbot": runServeHexbot,
+}
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		logging.GetLogger("hexbot", logging.INFO).Fatal("problem loading config", err)
+	}
+	log := logging.GetLoggerString("hexbot", cfg.LogLevel)
+
+	name, args := "fetch", os.Args[1:]
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		name, args = args[0], args[1:]
+	}
+
+	run, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", name)
+		os.Exit(2)
+	}
+	if err := run(cfg, log, args); err != nil {
+		log.Fatal("problem running "+name, err)
+	}
+}
+
+// hexbotSource names where colours come from, as recorded against each saved colour.
+func hexbotSource(cfg *config.Config) string {
+	if cfg.HexbotOffline {
+		return "offline"
+	}
+	return "hexbot"
+}
+
+// newDB connects to the configured Mongo database.
+func newDB(ctx context.Context, cfg *config.Config, log *logging.Logger) (*db.DB, error) {
+	return db.NewDB(ctx, log, dbConfig(cfg))
+}
+
+// dbConfig is where cfg says colours are stored.
+func dbConfig(cfg *config.Config) db.Config {
+	return db.Config{
+		URI:               cfg.MongoURI,
+		Database:          cfg.MongoDatabase,
+		Collection:        cfg.MongoCollection,
+		PaletteCollection: cfg.MongoPaletteCollection,
+		RunCollection:     cfg.MongoRunCollection,
+		JobCollection:     cfg.MongoJobCollection,
+		LeaseCollection:   cfg.MongoLeaseCollection,
+		Source:            hexbotSource(cfg),
+		Timeout:           cfg.MongoTimeout,
+	}
+}
+
+// newHexbotClient returns the configured Hexbot source wrapped in retries and a circuit breaker.
+func newHexbotClient(cfg *config.Config, log *logging.Logger) (*hexbot.Resilient, error) {
+	var next hexbot.Fetcher
+	if cfg.HexbotOffline {
+		next = hexbot.NewGenerator(cfg.HexbotSeed)
+	} else {
+		c, err := hexbot.NewClient(hexbot.Config{
+			BaseURL:   cfg.HexbotURL,
+			Timeout:   cfg.HexbotTimeout,
+			UserAgent: cfg.HexbotUserAgent,
+		})
+		if err != nil {
+			return nil, err
+		}
+		next = c
+	}
+
+	breaker := hexbot.NewBreaker(log, hexbot.BreakerConfig{
+		FailureThreshold: cfg.BreakerThreshold,
+		CoolDown:         cfg.BreakerCoolDown,
+	})
+	return hexbot.NewResilient(next, breaker, hexbot.RetryConfig{
+		MaxAttempts: cfg.RetryAttempts,
+		BaseDelay:   cfg.RetryBaseDelay,
+		MaxDelay:    cfg.RetryMaxDelay,
+	}), nil
+}
 
-	service := service.NewService()
-	h := handler.NewColour(logging.NopLogger, service)
-	hexColour := h.GetHexFromHexbot()
-	service.SaveColour(hexColour)
+// newColourService builds the colour service with deduplication configured from cfg.
+func newColourService(cfg *config.Config, log *logging.Logger, database service.Database, hc service.HexbotClient) (*service.ColourService, error) {
+	mode, err := service.ParseDedupMode(cfg.DedupMode)
+	if err != nil {
+		return nil, err
+	}
+	metric, err := colour.MetricByName(cfg.DedupMetric)
+	if err != nil {
+		return nil, err
+	}
+	lookup, err := newNameLookup(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return service.NewColourService(log, database, hc).WithDedup(service.DedupConfig{
+		Mode:      mode,
+		Threshold: cfg.DedupThreshold,
+		Window:    cfg.DedupWindow,
+		Metric:    metric,
+	}).WithNames(lookup), nil
+}
 
-}
\ No newline at end of file
+// newNameLookup searches the built in colour names followed by any configured dictionaries.
+func newNameLookup(cfg *config.Config) (*names.Lookup, error) {
+	lookup := names.NewDefaultLookup()
+	for _, path := range cfg.NameDictionaries {
+		d, err := names.LoadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "problem loading %s", path)
+		}
+		lookup.Add(d)
+	}
+	return lookup, nil
+}
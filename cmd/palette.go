@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/config"
+	"hexbot/internal/db"
+	"hexbot/internal/palette"
+)
+
+// runPalette builds and stores a harmony around a colour, fetched from Hexbot unless -seed is given,
+// or prints a stored palette when -id is given.
+func runPalette(cfg *config.Config, log *logging.Logger, args []string) error {
+	fs := flag.NewFlagSet("palette", flag.ExitOnError)
+	var seed colourFlag
+	fs.Var(&seed, "seed", "colour to build the palette around, fetched from Hexbot when unset")
+	harmony := fs.String("harmony", string(palette.Complementary), "one of complementary, split-complementary, analogous, triadic, tetradic or monochromatic")
+	id := fs.String("id", "", "print the stored palette with this id instead")
+	fs.Parse(args)
+
+	h, err := palette.ParseHarmony(*harmony)
+	if err != nil {
+		return err
+	}
+
+	hc, err := newHexbotClient(cfg, log)
+	if err != nil {
+		return errors.Wrap(err, "problem creating hexbot client")
+	}
+
+	ctx := context.Background()
+	database, err := newDB(ctx, cfg, log)
+	if err != nil {
+		return errors.Wrap(err, "problem creating database")
+	}
+	defer database.Disconnect(context.Background())
+
+	s, err := newColourService(cfg, log, database, hc)
+	if err != nil {
+		return errors.Wrap(err, "problem creating colour service")
+	}
+
+	var doc *db.PaletteDocument
+	switch {
+	case *id != "":
+		oid, err := primitive.ObjectIDFromHex(*id)
+		if err != nil {
+			return errors.Wrap(err, "invalid -id")
+		}
+		doc, err = s.Palette(ctx, oid)
+	case seed.c != nil:
+		doc, err = s.GeneratePalette(ctx, *seed.c, h)
+	default:
+		doc, err = s.PaletteFromHexbot(ctx, h)
+	}
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
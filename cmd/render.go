@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"image"
+	"os"
+	"strings"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/config"
+	"hexbot/internal/hexbot"
+	"hexbot/internal/render"
+	"hexbot/internal/service"
+)
+
+// runRender draws a PNG swatch, strip or grid of the colours given as arguments or of a stored
+// palette, or a canvas of colours fetched from Hexbot at their coordinates.
+func runRender(cfg *config.Config, log *logging.Logger, args []string) error {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	out := fs.String("o", "", "png file to write, standard output when unset")
+	size := fs.Int("size", 0, "swatch side in pixels, or the longest side of a canvas")
+	labels := fs.Bool("labels", false, "write hex codes on swatches large enough to hold them")
+	columns := fs.Int("columns", 0, "swatches per grid row, as square as possible when unset")
+	id := fs.String("palette", "", "draw the stored palette with this id")
+	count := fs.Int("count", 1, "number of colours on a canvas, 1 to 1000")
+	width := fs.Int("width", 0, "width of a canvas")
+	height := fs.Int("height", 0, "height of a canvas")
+	seed := fs.String("seed", "", "comma separated hex colours a canvas draws from")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return errors.New("usage: render [flags] swatch|strip|grid|canvas [colour ...]")
+	}
+	kind := fs.Arg(0)
+
+	var img image.Image
+	var err error
+	if kind == "canvas" {
+		opts := hexbot.FetchOptions{Count: *count, Width: *width, Height: *height}
+		if *seed != "" {
+			opts.Seed = strings.Split(*seed, ",")
+		}
+		if err := opts.Validate(); err != nil {
+			return err
+		}
+		hc, err := newHexbotClient(cfg, log)
+		if err != nil {
+			return errors.Wrap(err, "problem creating hexbot client")
+		}
+		img, err = service.NewColourService(log, nil, hc).Canvas(context.Background(), opts, *size)
+		if err != nil {
+			return err
+		}
+		return writePNG(*out, img)
+	}
+
+	colours, err := renderColours(cfg, log, *id, fs.Args()[1:])
+	if err != nil {
+		return err
+	}
+	opts := render.Options{Size: *size, Labels: *labels, Columns: *columns}
+	switch kind {
+	case "swatch":
+		if len(colours) != 1 {
+			return errors.New("a swatch is one colour")
+		}
+		img, err = render.Swatch(colours[0], opts)
+	case "strip":
+		img, err = render.Strip(colours, opts)
+	case "grid":
+		img, err = render.Grid(colours, opts)
+	default:
+		return errors.Errorf("unknown render %q, expected swatch, strip, grid or canvas", kind)
+	}
+	if err != nil {
+		return err
+	}
+	return writePNG(*out, img)
+}
+
+// renderColours parses args as colours, or loads the palette with id when it is set.
+func renderColours(cfg *config.Config, log *logging.Logger, id string, args []string) ([]colour.Colour, error) {
+	if id == "" {
+		colours := make([]colour.Colour, len(args))
+		for i, a := range args {
+			c, err := colour.Parse(a)
+			if err != nil {
+				return nil, err
+			}
+			colours[i] = c
+		}
+		return colours, nil
+	}
+	if len(args) > 0 {
+		return nil, errors.New("give either colours or -palette, not both")
+	}
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid -palette")
+	}
+	ctx := context.Background()
+	database, err := newDB(ctx, cfg, log)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem creating database")
+	}
+	defer database.Disconnect(context.Background())
+
+	s, err := newColourService(cfg, log, database, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem creating colour service")
+	}
+	doc, err := s.Palette(ctx, oid)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Colours, nil
+}
+
+// writePNG writes img to the file at path, or to standard output when path is empty.
+func writePNG(path string, img image.Image) error {
+	if path == "" {
+		return render.Encode(os.Stdout, img)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "problem creating output file")
+	}
+	if err := render.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return errors.Wrap(file.Close(), "problem closing output file")
+}
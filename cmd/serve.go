@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/pkg/errors"
+
+	"hexbot/internal/config"
+	"hexbot/internal/db"
+	"hexbot/internal/handler"
+	"hexbot/internal/leader"
+	"hexbot/internal/metrics"
+	"hexbot/internal/scheduler"
+	"hexbot/internal/stream"
+)
+
+// runServe serves the colour API, with metrics at /metrics, and runs the jobs in SCHEDULE_FILE, until interrupted. With
+// LEADER_ELECTION only the replica holding the scheduler lease runs them.
+func runServe(cfg *config.Config, log *logging.Logger, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	fs.Parse(args)
+
+	m := metrics.New()
+	hc, err := newHexbotClient(cfg, log)
+	if err != nil {
+		return errors.Wrap(err, "problem creating hexbot client")
+	}
+	hc.WithObserver(m)
+
+	dbCfg := dbConfig(cfg)
+	dbCfg.Monitor = m.CommandMonitor()
+	database, err := db.NewDB(context.Background(), log, dbCfg)
+	if err != nil {
+		return errors.Wrap(err, "problem creating database")
+	}
+	defer database.Disconnect(context.Background())
+
+	s, err := newColourService(cfg, log, database, hc)
+	if err != nil {
+		return errors.Wrap(err, "problem creating colour service")
+	}
+
+	b := stream.NewBroadcaster(cfg.StreamBuffer)
+	s.WithPublisher(b).WithObserver(m)
+	h := handler.NewHandle(log, s).
+		WithStream(b, cfg.StreamOrigins...).
+		WithHealth(database, hc).
+		WithStatus(handler.BuildInfo{Version: version, Commit: commit}, *cfg).
+		WithMetrics(m)
+
+	var sched *scheduler.Scheduler
+	if cfg.ScheduleFile != "" {
+		if sched, err = newScheduler(cfg, log, s, database); err != nil {
+			return err
+		}
+		h.WithScheduler(sched.WithObserver(m).WithJobStore(database, scheduler.DefaultJobPoll))
+	}
+
+	run := func(ctx context.Context) { sched.Run(ctx) }
+	if sched != nil && cfg.LeaderElection {
+		elector := leader.New(log, database, leader.Config{Name: cfg.LeaseName, TTL: cfg.LeaseTTL})
+		h.WithElector(elector)
+		run = func(ctx context.Context) { elector.Run(ctx, sched.Run) }
+		log.Info(fmt.Sprintf("campaigning for the %s lease as %s", cfg.LeaseName, elector.Status().Holder))
+	}
+
+	srv := &http.Server{
+		Addr:    *addr,
+		Handler: h.Routes(),
+	}
+	// Streams never finish by themselves, so end them when shutting down rather than waiting them out.
+	srv.RegisterOnShutdown(b.Close)
+	if sched == nil {
+		return listenAndServe(log, srv)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		run(ctx)
+		close(done)
+	}()
+	err = listenAndServe(log, srv)
+	cancel()
+	<-done
+	return err
+}
+
+// newScheduler loads the jobs in cfg.ScheduleFile.
+func newScheduler(cfg *config.Config, log *logging.Logger, f scheduler.Fetcher, runs scheduler.RunStore) (*scheduler.Scheduler, error) {
+	loc, err := time.LoadLocation(cfg.ScheduleTimezone)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem loading SCHEDULE_TIMEZONE")
+	}
+	jobs, err := scheduler.LoadFile(cfg.ScheduleFile)
+	if err != nil {
+		return nil, err
+	}
+	s, err := scheduler.New(log, f, runs, loc, jobs)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem creating scheduler")
+	}
+	log.Info(fmt.Sprintf("scheduling %d jobs from %s", len(jobs), cfg.ScheduleFile))
+	return s, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/pkg/errors"
+
+	"hexbot/internal/config"
+	"hexbot/internal/hexbot"
+)
+
+// runServeHexbot serves a local stand-in for the Hexbot API until interrupted.
+func runServeHexbot(cfg *config.Config, log *logging.Logger, args []string) error {
+	fs := flag.NewFlagSet("serve-hexbot", flag.ExitOnError)
+	addr := fs.String("addr", ":8081", "address to listen on")
+	latency := fs.Duration("latency", 0, "delay added before every response")
+	errorRate := fs.Float64("error-rate", 0, "fraction of requests, 0 to 1, answered with a 500")
+	malformedRate := fs.Float64("malformed-rate", 0, "fraction of requests, 0 to 1, answered with a truncated body")
+	seed := fs.Int64("seed", cfg.HexbotSeed, "seed for the generated colours and injected faults")
+	fs.Parse(args)
+
+	srv := &http.Server{
+		Addr: *addr,
+		Handler: hexbot.NewServer(log, hexbot.ServerConfig{
+			Latency:       *latency,
+			ErrorRate:     *errorRate,
+			MalformedRate: *malformedRate,
+			Seed:          *seed,
+		}),
+	}
+
+	return listenAndServe(log, srv)
+}
+
+// listenAndServe runs srv until SIGINT or SIGTERM, then shuts it down gracefully.
+func listenAndServe(log *logging.Logger, srv *http.Server) error {
+	errs := make(chan error, 1)
+	go func() {
+		log.Info("listening on " + srv.Addr)
+		errs <- srv.ListenAndServe()
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	select {
+	case err := <-errs:
+		return errors.Wrap(err, "problem serving http")
+	case <-stop:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return errors.Wrap(srv.Shutdown(ctx), "problem shutting down http server")
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/pkg/errors"
+
+	"hexbot/internal/config"
+	"hexbot/internal/service"
+	"hexbot/internal/theme"
+)
+
+// runTheme prints light and dark UI themes built from a colour, fetched from Hexbot unless -seed is given.
+// It doesn't touch the database.
+func runTheme(cfg *config.Config, log *logging.Logger, args []string) error {
+	fs := flag.NewFlagSet("theme", flag.ExitOnError)
+	var seed colourFlag
+	fs.Var(&seed, "seed", "colour to build the theme from, fetched from Hexbot when unset")
+	level := fs.String("level", cfg.ThemeLevel, "WCAG level every text colour meets: AA, AA-large, AAA or AAA-large")
+	format := fs.String("format", string(theme.FormatJSON), "json design tokens, css custom properties or a tailwind config fragment")
+	fs.Parse(args)
+
+	l, err := theme.ParseLevel(*level)
+	if err != nil {
+		return err
+	}
+	f, err := theme.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	hc, err := newHexbotClient(cfg, log)
+	if err != nil {
+		return errors.Wrap(err, "problem creating hexbot client")
+	}
+	s := service.NewColourService(log, nil, hc)
+
+	var t *theme.Theme
+	if seed.c != nil {
+		t, err = s.Theme(*seed.c, l)
+	} else {
+		t, err = s.ThemeFromHexbot(context.Background(), l)
+	}
+	if err != nil {
+		return err
+	}
+	return t.Write(os.Stdout, f)
+}
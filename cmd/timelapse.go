@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/pkg/errors"
+
+	"hexbot/internal/config"
+	"hexbot/internal/render"
+	"hexbot/internal/service"
+)
+
+// runTimelapse animates the colours saved in a time window as a GIF, one frame per fetch, hour or day.
+func runTimelapse(cfg *config.Config, log *logging.Logger, args []string) error {
+	fs := flag.NewFlagSet("timelapse", flag.ExitOnError)
+	var from, to timeFlag
+	fs.Var(&from, "from", "RFC 3339 start of the window, a day before -to when unset")
+	fs.Var(&to, "to", "RFC 3339 end of the window, now when unset")
+	group := fs.String("group", string(service.GroupFetch), "one frame per fetch, hour or day")
+	delay := fs.Duration("delay", render.DefaultFrameDelay, "how long each frame shows")
+	source := fs.String("source", "", "only animate colours from this source")
+	size := fs.Int("size", 0, "swatch side in pixels")
+	labels := fs.Bool("labels", false, "write hex codes on swatches large enough to hold them")
+	columns := fs.Int("columns", 0, "swatches per row, as square as possible when unset")
+	out := fs.String("o", "", "gif file to write, standard output when unset")
+	fs.Parse(args)
+
+	g, err := service.ParseGrouping(*group)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	database, err := newDB(ctx, cfg, log)
+	if err != nil {
+		return errors.Wrap(err, "problem creating database")
+	}
+	defer database.Disconnect(context.Background())
+
+	s, err := newColourService(cfg, log, database, nil)
+	if err != nil {
+		return errors.Wrap(err, "problem creating colour service")
+	}
+	anim, err := s.Timelapse(ctx, service.TimelapseOptions{
+		From:    from.t,
+		To:      to.t,
+		Source:  *source,
+		Group:   g,
+		Delay:   *delay,
+		Options: render.Options{Size: *size, Labels: *labels, Columns: *columns},
+	})
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return render.EncodeGIF(os.Stdout, anim)
+	}
+	file, err := os.Create(*out)
+	if err != nil {
+		return errors.Wrap(err, "problem creating output file")
+	}
+	if err := render.EncodeGIF(file, anim); err != nil {
+		file.Close()
+		return err
+	}
+	return errors.Wrap(file.Close(), "problem closing output file")
+}
@@ -0,0 +1,76 @@
+// Package colour provides an immutable sRGB colour value with parsing, validation and
+// conversions to other colour spaces.
+package colour
+
+import (
+	"fmt"
+	"math"
+)
+
+// Colour is an 8 bit per channel sRGB colour with alpha. The zero value is transparent black.
+// Colours are compared with ==.
+type Colour struct {
+	r, g, b, a uint8
+}
+
+// RGB returns an opaque colour.
+func RGB(r, g, b uint8) Colour {
+	return Colour{r: r, g: g, b: b, a: 0xFF}
+}
+
+// RGBA returns a colour with the given alpha, 0 being fully transparent.
+func RGBA(r, g, b, a uint8) Colour {
+	return Colour{r: r, g: g, b: b, a: a}
+}
+
+// R returns the red channel.
+func (c Colour) R() uint8 { return c.r }
+
+// G returns the green channel.
+func (c Colour) G() uint8 { return c.g }
+
+// B returns the blue channel.
+func (c Colour) B() uint8 { return c.b }
+
+// A returns the alpha channel.
+func (c Colour) A() uint8 { return c.a }
+
+// Opaque returns c with its alpha set to fully opaque.
+func (c Colour) Opaque() Colour {
+	c.a = 0xFF
+	return c
+}
+
+// IsZero reports whether c is the zero value.
+func (c Colour) IsZero() bool {
+	return c == Colour{}
+}
+
+// Hex returns c as "#RRGGBB", or "#RRGGBBAA" when it is not fully opaque.
+func (c Colour) Hex() string {
+	if c.a == 0xFF {
+		return fmt.Sprintf("#%02X%02X%02X", c.r, c.g, c.b)
+	}
+	return fmt.Sprintf("#%02X%02X%02X%02X", c.r, c.g, c.b, c.a)
+}
+
+func (c Colour) String() string {
+	return c.Hex()
+}
+
+// RGBA implements image/color.Color.
+func (c Colour) RGBA() (r, g, b, a uint32) {
+	r, g, b, a = uint32(c.r), uint32(c.g), uint32(c.b), uint32(c.a)
+	r, g, b, a = r|r<<8, g|g<<8, b|b<<8, a|a<<8
+	// image/color expects alpha premultiplied values.
+	return r * a / 0xFFFF, g * a / 0xFFFF, b * a / 0xFFFF, a
+}
+
+// channel converts a value in [0, 1] to the nearest 8 bit channel, clamping out of gamut values.
+func channel(v float64) uint8 {
+	return uint8(math.Round(clamp(v, 0, 1) * 255))
+}
+
+func clamp(v, min, max float64) float64 {
+	return math.Max(min, math.Min(max, v))
+}
@@ -0,0 +1,187 @@
+package colour_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"hexbot/internal/colour"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		Desc    string
+		In      string
+		Want    colour.Colour
+		WantErr error
+	}{
+		{Desc: "short hex", In: "#f0a", Want: colour.RGB(0xFF, 0x00, 0xAA)},
+		{Desc: "short hex with alpha", In: "#f0a8", Want: colour.RGBA(0xFF, 0x00, 0xAA, 0x88)},
+		{Desc: "long hex", In: "#A1B2C3", Want: colour.RGB(0xA1, 0xB2, 0xC3)},
+		{Desc: "long hex with alpha", In: "#a1b2c380", Want: colour.RGBA(0xA1, 0xB2, 0xC3, 0x80)},
+		{Desc: "rgb commas", In: "rgb(255, 128, 0)", Want: colour.RGB(255, 128, 0)},
+		{Desc: "rgba commas", In: "rgba(255, 128, 0, 0.5)", Want: colour.RGBA(255, 128, 0, 128)},
+		{Desc: "rgb spaces and percentages", In: "rgb(100% 0% 50% / 25%)", Want: colour.RGBA(255, 0, 128, 64)},
+		{Desc: "hsl", In: "hsl(120, 100%, 50%)", Want: colour.RGB(0, 255, 0)},
+		{Desc: "hsla with deg", In: "hsla(240deg, 100%, 25%, 1)", Want: colour.RGB(0, 0, 128)},
+		{Desc: "hsl spaces", In: "hsl(0 0% 100%)", Want: colour.RGB(255, 255, 255)},
+		{Desc: "empty", In: "  ", WantErr: colour.ErrEmpty},
+		{Desc: "named colours are not supported", In: "red", WantErr: colour.ErrNotation},
+		{Desc: "bad hex length", In: "#12345", WantErr: colour.ErrHexLength},
+		{Desc: "bad hex digit", In: "#12345g", WantErr: colour.ErrHexDigit},
+		{Desc: "rgb out of range", In: "rgb(256, 0, 0)", WantErr: colour.ErrArgumentRange},
+		{Desc: "rgb missing argument", In: "rgb(1, 2)", WantErr: colour.ErrArgumentCount},
+		{Desc: "rgb not a number", In: "rgb(a, b, c)", WantErr: colour.ErrArgument},
+		{Desc: "rgb nan", In: "rgb(nan, 0, 0)", WantErr: colour.ErrArgument},
+		{Desc: "rgb infinite percentage", In: "rgb(inf%, 0%, 0%)", WantErr: colour.ErrArgument},
+		{Desc: "rgba nan alpha", In: "rgba(0, 0, 0, NaN)", WantErr: colour.ErrArgument},
+		{Desc: "hsl infinite hue", In: "hsl(inf, 50%, 50%)", WantErr: colour.ErrArgument},
+		{Desc: "hsl nan hue", In: "hsl(nan, 50%, 50%)", WantErr: colour.ErrArgument},
+		{Desc: "hsl nan saturation", In: "hsl(0, nan%, 50%)", WantErr: colour.ErrArgument},
+		{Desc: "hsl without percentages", In: "hsl(120, 100, 50)", WantErr: colour.ErrArgument},
+		{Desc: "mixed separators", In: "rgb(1, 2, 3 / 50%)", WantErr: colour.ErrMixedSeparator},
+		{Desc: "unclosed", In: "rgb(1, 2, 3", WantErr: colour.ErrNotation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			got, err := colour.Parse(tt.In)
+			if tt.WantErr != nil {
+				if _, ok := err.(*colour.ParseError); !ok {
+					t.Fatalf("expected a *ParseError, got %v", err)
+				}
+				if errors.Cause(err) != tt.WantErr {
+					t.Fatalf("expected %v, got %v", tt.WantErr, errors.Cause(err))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.Want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.In, got, tt.Want)
+			}
+		})
+	}
+}
+
+func TestColour_Hex(t *testing.T) {
+	if got := colour.RGB(1, 2, 255).Hex(); got != "#0102FF" {
+		t.Errorf("got %s", got)
+	}
+	if got := colour.RGBA(1, 2, 255, 0x80).Hex(); got != "#0102FF80" {
+		t.Errorf("got %s", got)
+	}
+}
+
+func near(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestColour_Conversions(t *testing.T) {
+	orange := colour.MustParse("#FF8000")
+
+	if hsl := orange.HSL(); !near(hsl.H, 30.1, 0.1) || !near(hsl.S, 1, 1e-9) || !near(hsl.L, 0.5, 1e-9) {
+		t.Errorf("HSL() = %+v", hsl)
+	}
+	if hsv := orange.HSV(); !near(hsv.H, 30.1, 0.1) || !near(hsv.S, 1, 1e-9) || !near(hsv.V, 1, 1e-9) {
+		t.Errorf("HSV() = %+v", hsv)
+	}
+	if cmyk := orange.CMYK(); !near(cmyk.C, 0, 1e-9) || !near(cmyk.M, 0.498, 1e-3) || !near(cmyk.Y, 1, 1e-9) || !near(cmyk.K, 0, 1e-9) {
+		t.Errorf("CMYK() = %+v", cmyk)
+	}
+
+	white := colour.RGB(255, 255, 255)
+	if xyz := white.XYZ(); !near(xyz.X, 0.95047, 1e-4) || !near(xyz.Y, 1, 1e-4) || !near(xyz.Z, 1.08883, 1e-3) {
+		t.Errorf("XYZ() = %+v", xyz)
+	}
+	if lab := white.Lab(); !near(lab.L, 100, 1e-2) || !near(lab.A, 0, 1e-2) || !near(lab.B, 0, 1e-2) {
+		t.Errorf("Lab() = %+v", lab)
+	}
+	if ok := white.OKLab(); !near(ok.L, 1, 1e-3) || !near(ok.A, 0, 1e-3) || !near(ok.B, 0, 1e-3) {
+		t.Errorf("OKLab() = %+v", ok)
+	}
+
+	// Reference values from https://bottosson.github.io/posts/oklab/ and CSS Color 4.
+	red := colour.RGB(255, 0, 0)
+	if lab := red.Lab(); !near(lab.L, 53.24, 0.05) || !near(lab.A, 80.09, 0.05) || !near(lab.B, 67.20, 0.05) {
+		t.Errorf("Lab() = %+v", lab)
+	}
+	if lch := red.OKLCH(); !near(lch.L, 0.628, 1e-3) || !near(lch.C, 0.2577, 1e-3) || !near(lch.H, 29.23, 0.05) {
+		t.Errorf("OKLCH() = %+v", lch)
+	}
+}
+
+func TestColour_HSLRoundTrip(t *testing.T) {
+	for _, h := range []string{"#000000", "#FFFFFF", "#A1B2C3", "#FF8000", "#123456", "#7F7F7F"} {
+		c := colour.MustParse(h)
+		if got := c.HSL().Colour(); got != c {
+			t.Errorf("%s round tripped through HSL to %s", c, got)
+		}
+	}
+}
+
+func TestColour_LabAndCMYKRoundTrip(t *testing.T) {
+	for _, h := range []string{"#000000", "#FFFFFF", "#A1B2C3", "#FF8000", "#123456"} {
+		c := colour.MustParse(h)
+		if got := c.Lab().Colour(); got != c {
+			t.Errorf("%s round tripped through Lab to %s", c, got)
+		}
+		if got := c.CMYK().Colour(); got != c {
+			t.Errorf("%s round tripped through CMYK to %s", c, got)
+		}
+	}
+}
+
+func TestColour_OKLCHRoundTrip(t *testing.T) {
+	for _, h := range []string{"#000000", "#FFFFFF", "#A1B2C3", "#FF8000", "#123456", "#0000FF"} {
+		c := colour.MustParse(h)
+		if got := c.OKLCH().Colour(); got != c {
+			t.Errorf("%s round tripped through OKLCH to %s", c, got)
+		}
+	}
+
+	// A chroma no sRGB colour reaches is reduced until it fits, keeping lightness and hue.
+	want := colour.OKLCH{L: 0.7, C: 0.4, H: 150}
+	got := want.Colour().OKLCH()
+	if !near(got.L, want.L, 0.01) || !near(got.H, want.H, 1) || got.C >= want.C {
+		t.Errorf("gamut mapped %+v to %+v", want, got)
+	}
+}
+
+func TestColour_Marshalling(t *testing.T) {
+	type doc struct {
+		C colour.Colour `json:"c" bson:"c"`
+	}
+	in := doc{C: colour.RGB(0xA1, 0xB2, 0xC3)}
+
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"c":"#A1B2C3"}` {
+		t.Errorf("unexpected JSON %s", b)
+	}
+	var out doc
+	if err := json.Unmarshal(b, &out); err != nil || out != in {
+		t.Errorf("JSON round trip gave %+v, %v", out, err)
+	}
+	if err := json.Unmarshal([]byte(`{"c":"#GG0000"}`), &out); err == nil {
+		t.Error("expected an error decoding a malformed colour")
+	}
+
+	raw, err := bson.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := bson.Raw(raw).Lookup("c").StringValueOK(); !ok || s != "#A1B2C3" {
+		t.Errorf("expected the colour to be stored as a hex string, got %v", bson.Raw(raw).Lookup("c"))
+	}
+	out = doc{}
+	if err := bson.Unmarshal(raw, &out); err != nil || out != in {
+		t.Errorf("BSON round trip gave %+v, %v", out, err)
+	}
+}
@@ -0,0 +1,105 @@
+package colour
+
+import "math"
+
+// WCAG 2.x minimum contrast ratios. Large text is at least 18pt, or 14pt bold.
+const (
+	ContrastAA       = 4.5
+	ContrastAALarge  = 3
+	ContrastAAA      = 7
+	ContrastAAALarge = 4.5
+)
+
+// WCAG reports which WCAG 2.x success criteria a contrast ratio passes.
+type WCAG struct {
+	AA       bool `json:"aa"`
+	AALarge  bool `json:"aa_large"`
+	AAA      bool `json:"aaa"`
+	AAALarge bool `json:"aaa_large"`
+}
+
+// WCAGLevels grades a contrast ratio against the AA and AAA thresholds.
+func WCAGLevels(ratio float64) WCAG {
+	return WCAG{
+		AA:       ratio >= ContrastAA,
+		AALarge:  ratio >= ContrastAALarge,
+		AAA:      ratio >= ContrastAAA,
+		AAALarge: ratio >= ContrastAAALarge,
+	}
+}
+
+// Luminance is the WCAG 2.x relative luminance of c, from 0 for black to 1 for white.
+// Alpha is ignored.
+func (c Colour) Luminance() float64 {
+	return 0.2126*wcagLinear(c.r) + 0.7152*wcagLinear(c.g) + 0.0722*wcagLinear(c.b)
+}
+
+// wcagLinear uses the 0.03928 threshold from the WCAG definition rather than sRGB's 0.04045.
+// The two only differ for channel values that no 8 bit colour can take.
+func wcagLinear(v uint8) float64 {
+	f := float64(v) / 255
+	if f <= 0.03928 {
+		return f / 12.92
+	}
+	return math.Pow((f+0.055)/1.055, 2.4)
+}
+
+// ContrastRatio is the WCAG 2.x contrast ratio between a and b, from 1 to 21.
+// It is symmetric.
+func ContrastRatio(a, b Colour) float64 {
+	la, lb := a.Luminance(), b.Luminance()
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// APCA constants from the APCA-W3 0.0.98G-4g reference implementation.
+const (
+	apcaExponent  = 2.4
+	apcaNormBG    = 0.56
+	apcaNormText  = 0.57
+	apcaRevText   = 0.62
+	apcaRevBG     = 0.65
+	apcaBlackClip = 0.022
+	apcaBlackExp  = 1.414
+	apcaScale     = 1.14
+	apcaOffset    = 0.027
+	apcaDeltaYMin = 0.0005
+	apcaLowClip   = 0.1
+)
+
+// APCA is the APCA lightness contrast Lc of text drawn on background, roughly -108 to 106.
+// It is positive for dark text on a light background and negative the other way round.
+// Lc 75 is the usual minimum for body text, 60 for larger text and 45 for headlines.
+func APCA(text, background Colour) float64 {
+	yt, yb := apcaY(text), apcaY(background)
+	if math.Abs(yb-yt) < apcaDeltaYMin {
+		return 0
+	}
+
+	var lc float64
+	if yb > yt {
+		sapc := (math.Pow(yb, apcaNormBG) - math.Pow(yt, apcaNormText)) * apcaScale
+		if sapc >= apcaLowClip {
+			lc = sapc - apcaOffset
+		}
+	} else {
+		sapc := (math.Pow(yb, apcaRevBG) - math.Pow(yt, apcaRevText)) * apcaScale
+		if sapc <= -apcaLowClip {
+			lc = sapc + apcaOffset
+		}
+	}
+	return lc * 100
+}
+
+// apcaY is APCA's estimate of screen luminance, with a soft clamp near black.
+func apcaY(c Colour) float64 {
+	y := 0.2126729*math.Pow(float64(c.r)/255, apcaExponent) +
+		0.7151522*math.Pow(float64(c.g)/255, apcaExponent) +
+		0.0721750*math.Pow(float64(c.b)/255, apcaExponent)
+	if y < apcaBlackClip {
+		y += math.Pow(apcaBlackClip-y, apcaBlackExp)
+	}
+	return y
+}
@@ -0,0 +1,56 @@
+package colour_test
+
+import (
+	"testing"
+
+	"hexbot/internal/colour"
+)
+
+func TestContrastRatio(t *testing.T) {
+	tests := []struct {
+		A, B string
+		Want float64
+		WCAG colour.WCAG
+	}{
+		{"#000000", "#FFFFFF", 21, colour.WCAG{AA: true, AALarge: true, AAA: true, AAALarge: true}},
+		{"#FFFFFF", "#FFFFFF", 1, colour.WCAG{}},
+		{"#777777", "#FFFFFF", 4.48, colour.WCAG{AALarge: true}},
+		{"#767676", "#FFFFFF", 4.54, colour.WCAG{AA: true, AALarge: true, AAALarge: true}},
+		{"#595959", "#FFFFFF", 7.00, colour.WCAG{AA: true, AALarge: true, AAA: true, AAALarge: true}},
+	}
+
+	for _, tt := range tests {
+		a, b := colour.MustParse(tt.A), colour.MustParse(tt.B)
+		got := colour.ContrastRatio(a, b)
+		if !near(got, tt.Want, 0.01) {
+			t.Errorf("ContrastRatio(%s, %s) = %.3f, want %.2f", tt.A, tt.B, got, tt.Want)
+		}
+		if got != colour.ContrastRatio(b, a) {
+			t.Errorf("ContrastRatio is not symmetric for %s, %s", tt.A, tt.B)
+		}
+		if levels := colour.WCAGLevels(got); levels != tt.WCAG {
+			t.Errorf("WCAGLevels(%.3f) = %+v, want %+v", got, levels, tt.WCAG)
+		}
+	}
+}
+
+func TestAPCA(t *testing.T) {
+	// Values from the APCA-W3 reference implementation.
+	tests := []struct {
+		Text, Background string
+		Want             float64
+	}{
+		{"#000000", "#FFFFFF", 106.04},
+		{"#FFFFFF", "#000000", -107.88},
+		{"#888888", "#FFFFFF", 63.06},
+		{"#FFFFFF", "#888888", -68.54},
+		{"#123456", "#123456", 0},
+	}
+
+	for _, tt := range tests {
+		got := colour.APCA(colour.MustParse(tt.Text), colour.MustParse(tt.Background))
+		if !near(got, tt.Want, 0.01) {
+			t.Errorf("APCA(%s, %s) = %.3f, want %.2f", tt.Text, tt.Background, got, tt.Want)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package colour
+
+import (
+	"math"
+)
+
+// HSL is hue in degrees, [0, 360), with saturation and lightness in [0, 1].
+type HSL struct {
+	H float64 `json:"h"`
+	S float64 `json:"s"`
+	L float64 `json:"l"`
+}
+
+// HSV is hue in degrees, [0, 360), with saturation and value in [0, 1].
+type HSV struct {
+	H float64 `json:"h"`
+	S float64 `json:"s"`
+	V float64 `json:"v"`
+}
+
+// CMYK holds naive, profile free, cyan, magenta, yellow and key values in [0, 1].
+type CMYK struct {
+	C float64 `json:"c"`
+	M float64 `json:"m"`
+	Y float64 `json:"y"`
+	K float64 `json:"k"`
+}
+
+// XYZ is CIE 1931 XYZ relative to the D65 white point, scaled so white has Y = 1.
+type XYZ struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+// Lab is CIELAB relative to the D65 white point. L is in [0, 100].
+type Lab struct {
+	L float64 `json:"l"`
+	A float64 `json:"a"`
+	B float64 `json:"b"`
+}
+
+// OKLab is Björn Ottosson's perceptual colour space. L is in [0, 1].
+type OKLab struct {
+	L float64 `json:"l"`
+	A float64 `json:"a"`
+	B float64 `json:"b"`
+}
+
+// OKLCH is the polar form of OKLab. H is in degrees, [0, 360).
+type OKLCH struct {
+	L float64 `json:"l"`
+	C float64 `json:"c"`
+	H float64 `json:"h"`
+}
+
+// D65 reference white in XYZ.
+var d65 = XYZ{X: 0.95047, Y: 1, Z: 1.08883}
+
+// HSL converts c to HSL, ignoring alpha.
+func (c Colour) HSL() HSL {
+	r, g, b := c.unit()
+	max, min := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	l := (max + min) / 2
+	d := max - min
+	if d == 0 {
+		return HSL{L: l}
+	}
+	return HSL{H: hue(r, g, b, max, d), S: d / (1 - math.Abs(2*l-1)), L: l}
+}
+
+// HSV converts c to HSV, ignoring alpha.
+func (c Colour) HSV() HSV {
+	r, g, b := c.unit()
+	max, min := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	d := max - min
+	if max == 0 {
+		return HSV{}
+	}
+	if d == 0 {
+		return HSV{V: max}
+	}
+	return HSV{H: hue(r, g, b, max, d), S: d / max, V: max}
+}
+
+// CMYK converts c to CMYK, ignoring alpha.
+func (c Colour) CMYK() CMYK {
+	r, g, b := c.unit()
+	k := 1 - math.Max(r, math.Max(g, b))
+	if k == 1 {
+		return CMYK{K: 1}
+	}
+	return CMYK{C: (1 - r - k) / (1 - k), M: (1 - g - k) / (1 - k), Y: (1 - b - k) / (1 - k), K: k}
+}
+
+// XYZ converts c to CIE XYZ, ignoring alpha.
+func (c Colour) XYZ() XYZ {
+	r, g, b := c.linear()
+	return XYZ{
+		X: 0.4124564*r + 0.3575761*g + 0.1804375*b,
+		Y: 0.2126729*r + 0.7151522*g + 0.0721750*b,
+		Z: 0.0193339*r + 0.1191920*g + 0.9503041*b,
+	}
+}
+
+// Lab converts c to CIELAB, ignoring alpha.
+func (c Colour) Lab() Lab {
+	xyz := c.XYZ()
+	fx, fy, fz := labF(xyz.X/d65.X), labF(xyz.Y/d65.Y), labF(xyz.Z/d65.Z)
+	return Lab{L: 116*fy - 16, A: 500 * (fx - fy), B: 200 * (fy - fz)}
+}
+
+// OKLab converts c to OKLab, ignoring alpha.
+func (c Colour) OKLab() OKLab {
+	r, g, b := c.linear()
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+	return OKLab{
+		L: 0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		A: 1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		B: 0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+	}
+}
+
+// OKLCH converts c to OKLCH, ignoring alpha.
+func (c Colour) OKLCH() OKLCH {
+	return c.OKLab().OKLCH()
+}
+
+// OKLCH converts to polar form.
+func (o OKLab) OKLCH() OKLCH {
+	h := math.Atan2(o.B, o.A) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+	return OKLCH{L: o.L, C: math.Hypot(o.A, o.B), H: h}
+}
+
+// Colour converts XYZ back to an opaque sRGB colour, clipping each channel to the sRGB gamut.
+func (x XYZ) Colour() Colour {
+	r := 3.2404542*x.X - 1.5371385*x.Y - 0.4985314*x.Z
+	g := -0.9692660*x.X + 1.8760108*x.Y + 0.0415560*x.Z
+	b := 0.0556434*x.X - 0.2040259*x.Y + 1.0572252*x.Z
+	return RGB(channel(fromLinear(clamp(r, 0, 1))), channel(fromLinear(clamp(g, 0, 1))), channel(fromLinear(clamp(b, 0, 1))))
+}
+
+// Colour converts CIELAB back to an opaque sRGB colour, clipping each channel to the sRGB gamut.
+func (l Lab) Colour() Colour {
+	fy := (l.L + 16) / 116
+	fx, fz := fy+l.A/500, fy-l.B/200
+	return XYZ{X: d65.X * labFInv(fx), Y: d65.Y * labFInv(fy), Z: d65.Z * labFInv(fz)}.Colour()
+}
+
+// Colour converts CMYK back to an opaque sRGB colour with the naive formula, without an ICC profile.
+func (c CMYK) Colour() Colour {
+	k := 1 - clamp(c.K, 0, 1)
+	return RGB(channel((1-clamp(c.C, 0, 1))*k), channel((1-clamp(c.M, 0, 1))*k), channel((1-clamp(c.Y, 0, 1))*k))
+}
+
+// OKLab converts back to Cartesian form.
+func (o OKLCH) OKLab() OKLab {
+	h := o.H * math.Pi / 180
+	return OKLab{L: o.L, A: o.C * math.Cos(h), B: o.C * math.Sin(h)}
+}
+
+// Colour converts OKLab back to an opaque sRGB colour, clipping each channel to the sRGB gamut.
+func (o OKLab) Colour() Colour {
+	r, g, b := o.linear()
+	return RGB(channel(fromLinear(r)), channel(fromLinear(g)), channel(fromLinear(b)))
+}
+
+// Colour converts OKLCH back to an opaque sRGB colour. Colours outside the sRGB gamut lose chroma,
+// keeping their lightness and hue, until they fit.
+func (o OKLCH) Colour() Colour {
+	o.L = clamp(o.L, 0, 1)
+	o.C = math.Max(o.C, 0)
+	if inGamut(o.OKLab()) {
+		return o.OKLab().Colour()
+	}
+
+	lo, hi := 0.0, o.C
+	for hi-lo > 1e-4 {
+		o.C = (lo + hi) / 2
+		if inGamut(o.OKLab()) {
+			lo = o.C
+		} else {
+			hi = o.C
+		}
+	}
+	o.C = lo
+	return o.OKLab().Colour()
+}
+
+// linear converts to linear sRGB, which may be outside [0, 1] for colours sRGB can't show.
+func (o OKLab) linear() (r, g, b float64) {
+	l := o.L + 0.3963377774*o.A + 0.2158037573*o.B
+	m := o.L - 0.1055613458*o.A - 0.0638541728*o.B
+	s := o.L - 0.0894841775*o.A - 1.2914855480*o.B
+	l, m, s = l*l*l, m*m*m, s*s*s
+	return 4.0767416621*l - 3.3077115913*m + 0.2309699292*s,
+		-1.2684380046*l + 2.6097574011*m - 0.3413193965*s,
+		-0.0041960863*l - 0.7034186147*m + 1.7076147010*s
+}
+
+func inGamut(o OKLab) bool {
+	const eps = 1e-6
+	r, g, b := o.linear()
+	return r >= -eps && r <= 1+eps && g >= -eps && g <= 1+eps && b >= -eps && b <= 1+eps
+}
+
+// Colour converts HSL back to an opaque sRGB colour.
+func (h HSL) Colour() Colour {
+	s, l := clamp(h.S, 0, 1), clamp(h.L, 0, 1)
+	hh := math.Mod(h.H, 360)
+	if hh < 0 {
+		hh += 360
+	}
+
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(hh/60, 2)-1))
+	m := l - c/2
+
+	var r, g, b float64
+	switch {
+	case hh < 60:
+		r, g, b = c, x, 0
+	case hh < 120:
+		r, g, b = x, c, 0
+	case hh < 180:
+		r, g, b = 0, c, x
+	case hh < 240:
+		r, g, b = 0, x, c
+	case hh < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	return RGB(channel(r+m), channel(g+m), channel(b+m))
+}
+
+// unit returns the channels scaled to [0, 1].
+func (c Colour) unit() (r, g, b float64) {
+	return float64(c.r) / 255, float64(c.g) / 255, float64(c.b) / 255
+}
+
+// linear returns the channels with the sRGB transfer function removed.
+func (c Colour) linear() (r, g, b float64) {
+	r, g, b = c.unit()
+	return toLinear(r), toLinear(g), toLinear(b)
+}
+
+func toLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func fromLinear(v float64) float64 {
+	if v <= 0.0031308 {
+		return 12.92 * v
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+func hue(r, g, b, max, d float64) float64 {
+	var h float64
+	switch max {
+	case r:
+		h = math.Mod((g-b)/d, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h
+}
+
+func labF(t float64) float64 {
+	const epsilon, kappa = 216.0 / 24389, 24389.0 / 27
+	if t > epsilon {
+		return math.Cbrt(t)
+	}
+	return (kappa*t + 16) / 116
+}
+
+func labFInv(t float64) float64 {
+	const epsilon, kappa = 216.0 / 24389, 24389.0 / 27
+	if t3 := t * t * t; t3 > epsilon {
+		return t3
+	}
+	return (116*t - 16) / kappa
+}
@@ -0,0 +1,70 @@
+package colour
+
+import "github.com/pkg/errors"
+
+// Deficiency is a kind of colour vision deficiency.
+type Deficiency string
+
+const (
+	// Protanopia is missing long wavelength (red) cones.
+	Protanopia Deficiency = "protanopia"
+	// Deuteranopia is missing medium wavelength (green) cones.
+	Deuteranopia Deficiency = "deuteranopia"
+	// Tritanopia is missing short wavelength (blue) cones.
+	Tritanopia Deficiency = "tritanopia"
+	// Achromatopsia is seeing no colour at all, only luminance.
+	Achromatopsia Deficiency = "achromatopsia"
+)
+
+// Deficiencies lists every deficiency Simulate understands.
+var Deficiencies = []Deficiency{Protanopia, Deuteranopia, Tritanopia, Achromatopsia}
+
+// machado are the full severity matrices from Machado, Oliveira and Fernandes (2009), applied to
+// linear sRGB.
+var machado = map[Deficiency][3][3]float64{
+	Protanopia: {
+		{0.152286, 1.052583, -0.204868},
+		{0.114503, 0.786281, 0.099216},
+		{-0.003882, -0.048116, 1.051998},
+	},
+	Deuteranopia: {
+		{0.367322, 0.860646, -0.227968},
+		{0.280085, 0.672501, 0.047413},
+		{-0.011820, 0.042940, 0.968881},
+	},
+	Tritanopia: {
+		{1.255528, -0.076749, -0.178779},
+		{-0.078411, 0.930809, 0.147602},
+		{0.004733, 0.691367, 0.303900},
+	},
+}
+
+// ParseDeficiency validates a deficiency name.
+func ParseDeficiency(s string) (Deficiency, error) {
+	for _, d := range Deficiencies {
+		if string(d) == s {
+			return d, nil
+		}
+	}
+	return "", errors.Errorf("unknown colour vision deficiency %q, expected one of %v", s, Deficiencies)
+}
+
+// Simulate returns c as someone with d would see it, keeping alpha. Protanopia, deuteranopia and
+// tritanopia use the Machado model; achromatopsia maps c to the grey of the same luminance.
+func (c Colour) Simulate(d Deficiency) Colour {
+	r, g, b := c.linear()
+	if d == Achromatopsia {
+		y := 0.2126*r + 0.7152*g + 0.0722*b
+		v := channel(fromLinear(y))
+		return RGBA(v, v, v, c.a)
+	}
+
+	m, ok := machado[d]
+	if !ok {
+		return c
+	}
+	sr := m[0][0]*r + m[0][1]*g + m[0][2]*b
+	sg := m[1][0]*r + m[1][1]*g + m[1][2]*b
+	sb := m[2][0]*r + m[2][1]*g + m[2][2]*b
+	return RGBA(channel(fromLinear(clamp(sr, 0, 1))), channel(fromLinear(clamp(sg, 0, 1))), channel(fromLinear(clamp(sb, 0, 1))), c.a)
+}
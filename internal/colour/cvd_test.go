@@ -0,0 +1,37 @@
+package colour_test
+
+import (
+	"testing"
+
+	"hexbot/internal/colour"
+)
+
+func TestColour_Simulate(t *testing.T) {
+	white, black := colour.MustParse("#FFFFFF"), colour.MustParse("#000000")
+	for _, d := range colour.Deficiencies {
+		if got := white.Simulate(d); got != white {
+			t.Errorf("%s turned white into %s", d, got)
+		}
+		if got := black.Simulate(d); got != black {
+			t.Errorf("%s turned black into %s", d, got)
+		}
+	}
+
+	if got := colour.MustParse("#FF000080").Simulate(colour.Achromatopsia); got.R() != got.G() || got.G() != got.B() || got.A() != 0x80 {
+		t.Errorf("expected a grey keeping alpha, got %s", got)
+	}
+
+	// Red and green, far apart normally, become hard to tell apart without red or green cones,
+	// while blue and yellow stay distinct.
+	red, green := colour.MustParse("#D62728"), colour.MustParse("#2CA02C")
+	normal := red.DeltaE2000(green)
+	for _, d := range []colour.Deficiency{colour.Protanopia, colour.Deuteranopia} {
+		if got := red.Simulate(d).DeltaE2000(green.Simulate(d)); got > normal/2 {
+			t.Errorf("%s: red and green are still %.1f apart, normally %.1f", d, got, normal)
+		}
+	}
+	blue, yellow := colour.MustParse("#0000FF"), colour.MustParse("#FFFF00")
+	if got := blue.Simulate(colour.Deuteranopia).DeltaE2000(yellow.Simulate(colour.Deuteranopia)); got < 50 {
+		t.Errorf("deuteranopia: blue and yellow only %.1f apart", got)
+	}
+}
@@ -0,0 +1,161 @@
+package colour
+
+import (
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Metric measures the perceptual distance between two colours. Zero means identical.
+type Metric func(a, b Colour) float64
+
+// Names of the metrics understood by MetricByName.
+const (
+	MetricDE76   = "de76"
+	MetricDE94   = "de94"
+	MetricDE2000 = "de2000"
+	MetricOKLab  = "oklab"
+)
+
+var metrics = map[string]Metric{
+	MetricDE76:   Colour.DeltaE76,
+	MetricDE94:   Colour.DeltaE94,
+	MetricDE2000: Colour.DeltaE2000,
+	MetricOKLab:  Colour.OKLabDistance,
+}
+
+// MetricByName returns one of the MetricDE76, MetricDE94, MetricDE2000 or MetricOKLab metrics.
+func MetricByName(name string) (Metric, error) {
+	m, ok := metrics[name]
+	if !ok {
+		names := make([]string, 0, len(metrics))
+		for n := range metrics {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, errors.Errorf("unknown colour metric %q, expected one of %v", name, names)
+	}
+	return m, nil
+}
+
+// DeltaE76 is the CIE 1976 colour difference, the Euclidean distance in CIELAB.
+func (c Colour) DeltaE76(o Colour) float64 {
+	return DeltaE76(c.Lab(), o.Lab())
+}
+
+// DeltaE94 is the CIE 1994 colour difference with graphic arts weights, taking c as the reference.
+func (c Colour) DeltaE94(o Colour) float64 {
+	return DeltaE94(c.Lab(), o.Lab())
+}
+
+// DeltaE2000 is the CIEDE2000 colour difference.
+func (c Colour) DeltaE2000(o Colour) float64 {
+	return DeltaE2000(c.Lab(), o.Lab())
+}
+
+// OKLabDistance is the Euclidean distance in OKLab. A difference of about 0.02 is just noticeable.
+func (c Colour) OKLabDistance(o Colour) float64 {
+	a, b := c.OKLab(), o.OKLab()
+	return math.Sqrt(sq(a.L-b.L) + sq(a.A-b.A) + sq(a.B-b.B))
+}
+
+// DeltaE76 is the Euclidean distance between two CIELAB colours.
+func DeltaE76(a, b Lab) float64 {
+	return math.Sqrt(sq(a.L-b.L) + sq(a.A-b.A) + sq(a.B-b.B))
+}
+
+// DeltaE94 is the CIE 1994 difference of b from the reference a, with graphic arts weights.
+func DeltaE94(a, b Lab) float64 {
+	const k1, k2 = 0.045, 0.015
+
+	c1, c2 := math.Hypot(a.A, a.B), math.Hypot(b.A, b.B)
+	dL, dC := a.L-b.L, c1-c2
+	dH2 := sq(a.A-b.A) + sq(a.B-b.B) - sq(dC)
+	if dH2 < 0 {
+		dH2 = 0
+	}
+
+	sC, sH := 1+k1*c1, 1+k2*c1
+	return math.Sqrt(sq(dL) + sq(dC/sC) + dH2/sq(sH))
+}
+
+// DeltaE2000 is the CIEDE2000 difference between two CIELAB colours, following
+// Sharma, Wu and Dalal, "The CIEDE2000 Color-Difference Formula" (2005).
+func DeltaE2000(a, b Lab) float64 {
+	pow25to7 := math.Pow(25, 7)
+
+	c1, c2 := math.Hypot(a.A, a.B), math.Hypot(b.A, b.B)
+	cBar7 := math.Pow((c1+c2)/2, 7)
+	g := 0.5 * (1 - math.Sqrt(cBar7/(cBar7+pow25to7)))
+
+	a1p, a2p := (1+g)*a.A, (1+g)*b.A
+	c1p, c2p := math.Hypot(a1p, a.B), math.Hypot(a2p, b.B)
+	h1p, h2p := hueAngle(a.B, a1p), hueAngle(b.B, a2p)
+
+	dLp := b.L - a.L
+	dCp := c2p - c1p
+
+	var dhp float64
+	if c1p*c2p != 0 {
+		dhp = h2p - h1p
+		switch {
+		case dhp > 180:
+			dhp -= 360
+		case dhp < -180:
+			dhp += 360
+		}
+	}
+	dHp := 2 * math.Sqrt(c1p*c2p) * math.Sin(radians(dhp/2))
+
+	lBarp := (a.L + b.L) / 2
+	cBarp := (c1p + c2p) / 2
+
+	hBarp := h1p + h2p
+	if c1p*c2p != 0 {
+		switch {
+		case math.Abs(h1p-h2p) <= 180:
+			hBarp /= 2
+		case h1p+h2p < 360:
+			hBarp = (hBarp + 360) / 2
+		default:
+			hBarp = (hBarp - 360) / 2
+		}
+	}
+
+	t := 1 -
+		0.17*math.Cos(radians(hBarp-30)) +
+		0.24*math.Cos(radians(2*hBarp)) +
+		0.32*math.Cos(radians(3*hBarp+6)) -
+		0.20*math.Cos(radians(4*hBarp-63))
+	dTheta := 30 * math.Exp(-sq((hBarp-275)/25))
+	cBarp7 := math.Pow(cBarp, 7)
+	rC := 2 * math.Sqrt(cBarp7/(cBarp7+pow25to7))
+
+	sL := 1 + 0.015*sq(lBarp-50)/math.Sqrt(20+sq(lBarp-50))
+	sC := 1 + 0.045*cBarp
+	sH := 1 + 0.015*cBarp*t
+	rT := -math.Sin(radians(2*dTheta)) * rC
+
+	return math.Sqrt(sq(dLp/sL) + sq(dCp/sC) + sq(dHp/sH) + rT*(dCp/sC)*(dHp/sH))
+}
+
+// hueAngle returns atan2(b, a) in degrees, [0, 360).
+func hueAngle(b, a float64) float64 {
+	if a == 0 && b == 0 {
+		return 0
+	}
+	h := math.Atan2(b, a) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+	return h
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func sq(v float64) float64 {
+	return v * v
+}
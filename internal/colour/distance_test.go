@@ -0,0 +1,62 @@
+package colour_test
+
+import (
+	"testing"
+
+	"hexbot/internal/colour"
+)
+
+func TestDeltaE2000(t *testing.T) {
+	// A selection of pairs from Sharma, Wu and Dalal's CIEDE2000 test data.
+	tests := []struct {
+		A, B colour.Lab
+		Want float64
+	}{
+		{colour.Lab{L: 50, A: 2.6772, B: -79.7751}, colour.Lab{L: 50, A: 0, B: -82.7485}, 2.0425},
+		{colour.Lab{L: 50, A: 0, B: 0}, colour.Lab{L: 50, A: -1, B: 2}, 2.3669},
+		{colour.Lab{L: 50, A: 2.49, B: -0.001}, colour.Lab{L: 50, A: -2.49, B: 0.0011}, 7.2195},
+		{colour.Lab{L: 50, A: 2.5, B: 0}, colour.Lab{L: 73, A: 25, B: -18}, 27.1492},
+		{colour.Lab{L: 60.2574, A: -34.0099, B: 36.2677}, colour.Lab{L: 60.4626, A: -34.1751, B: 39.4387}, 1.2644},
+		{colour.Lab{L: 2.0776, A: 0.0795, B: -1.135}, colour.Lab{L: 0.9033, A: -0.0636, B: -0.5514}, 0.9082},
+	}
+
+	for _, tt := range tests {
+		if got := colour.DeltaE2000(tt.A, tt.B); !near(got, tt.Want, 1e-4) {
+			t.Errorf("DeltaE2000(%+v, %+v) = %.4f, want %.4f", tt.A, tt.B, got, tt.Want)
+		}
+		if got := colour.DeltaE2000(tt.B, tt.A); !near(got, tt.Want, 1e-4) {
+			t.Errorf("DeltaE2000 is not symmetric for %+v, %+v", tt.A, tt.B)
+		}
+	}
+}
+
+func TestDeltaE76And94(t *testing.T) {
+	a, b := colour.Lab{L: 50, A: 0, B: 0}, colour.Lab{L: 53, A: 4, B: 0}
+	if got := colour.DeltaE76(a, b); !near(got, 5, 1e-9) {
+		t.Errorf("DeltaE76 = %v, want 5", got)
+	}
+	// With a neutral reference the chroma and hue weights are 1, so only ΔL and ΔC contribute.
+	if got := colour.DeltaE94(a, b); !near(got, 5, 1e-9) {
+		t.Errorf("DeltaE94 = %v, want 5", got)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	a, b := colour.MustParse("#FF0000"), colour.MustParse("#FE0101")
+	for _, name := range []string{colour.MetricDE76, colour.MetricDE94, colour.MetricDE2000, colour.MetricOKLab} {
+		m, err := colour.MetricByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := m(a, a); d != 0 {
+			t.Errorf("%s: expected identical colours to have distance 0, got %v", name, d)
+		}
+		near, far := m(a, b), m(a, colour.MustParse("#0000FF"))
+		if near <= 0 || near >= far {
+			t.Errorf("%s: expected 0 < %v < %v", name, near, far)
+		}
+	}
+	if _, err := colour.MetricByName("euclid"); err == nil {
+		t.Error("expected an error for an unknown metric")
+	}
+}
@@ -0,0 +1,50 @@
+package colour
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// MarshalJSON encodes c as its hex string.
+func (c Colour) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Hex())
+}
+
+// UnmarshalJSON accepts any notation understood by Parse.
+func (c *Colour) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return errors.Wrap(err, "colour must be a JSON string")
+	}
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
+
+// MarshalBSONValue stores c as its hex string.
+func (c Colour) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bsontype.String, bsoncore.AppendString(nil, c.Hex()), nil
+}
+
+// UnmarshalBSONValue reads a colour stored as a string.
+func (c *Colour) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	if t != bsontype.String {
+		return errors.Errorf("cannot decode BSON %s into a colour", t)
+	}
+	s, _, ok := bsoncore.ReadString(data)
+	if !ok {
+		return errors.New("malformed BSON string for colour")
+	}
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
@@ -0,0 +1,228 @@
+package colour
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Reasons a colour can fail to parse. They are wrapped in a *ParseError and can be
+// recovered with errors.Cause.
+var (
+	ErrEmpty          = errors.New("colour is empty")
+	ErrNotation       = errors.New("unrecognised colour notation")
+	ErrHexLength      = errors.New("hex colours must have 3, 4, 6 or 8 digits")
+	ErrHexDigit       = errors.New("invalid hex digit")
+	ErrArgumentCount  = errors.New("wrong number of arguments")
+	ErrArgument       = errors.New("argument is not a number")
+	ErrArgumentRange  = errors.New("argument out of range")
+	ErrMixedSeparator = errors.New("arguments must be separated by either commas or spaces")
+)
+
+// ParseError records the input that failed to parse and why.
+type ParseError struct {
+	Input string
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("cannot parse colour %q: %v", e.Input, e.Err)
+}
+
+// Cause returns the underlying reason, one of the Err variables.
+func (e *ParseError) Cause() error {
+	return e.Err
+}
+
+// Parse reads a colour in one of the CSS notations:
+//
+//	#RGB, #RGBA, #RRGGBB, #RRGGBBAA
+//	rgb(255, 0, 0), rgba(255, 0, 0, 0.5), rgb(100% 0% 0% / 50%)
+//	hsl(120, 100%, 50%), hsla(120deg, 100%, 50%, 0.5), hsl(120 100% 50% / 50%)
+//
+// Anything else, including out of range values, is rejected with a *ParseError.
+func Parse(s string) (Colour, error) {
+	in := strings.ToLower(strings.TrimSpace(s))
+
+	var c Colour
+	var err error
+	switch {
+	case in == "":
+		err = ErrEmpty
+	case strings.HasPrefix(in, "#"):
+		c, err = parseHex(in[1:])
+	case strings.HasPrefix(in, "rgb"):
+		c, err = parseFunc(in, "rgb", parseRGB)
+	case strings.HasPrefix(in, "hsl"):
+		c, err = parseFunc(in, "hsl", parseHSL)
+	default:
+		err = ErrNotation
+	}
+	if err != nil {
+		return Colour{}, &ParseError{Input: s, Err: err}
+	}
+	return c, nil
+}
+
+// MustParse is like Parse but panics on malformed input. It is meant for constants.
+func MustParse(s string) Colour {
+	c, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func parseHex(h string) (Colour, error) {
+	digits := make([]uint8, len(h))
+	for i := 0; i < len(h); i++ {
+		d, ok := hexDigit(h[i])
+		if !ok {
+			return Colour{}, ErrHexDigit
+		}
+		digits[i] = d
+	}
+
+	switch len(h) {
+	case 3, 4:
+		c := RGB(digits[0]*0x11, digits[1]*0x11, digits[2]*0x11)
+		if len(h) == 4 {
+			c.a = digits[3] * 0x11
+		}
+		return c, nil
+	case 6, 8:
+		c := RGB(digits[0]<<4|digits[1], digits[2]<<4|digits[3], digits[4]<<4|digits[5])
+		if len(h) == 8 {
+			c.a = digits[6]<<4 | digits[7]
+		}
+		return c, nil
+	}
+	return Colour{}, ErrHexLength
+}
+
+func hexDigit(b byte) (uint8, bool) {
+	switch {
+	case b >= '0' && b <= '9':
+		return b - '0', true
+	case b >= 'a' && b <= 'f':
+		return b - 'a' + 10, true
+	}
+	return 0, false
+}
+
+// parseFunc handles the shared shape of rgb(), rgba(), hsl() and hsla(), passing the
+// three colour arguments and the optional alpha on to build.
+func parseFunc(in, name string, build func(args []string) (Colour, error)) (Colour, error) {
+	rest := strings.TrimPrefix(in, name)
+	rest = strings.TrimPrefix(rest, "a")
+	rest = strings.TrimSpace(rest)
+	if !strings.HasPrefix(rest, "(") || !strings.HasSuffix(rest, ")") {
+		return Colour{}, ErrNotation
+	}
+	body := strings.TrimSpace(rest[1 : len(rest)-1])
+
+	var args []string
+	if strings.Contains(body, ",") {
+		if strings.Contains(body, "/") {
+			return Colour{}, ErrMixedSeparator
+		}
+		for _, a := range strings.Split(body, ",") {
+			args = append(args, strings.TrimSpace(a))
+		}
+	} else {
+		parts := strings.SplitN(body, "/", 2)
+		args = strings.Fields(parts[0])
+		if len(parts) == 2 {
+			if len(args) != 3 {
+				return Colour{}, ErrArgumentCount
+			}
+			args = append(args, strings.TrimSpace(parts[1]))
+		}
+	}
+
+	if len(args) != 3 && len(args) != 4 {
+		return Colour{}, ErrArgumentCount
+	}
+	for _, a := range args {
+		if a == "" {
+			return Colour{}, ErrArgument
+		}
+	}
+
+	c, err := build(args[:3])
+	if err != nil {
+		return Colour{}, err
+	}
+	if len(args) == 4 {
+		alpha, err := number(args[3], 1)
+		if err != nil {
+			return Colour{}, err
+		}
+		c.a = channel(alpha)
+	}
+	return c, nil
+}
+
+func parseRGB(args []string) (Colour, error) {
+	var v [3]float64
+	for i, a := range args {
+		n, err := number(a, 255)
+		if err != nil {
+			return Colour{}, err
+		}
+		v[i] = n / 255
+	}
+	return RGB(channel(v[0]), channel(v[1]), channel(v[2])), nil
+}
+
+func parseHSL(args []string) (Colour, error) {
+	h, err := parseFloat(strings.TrimSuffix(args[0], "deg"))
+	if err != nil {
+		return Colour{}, err
+	}
+	if !strings.HasSuffix(args[1], "%") || !strings.HasSuffix(args[2], "%") {
+		return Colour{}, ErrArgument
+	}
+	s, err := number(args[1], 1)
+	if err != nil {
+		return Colour{}, err
+	}
+	l, err := number(args[2], 1)
+	if err != nil {
+		return Colour{}, err
+	}
+	return HSL{H: h, S: s, L: l}.Colour(), nil
+}
+
+// number parses a plain number in [0, max] or a percentage in [0%, 100%], returning
+// percentages scaled onto [0, max].
+func number(a string, max float64) (float64, error) {
+	percent := strings.HasSuffix(a, "%")
+	v, err := parseFloat(strings.TrimSuffix(a, "%"))
+	if err != nil {
+		return 0, err
+	}
+	if percent {
+		if v < 0 || v > 100 {
+			return 0, ErrArgumentRange
+		}
+		return v / 100 * max, nil
+	}
+	if v < 0 || v > max {
+		return 0, ErrArgumentRange
+	}
+	return v, nil
+}
+
+// parseFloat parses a finite number. strconv accepts "nan" and "inf", which no notation allows and
+// which would otherwise slip past the range checks.
+func parseFloat(a string) (float64, error) {
+	v, err := strconv.ParseFloat(a, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, ErrArgument
+	}
+	return v, nil
+}
@@ -0,0 +1,271 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Config is the runtime configuration, read from the environment.
+type Config struct {
+	LogLevel string
+
+	HexbotURL       string
+	HexbotTimeout   time.Duration
+	HexbotUserAgent string
+	// HexbotOffline swaps the HTTP client for the local deterministic generator.
+	HexbotOffline bool
+	HexbotSeed    int64
+
+	RetryAttempts    int
+	RetryBaseDelay   time.Duration
+	RetryMaxDelay    time.Duration
+	BreakerThreshold int
+	BreakerCoolDown  time.Duration
+
+	MongoURI        string
+	MongoDatabase   string
+	MongoCollection string
+	// MongoPaletteCollection holds generated and imported palettes.
+	MongoPaletteCollection string
+	// MongoRunCollection records scheduled fetches.
+	MongoRunCollection string
+	// MongoJobCollection holds the state of scheduled jobs shared by every replica, such as pauses.
+	MongoJobCollection string
+	MongoTimeout       time.Duration
+
+	// ScheduleFile is a JSON list of fetch jobs run by serve; see scheduler.LoadFile. Nothing is
+	// scheduled when it is empty.
+	ScheduleFile string
+	// ScheduleTimezone is the IANA zone cron schedules are read in.
+	ScheduleTimezone string
+	// LeaderElection makes replicas elect one of them to run the schedule, through a lease in
+	// MongoLeaseCollection named LeaseName that lasts LeaseTTL without renewal. The leader's writes are
+	// fenced in transactions, so Mongo must run as a replica set.
+	LeaderElection       bool
+	LeaseName            string
+	LeaseTTL             time.Duration
+	MongoLeaseCollection string
+
+	// DedupMode is off, reject or merge; see service.DedupMode.
+	DedupMode      string
+	DedupThreshold float64
+	DedupWindow    time.Duration
+	DedupMetric    string
+
+	// NameDictionaries are extra colour name files searched after the built in dictionaries.
+	NameDictionaries []string
+
+	// ThemeLevel is the WCAG level generated themes meet unless told otherwise; see theme.Level.
+	ThemeLevel string
+	// CVDThreshold is the CIEDE2000 difference below which colours are flagged as indistinguishable
+	// with a colour vision deficiency; palette.DefaultCVDThreshold when zero.
+	CVDThreshold float64
+
+	// StreamBuffer is how many colours a live stream subscriber may fall behind by before it is cut off;
+	// stream.DefaultBuffer when zero.
+	StreamBuffer int
+	// StreamOrigins are the web origins, besides the API's own, whose pages may open WebSockets to the
+	// live stream.
+	StreamOrigins []string
+}
+
+// Load reads the configuration from environment variables, using defaults where unset.
+func Load() (*Config, error) {
+	cfg := &Config{
+		LogLevel:               os.Getenv("LOG_LEVEL"),
+		HexbotURL:              os.Getenv("HEXBOT_URL"),
+		HexbotUserAgent:        os.Getenv("HEXBOT_USER_AGENT"),
+		MongoURI:               str("MONGO_URI", "mongodb://localhost:27017"),
+		MongoDatabase:          str("MONGO_DATABASE", "hexbot"),
+		MongoCollection:        str("MONGO_COLLECTION", "colours"),
+		MongoPaletteCollection: str("MONGO_PALETTE_COLLECTION", "palettes"),
+		MongoRunCollection:     str("MONGO_RUN_COLLECTION", "runs"),
+		MongoJobCollection:     str("MONGO_JOB_COLLECTION", "jobs"),
+		ScheduleFile:           os.Getenv("SCHEDULE_FILE"),
+		ScheduleTimezone:       str("SCHEDULE_TIMEZONE", "UTC"),
+		LeaseName:              str("LEASE_NAME", "scheduler"),
+		MongoLeaseCollection:   str("MONGO_LEASE_COLLECTION", "leases"),
+		DedupMode:              str("DEDUP_MODE", "off"),
+		DedupMetric:            str("DEDUP_METRIC", "de2000"),
+		NameDictionaries:       list("NAME_DICTIONARIES"),
+		ThemeLevel:             str("THEME_LEVEL", "AA"),
+		StreamOrigins:          list("STREAM_ORIGINS"),
+	}
+
+	var err error
+	if cfg.HexbotTimeout, err = duration("HEXBOT_TIMEOUT", 10*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.HexbotOffline, err = boolean("HEXBOT_OFFLINE", false); err != nil {
+		return nil, err
+	}
+	if cfg.HexbotSeed, err = integer("HEXBOT_SEED", time.Now().UnixNano()); err != nil {
+		return nil, err
+	}
+
+	var n int64
+	if n, err = integer("HEXBOT_RETRY_ATTEMPTS", 3); err != nil {
+		return nil, err
+	}
+	cfg.RetryAttempts = int(n)
+	if cfg.RetryBaseDelay, err = duration("HEXBOT_RETRY_BASE_DELAY", 200*time.Millisecond); err != nil {
+		return nil, err
+	}
+	if cfg.RetryMaxDelay, err = duration("HEXBOT_RETRY_MAX_DELAY", 5*time.Second); err != nil {
+		return nil, err
+	}
+	if n, err = integer("HEXBOT_BREAKER_THRESHOLD", 5); err != nil {
+		return nil, err
+	}
+	cfg.BreakerThreshold = int(n)
+	if cfg.BreakerCoolDown, err = duration("HEXBOT_BREAKER_COOLDOWN", 30*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.MongoTimeout, err = duration("MONGO_TIMEOUT", 10*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.DedupThreshold, err = float("DEDUP_THRESHOLD", 2.3); err != nil {
+		return nil, err
+	}
+	if cfg.DedupWindow, err = duration("DEDUP_WINDOW", time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.LeaderElection, err = boolean("LEADER_ELECTION", false); err != nil {
+		return nil, err
+	}
+	if cfg.LeaseTTL, err = duration("LEASE_TTL", 15*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.CVDThreshold, err = float("CVD_THRESHOLD", 0); err != nil {
+		return nil, err
+	}
+	if n, err = integer("STREAM_BUFFER", 0); err != nil {
+		return nil, err
+	}
+	cfg.StreamBuffer = int(n)
+
+	return cfg, nil
+}
+
+// redacted stands in for secrets in Redacted configurations.
+const redacted = "REDACTED"
+
+// Redacted returns a copy of cfg that is safe to show, with the passwords and secret looking query
+// parameters of its URIs replaced.
+func (cfg Config) Redacted() Config {
+	cfg.MongoURI = redactURI(cfg.MongoURI)
+	cfg.HexbotURL = redactURI(cfg.HexbotURL)
+	cfg.NameDictionaries = append([]string(nil), cfg.NameDictionaries...)
+	cfg.StreamOrigins = append([]string(nil), cfg.StreamOrigins...)
+	return cfg
+}
+
+// redactURI hides uri's password and secrets in its query without parsing it as a URL, since Mongo URIs
+// list several hosts, which net/url rejects.
+func redactURI(uri string) string {
+	scheme := strings.Index(uri, "://")
+	if scheme < 0 {
+		return uri
+	}
+	rest, query := uri[scheme+3:], ""
+	if i := strings.Index(rest, "?"); i >= 0 {
+		rest, query = rest[:i], rest[i:]
+	}
+	authority, path := rest, ""
+	if i := strings.Index(rest, "/"); i >= 0 {
+		authority, path = rest[:i], rest[i:]
+	}
+	if at := strings.LastIndex(authority, "@"); at >= 0 {
+		if colon := strings.Index(authority[:at], ":"); colon >= 0 {
+			authority = authority[:colon+1] + redacted + authority[at:]
+		}
+	}
+
+	params := strings.Split(strings.TrimPrefix(query, "?"), "&")
+	for i, p := range params {
+		eq := strings.Index(p, "=")
+		if eq < 0 {
+			continue
+		}
+		key := strings.ToLower(p[:eq])
+		for _, secret := range []string{"password", "secret", "token", "key", "authmechanismproperties"} {
+			if strings.Contains(key, secret) {
+				params[i] = p[:eq+1] + redacted
+				break
+			}
+		}
+	}
+	if query != "" {
+		query = "?" + strings.Join(params, "&")
+	}
+	return uri[:scheme+3] + authority + path + query
+}
+
+func str(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func list(key string) []string {
+	var values []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func duration(key string, fallback time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, errors.Wrapf(err, "problem parsing %s", key)
+	}
+	return d, nil
+}
+
+func boolean(key string, fallback bool) (bool, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, errors.Wrapf(err, "problem parsing %s", key)
+	}
+	return b, nil
+}
+
+func integer(key string, fallback int64) (int64, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "problem parsing %s", key)
+	}
+	return i, nil
+}
+
+func float(key string, fallback float64) (float64, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "problem parsing %s", key)
+	}
+	return f, nil
+}
@@ -1,18 +1,284 @@
 package db
 
-import(
+import (
+	"context"
+	"time"
 
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/requestctx"
 )
 
-type db struct {
+// Config describes where colours are stored.
+type Config struct {
+	URI        string
+	Database   string
+	Collection string
+	// PaletteCollection holds palettes; "palettes" when empty.
+	PaletteCollection string
+	// RunCollection holds scheduled job runs; "runs" when empty.
+	RunCollection string
+	// JobCollection holds scheduled jobs' shared state; "jobs" when empty.
+	JobCollection string
+	// LeaseCollection holds leader election leases; "leases" when empty.
+	LeaseCollection string
+	// Source is recorded on every colour whose context does not carry one.
+	Source string
+	// Timeout bounds connecting and creating indexes at start up.
+	Timeout time.Duration
+	// Monitor, when set, is told about every command sent to Mongo.
+	Monitor *event.CommandMonitor
+}
+
+// ColourDocument is how a colour is stored in Mongo.
+type ColourDocument struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Colour colour.Colour      `bson:"hex" json:"hex"`
+	R      int                `bson:"r" json:"r"`
+	G      int                `bson:"g" json:"g"`
+	B      int                `bson:"b" json:"b"`
+	// Hue is in degrees, 0 to 360; Saturation and Lightness are percentages, 0 to 100.
+	Hue        float64 `bson:"h" json:"h"`
+	Saturation float64 `bson:"s" json:"s"`
+	Lightness  float64 `bson:"l" json:"l"`
+	// Luminance is the WCAG relative luminance, 0 to 1.
+	Luminance  float64 `bson:"luminance" json:"luminance"`
+	ColourName `bson:",inline"`
+	FetchedAt  time.Time `bson:"fetched_at" json:"fetched_at"`
+	// SeenCount counts this colour and every near duplicate merged into it since.
+	SeenCount  int       `bson:"seen_count" json:"seen_count"`
+	LastSeenAt time.Time `bson:"last_seen_at" json:"last_seen_at"`
+	Source     string    `bson:"source" json:"source"`
+	RequestID  string    `bson:"request_id,omitempty" json:"request_id,omitempty"`
+}
+
+// ColourName is the nearest named colour to a stored colour.
+type ColourName struct {
+	Name       string `bson:"name,omitempty" json:"name,omitempty"`
+	Dictionary string `bson:"name_dictionary,omitempty" json:"name_dictionary,omitempty"`
+	// Distance is the CIEDE2000 difference between the colour and the named one.
+	Distance float64 `bson:"name_distance,omitempty" json:"name_distance,omitempty"`
+}
+
+// DB is a Mongo backed store for colours.
+type DB struct {
+	log      *logging.Logger
+	client   *mongo.Client
+	colours  *mongo.Collection
+	palettes *mongo.Collection
+	runs     *mongo.Collection
+	jobs     *mongo.Collection
+	leases   *mongo.Collection
+	source   string
+	// fenceHook, when set, runs inside fenced writes between checking the token and writing, for tests.
+	fenceHook func()
+}
+
+// NewDB connects to Mongo, checks the connection and makes sure the indexes exist.
+func NewDB(ctx context.Context, log *logging.Logger, cfg Config) (*DB, error) {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.PaletteCollection == "" {
+		cfg.PaletteCollection = "palettes"
+	}
+	if cfg.RunCollection == "" {
+		cfg.RunCollection = "runs"
+	}
+	if cfg.JobCollection == "" {
+		cfg.JobCollection = "jobs"
+	}
+	if cfg.LeaseCollection == "" {
+		cfg.LeaseCollection = "leases"
+	}
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
 
+	opts := options.Client().ApplyURI(cfg.URI)
+	if cfg.Monitor != nil {
+		opts.SetMonitor(cfg.Monitor)
+	}
+	client, err := mongo.Connect(ctx, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem connecting to mongo")
+	}
+	if err := client.Ping(ctx, readpref.Primary()); err != nil {
+		client.Disconnect(context.Background())
+		return nil, errors.Wrap(err, "problem pinging mongo")
+	}
+
+	db := &DB{
+		log:      log,
+		client:   client,
+		colours:  client.Database(cfg.Database).Collection(cfg.Collection),
+		palettes: client.Database(cfg.Database).Collection(cfg.PaletteCollection),
+		runs:     client.Database(cfg.Database).Collection(cfg.RunCollection),
+		jobs:     client.Database(cfg.Database).Collection(cfg.JobCollection),
+		leases:   client.Database(cfg.Database).Collection(cfg.LeaseCollection),
+		source:   cfg.Source,
+	}
+	if err := db.ensureIndexes(ctx); err != nil {
+		client.Disconnect(context.Background())
+		return nil, err
+	}
+	return db, nil
 }
 
+func (db *DB) ensureIndexes(ctx context.Context) error {
+	_, err := db.colours.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "fetched_at", Value: -1}, {Key: "_id", Value: -1}}, Options: options.Index().SetName("fetched_at_id")},
+		{Keys: bson.D{{Key: "hex", Value: 1}, {Key: "fetched_at", Value: -1}, {Key: "_id", Value: -1}}, Options: options.Index().SetName("hex_fetched_at_id")},
+		{Keys: bson.D{{Key: "source", Value: 1}, {Key: "fetched_at", Value: -1}, {Key: "_id", Value: -1}}, Options: options.Index().SetName("source_fetched_at_id")},
+		{Keys: bson.D{{Key: "name", Value: 1}, {Key: "fetched_at", Value: -1}, {Key: "_id", Value: -1}}, Options: options.Index().SetName("name_fetched_at_id")},
+		{Keys: bson.D{{Key: "h", Value: 1}, {Key: "_id", Value: 1}}, Options: options.Index().SetName("h_id")},
+		{Keys: bson.D{{Key: "l", Value: 1}, {Key: "_id", Value: 1}}, Options: options.Index().SetName("l_id")},
+		{Keys: bson.D{{Key: "s", Value: 1}, {Key: "_id", Value: 1}}, Options: options.Index().SetName("s_id")},
+		{Keys: bson.D{{Key: "request_id", Value: 1}}, Options: options.Index().SetName("request_id").SetSparse(true)},
+	})
+	if err != nil {
+		return errors.Wrap(err, "problem creating colour indexes")
+	}
+
+	// FindPalettes pages on _id, which Mongo always indexes, so these serve its filters.
+	_, err = db.palettes.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "source", Value: 1}, {Key: "_id", Value: -1}}, Options: options.Index().SetName("source_id")},
+		{Keys: bson.D{{Key: "harmony", Value: 1}, {Key: "_id", Value: -1}}, Options: options.Index().SetName("harmony_id")},
+	})
+	if err != nil {
+		return errors.Wrap(err, "problem creating palette indexes")
+	}
+	// Earlier versions indexed created_at, which no query uses.
+	for _, name := range []string{"created_at_id", "harmony_created_at"} {
+		if _, err := db.palettes.Indexes().DropOne(ctx, name); err != nil && !isIndexNotFound(err) {
+			return errors.Wrapf(err, "problem dropping palette index %s", name)
+		}
+	}
 
-func (db *db) NewDB() (err error){
-	return nil
+	_, err = db.runs.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "job", Value: 1}, {Key: "scheduled_at", Value: -1}, {Key: "_id", Value: -1}}, Options: options.Index().SetName("job_scheduled_at_id")},
+	})
+	return errors.Wrap(err, "problem creating run indexes")
 }
-func (db *db) SaveColour() (err error) {
-	return nil
+
+// isIndexNotFound reports whether err is Mongo's IndexNotFound error, returned when dropping an index
+// that is not there.
+func isIndexNotFound(err error) bool {
+	e, ok := err.(mongo.CommandError)
+	return ok && e.Code == 27
 }
 
+// Save stores c and its name along with its components and the source and request id
+// carried by ctx, returning the stored document. It returns ErrFenced when ctx carries a stale
+// fencing token.
+func (db *DB) Save(ctx context.Context, c colour.Colour, name ColourName) (*ColourDocument, error) {
+	doc := newColourDocument(c)
+	doc.ColourName = name
+	doc.ID = primitive.NewObjectID()
+	doc.FetchedAt = time.Now().UTC().Truncate(time.Millisecond)
+	doc.LastSeenAt = doc.FetchedAt
+	doc.SeenCount = 1
+	doc.RequestID = requestctx.RequestID(ctx)
+	if doc.Source = requestctx.Source(ctx); doc.Source == "" {
+		doc.Source = db.source
+	}
+
+	err := db.fenced(ctx, func(ctx context.Context) error {
+		_, err := db.colours.InsertOne(ctx, doc)
+		return errors.Wrap(err, "problem inserting colour")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// MarkSeen records that a near duplicate of the colour with id was fetched again. It returns ErrFenced
+// when ctx carries a stale fencing token.
+func (db *DB) MarkSeen(ctx context.Context, id primitive.ObjectID) error {
+	return db.fenced(ctx, func(ctx context.Context) error {
+		res, err := db.colours.UpdateOne(ctx, bson.D{{Key: "_id", Value: id}}, bson.D{
+			{Key: "$inc", Value: bson.D{{Key: "seen_count", Value: 1}}},
+			{Key: "$set", Value: bson.D{{Key: "last_seen_at", Value: time.Now().UTC()}}},
+		})
+		if err != nil {
+			return errors.Wrap(err, "problem marking colour as seen")
+		}
+		if res.MatchedCount == 0 {
+			return errors.Errorf("no colour with id %s", id.Hex())
+		}
+		return nil
+	})
+}
+
+// ServerStatus describes the Mongo server answering for the DB.
+type ServerStatus struct {
+	Version string `json:"version"`
+	// Host is the server's name for itself and ReplicaSet the set it belongs to, if any.
+	Host           string `json:"host,omitempty"`
+	ReplicaSet     string `json:"replica_set,omitempty"`
+	Primary        bool   `json:"primary"`
+	MaxWireVersion int    `json:"max_wire_version"`
+}
+
+// Ping checks the primary answers and describes it.
+func (db *DB) Ping(ctx context.Context) (*ServerStatus, error) {
+	if err := db.client.Ping(ctx, readpref.Primary()); err != nil {
+		return nil, errors.Wrap(err, "problem pinging mongo")
+	}
+
+	admin := db.client.Database("admin")
+	var hello struct {
+		IsMaster       bool   `bson:"ismaster"`
+		Me             string `bson:"me"`
+		SetName        string `bson:"setName"`
+		MaxWireVersion int32  `bson:"maxWireVersion"`
+	}
+	if err := admin.RunCommand(ctx, bson.D{{Key: "isMaster", Value: 1}}).Decode(&hello); err != nil {
+		return nil, errors.Wrap(err, "problem describing mongo server")
+	}
+	var build struct {
+		Version string `bson:"version"`
+	}
+	if err := admin.RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&build); err != nil {
+		return nil, errors.Wrap(err, "problem reading mongo build info")
+	}
+	return &ServerStatus{
+		Version:        build.Version,
+		Host:           hello.Me,
+		ReplicaSet:     hello.SetName,
+		Primary:        hello.IsMaster,
+		MaxWireVersion: int(hello.MaxWireVersion),
+	}, nil
+}
+
+// Disconnect closes every connection to Mongo.
+func (db *DB) Disconnect(ctx context.Context) error {
+	return errors.Wrap(db.client.Disconnect(ctx), "problem disconnecting from mongo")
+}
+
+func newColourDocument(c colour.Colour) *ColourDocument {
+	hsl := c.HSL()
+	return &ColourDocument{
+		Colour:     c,
+		R:          int(c.R()),
+		G:          int(c.G()),
+		B:          int(c.B()),
+		Hue:        hsl.H,
+		Saturation: hsl.S * 100,
+		Lightness:  hsl.L * 100,
+		Luminance:  c.Luminance(),
+	}
+}
+
+// Drop removes the whole database. It exists for tests.
+func (db *DB) Drop(ctx context.Context) error {
+	return errors.Wrap(db.colours.Database().Drop(ctx), "problem dropping database")
+}
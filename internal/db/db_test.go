@@ -0,0 +1,517 @@
+package db_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/db"
+	"hexbot/internal/palette"
+	"hexbot/internal/requestctx"
+)
+
+// newTestDB connects to the mongod named by MONGO_TEST_URI, skipping the test when it is unset.
+// Every test gets its own database, dropped when the returned func is called.
+func newTestDB(t *testing.T) (*db.DB, func()) {
+	t.Helper()
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI not set, skipping mongo integration test")
+	}
+
+	name := fmt.Sprintf("hexbot_test_%d", time.Now().UnixNano())
+	d, err := db.NewDB(context.Background(), logging.NopLogger, db.Config{
+		URI:        uri,
+		Database:   name,
+		Collection: "colours",
+		Source:     "test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d, func() {
+		d.Drop(context.Background())
+		d.Disconnect(context.Background())
+	}
+}
+
+func TestDB_Save(t *testing.T) {
+	d, done := newTestDB(t)
+	defer done()
+
+	ctx := requestctx.WithRequestID(context.Background(), "req-1")
+	saved, err := d.Save(ctx, colour.RGB(0xA1, 0xB2, 0xC3), db.ColourName{Name: "lavender gray", Dictionary: "test", Distance: 1.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.MarkSeen(ctx, saved.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := d.FindColours(ctx, db.ColourQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Colours) != 1 {
+		t.Fatalf("expected 1 colour, got %d", len(page.Colours))
+	}
+	got := page.Colours[0]
+	if got.Colour.Hex() != "#A1B2C3" || got.R != 0xA1 || got.G != 0xB2 || got.B != 0xC3 || got.Source != "test" || got.RequestID != "req-1" || got.SeenCount != 2 || got.Name != "lavender gray" || got.Dictionary != "test" {
+		t.Errorf("unexpected document %+v", got)
+	}
+}
+
+func TestDB_Ping(t *testing.T) {
+	d, done := newTestDB(t)
+	defer done()
+
+	st, err := d.Ping(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Version == "" || !st.Primary {
+		t.Errorf("expected a primary with a version, got %+v", st)
+	}
+}
+
+func TestDB_NewDBIsRepeatable(t *testing.T) {
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI not set, skipping mongo integration test")
+	}
+
+	// Connecting again to the same database must not trip over the indexes the first connection made
+	// or dropped.
+	cfg := db.Config{
+		URI:        uri,
+		Database:   fmt.Sprintf("hexbot_test_%d", time.Now().UnixNano()),
+		Collection: "colours",
+		Source:     "test",
+	}
+	for i := 0; i < 2; i++ {
+		d, err := db.NewDB(context.Background(), logging.NopLogger, cfg)
+		if err != nil {
+			t.Fatalf("connection %d: %v", i+1, err)
+		}
+		if i == 1 {
+			d.Drop(context.Background())
+		}
+		d.Disconnect(context.Background())
+	}
+}
+
+func TestDB_FindColours(t *testing.T) {
+	d, done := newTestDB(t)
+	defer done()
+
+	ctx := context.Background()
+	hexes := []string{"#FF0000", "#00FF00", "#0000FF", "#FF0000", "#808080"}
+	for _, h := range hexes {
+		name := db.ColourName{Name: "grey"}
+		if h == "#FF0000" {
+			name.Name = "red"
+		}
+		if _, err := d.Save(requestctx.WithSource(ctx, "paging"), colour.MustParse(h), name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var seen []string
+	q := db.ColourQuery{Source: "paging", Limit: 2}
+	for {
+		page, err := d.FindColours(ctx, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range page.Colours {
+			seen = append(seen, c.Colour.Hex())
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+	if len(seen) != len(hexes) {
+		t.Fatalf("expected %d colours across all pages, got %d", len(hexes), len(seen))
+	}
+	for i := range hexes {
+		if seen[i] != hexes[len(hexes)-1-i] {
+			t.Fatalf("expected newest first, got %v", seen)
+		}
+	}
+
+	var each []string
+	err := d.EachColour(ctx, db.ColourQuery{Source: "paging", SortBy: db.SortFetchedAt, Ascending: true}, func(doc db.ColourDocument) error {
+		each = append(each, doc.Colour.Hex())
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(each, ",") != strings.Join(hexes, ",") {
+		t.Errorf("expected EachColour to visit every colour oldest first, got %v", each)
+	}
+	stop := errors.New("stop")
+	if err := d.EachColour(ctx, db.ColourQuery{Source: "paging"}, func(db.ColourDocument) error { return stop }); err != stop {
+		t.Errorf("expected EachColour to return fn's error, got %v", err)
+	}
+
+	red := colour.RGB(255, 0, 0)
+	tests := []struct {
+		Desc  string
+		Query db.ColourQuery
+		Want  int
+	}{
+		{Desc: "exact hex", Query: db.ColourQuery{Colour: &red}, Want: 2},
+		{Desc: "red component", Query: db.ColourQuery{R: &db.Range{Min: 200, Max: 255}}, Want: 2},
+		{Desc: "hue wrapping through 360", Query: db.ColourQuery{Hue: &db.Range{Min: 330, Max: 30}, Saturation: &db.Range{Min: 50, Max: 100}}, Want: 2},
+		{Desc: "greys", Query: db.ColourQuery{Saturation: &db.Range{Min: 0, Max: 0}}, Want: 1},
+		{Desc: "name", Query: db.ColourQuery{Name: " Red "}, Want: 2},
+		{Desc: "unknown source", Query: db.ColourQuery{Source: "nowhere"}, Want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			page, err := d.FindColours(ctx, tt.Query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(page.Colours) != tt.Want {
+				t.Errorf("expected %d colours, got %d", tt.Want, len(page.Colours))
+			}
+		})
+	}
+}
+
+func TestDB_FindColoursSince(t *testing.T) {
+	d, done := newTestDB(t)
+	defer done()
+
+	ctx := context.Background()
+	var ids []primitive.ObjectID
+	for _, h := range []string{"#FF0000", "#00FF00", "#0000FF"} {
+		doc, err := d.Save(ctx, colour.MustParse(h), db.ColourName{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, doc.ID)
+	}
+
+	docs, err := d.FindColoursSince(ctx, ids[0], 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 || docs[0].ID != ids[1] || docs[1].ID != ids[2] {
+		t.Errorf("expected the last two colours oldest first, got %+v", docs)
+	}
+	if docs, err = d.FindColoursSince(ctx, ids[2], 0); err != nil || len(docs) != 0 {
+		t.Errorf("expected nothing after the newest colour, got %+v, %v", docs, err)
+	}
+}
+
+func TestDB_Palettes(t *testing.T) {
+	d, done := newTestDB(t)
+	defer done()
+
+	ctx := requestctx.WithRequestID(context.Background(), "req-2")
+	p, err := palette.Generate(colour.MustParse("#3A7BD5"), palette.Triadic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := d.SavePalette(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := d.FindPalette(ctx, saved.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Seed == nil || *got.Seed != *p.Seed || got.Harmony != palette.Triadic || len(got.Colours) != 3 || got.Colours[1] != p.Colours[1] || got.RequestID != "req-2" {
+		t.Errorf("unexpected palette %+v", got)
+	}
+
+	if _, err := d.FindPalette(ctx, primitive.NewObjectID()); errors.Cause(err) != db.ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	renamed := p
+	renamed.Name = "renamed"
+	updated, err := d.UpdatePalette(ctx, saved.ID, renamed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name != "renamed" || updated.UpdatedAt == nil || !updated.CreatedAt.Equal(saved.CreatedAt) || updated.RequestID != "req-2" {
+		t.Errorf("unexpected updated palette %+v", updated)
+	}
+	if _, err := d.UpdatePalette(ctx, primitive.NewObjectID(), renamed); errors.Cause(err) != db.ErrNotFound {
+		t.Errorf("expected ErrNotFound updating a missing palette, got %v", err)
+	}
+
+	if _, err := d.SavePalette(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	first, err := d.FindPalettes(ctx, db.PaletteQuery{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Palettes) != 1 || first.NextCursor == "" {
+		t.Fatalf("expected one palette and a cursor, got %+v", first)
+	}
+	second, err := d.FindPalettes(ctx, db.PaletteQuery{Limit: 1, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second.Palettes) != 1 || second.Palettes[0].ID != saved.ID || second.NextCursor != "" {
+		t.Errorf("expected the first palette saved on the last page, got %+v", second)
+	}
+	if err := (db.PaletteQuery{Cursor: "nope"}).Validate(); err == nil {
+		t.Error("expected a malformed cursor to be invalid")
+	}
+
+	if err := d.DeletePalette(ctx, saved.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.DeletePalette(ctx, saved.ID); errors.Cause(err) != db.ErrNotFound {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+}
+
+func TestDB_Runs(t *testing.T) {
+	d, done := newTestDB(t)
+	defer done()
+
+	ctx := context.Background()
+	at := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	if _, err := d.LastRun(ctx, "minutely"); errors.Cause(err) != db.ErrNotFound {
+		t.Errorf("expected ErrNotFound before any run, got %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		r := &db.RunDocument{Job: "minutely", ScheduledAt: at.Add(time.Duration(i) * time.Minute), StartedAt: at, Outcome: db.RunRunning}
+		if err := d.SaveRun(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+		r.Outcome, r.Saved, r.EndedAt = db.RunSucceeded, i, at.Add(time.Second)
+		if err := d.SaveRun(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := d.SaveRun(ctx, &db.RunDocument{Job: "hourly", ScheduledAt: at.Add(time.Hour), Outcome: db.RunSkipped}); err != nil {
+		t.Fatal(err)
+	}
+
+	last, err := d.LastRun(ctx, "minutely")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !last.ScheduledAt.Equal(at.Add(2*time.Minute)) || last.Outcome != db.RunSucceeded || last.Saved != 2 {
+		t.Errorf("unexpected last run %+v", last)
+	}
+
+	runs, err := d.FindRuns(ctx, "minutely", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].ID != last.ID || runs[1].Saved != 1 {
+		t.Errorf("unexpected runs %+v", runs)
+	}
+}
+
+func TestDB_Leases(t *testing.T) {
+	d, done := newTestDB(t)
+	defer done()
+
+	ctx := context.Background()
+	ttl := time.Second
+	a, err := d.AcquireLease(ctx, "scheduler", "a", ttl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Holder != "a" || a.Token != 1 {
+		t.Errorf("unexpected first lease %+v", a)
+	}
+	if _, err := d.AcquireLease(ctx, "scheduler", "b", ttl); errors.Cause(err) != db.ErrLeaseHeld {
+		t.Errorf("expected ErrLeaseHeld while a holds it, got %v", err)
+	}
+
+	// Acquiring again and renewing keep the token.
+	again, err := d.AcquireLease(ctx, "scheduler", "a", ttl)
+	if err != nil || again.Token != 1 {
+		t.Errorf("expected a to keep token 1, got %+v, %v", again, err)
+	}
+	renewed, err := d.RenewLease(ctx, "scheduler", "a", 1, ttl)
+	if err != nil || renewed.Token != 1 || !renewed.ExpiresAt.After(a.ExpiresAt) {
+		t.Errorf("expected renewal to push expiry out, got %+v, %v", renewed, err)
+	}
+
+	// Once it expires b takes over with a higher token, and a can neither renew nor release it.
+	time.Sleep(ttl + 100*time.Millisecond)
+	b, err := d.AcquireLease(ctx, "scheduler", "b", ttl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Holder != "b" || b.Token != 2 {
+		t.Errorf("unexpected lease after takeover %+v", b)
+	}
+	if _, err := d.RenewLease(ctx, "scheduler", "a", 1, ttl); errors.Cause(err) != db.ErrLeaseLost {
+		t.Errorf("expected ErrLeaseLost renewing a lost lease, got %v", err)
+	}
+	if err := d.ReleaseLease(ctx, "scheduler", "a", 1); errors.Cause(err) != db.ErrLeaseLost {
+		t.Errorf("expected ErrLeaseLost releasing a lost lease, got %v", err)
+	}
+
+	// Releasing lets a take it straight back.
+	if err := d.ReleaseLease(ctx, "scheduler", "b", 2); err != nil {
+		t.Fatal(err)
+	}
+	if a, err = d.AcquireLease(ctx, "scheduler", "a", ttl); err != nil || a.Token != 3 {
+		t.Errorf("expected a to take the released lease with token 3, got %+v, %v", a, err)
+	}
+	if l, err := d.FindLease(ctx, "scheduler"); err != nil || l.Holder != "a" {
+		t.Errorf("expected FindLease to report a, got %+v, %v", l, err)
+	}
+}
+
+// The fencing tests need MONGO_TEST_URI to name a replica set, since fenced writes run in transactions.
+func TestDB_Fencing(t *testing.T) {
+	d, done := newTestDB(t)
+	defer done()
+
+	ctx := context.Background()
+	ttl := time.Second
+	a, err := d.AcquireLease(ctx, "scheduler", "a", ttl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aCtx := requestctx.WithFencingToken(ctx, "scheduler", a.Token)
+	if _, err := d.Save(aCtx, colour.MustParse("#FF0000"), db.ColourName{}); err != nil {
+		t.Fatalf("expected the leader's write to go through, got %v", err)
+	}
+
+	// a stalls, its lease expires and b takes over: a's token is now stale.
+	time.Sleep(ttl + 100*time.Millisecond)
+	b, err := d.AcquireLease(ctx, "scheduler", "b", ttl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := d.Save(aCtx, colour.MustParse("#00FF00"), db.ColourName{})
+	if errors.Cause(err) != db.ErrFenced {
+		t.Errorf("expected ErrFenced saving a colour with a stale token, got %v", err)
+	}
+	if saved != nil {
+		t.Errorf("expected nothing saved, got %+v", saved)
+	}
+	if err := d.SaveRun(aCtx, &db.RunDocument{Job: "hourly", Outcome: db.RunSucceeded}); errors.Cause(err) != db.ErrFenced {
+		t.Errorf("expected ErrFenced saving a run with a stale token, got %v", err)
+	}
+	if err := d.MarkSeen(aCtx, primitive.NewObjectID()); errors.Cause(err) != db.ErrFenced {
+		t.Errorf("expected ErrFenced marking a colour seen with a stale token, got %v", err)
+	}
+
+	bCtx := requestctx.WithFencingToken(ctx, "scheduler", b.Token)
+	if err := d.SaveRun(bCtx, &db.RunDocument{Job: "hourly", Outcome: db.RunSucceeded}); err != nil {
+		t.Errorf("expected the new leader's write to go through, got %v", err)
+	}
+	if _, err := d.Save(ctx, colour.MustParse("#0000FF"), db.ColourName{}); err != nil {
+		t.Errorf("expected writes outside an election to go through, got %v", err)
+	}
+	page, err := d.FindColours(ctx, db.ColourQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Colours) != 2 {
+		t.Errorf("expected the fenced colour not to be saved, got %d colours", len(page.Colours))
+	}
+}
+
+func TestDB_FencingRacesTakeover(t *testing.T) {
+	d, done := newTestDB(t)
+	defer done()
+
+	ctx := context.Background()
+	ttl := time.Second
+	a, err := d.AcquireLease(ctx, "scheduler", "a", ttl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a's lease expires without a noticing, so b can take it over at any moment.
+	time.Sleep(ttl + 100*time.Millisecond)
+	aCtx := requestctx.WithFencingToken(ctx, "scheduler", a.Token)
+
+	taken := make(chan *db.Lease, 1)
+	d.SetFenceHook(func() {
+		// b takes the lease over after a's token was checked and before a writes.
+		go func() {
+			b, err := d.AcquireLease(ctx, "scheduler", "b", ttl)
+			if err != nil {
+				t.Error(err)
+			}
+			taken <- b
+		}()
+		select {
+		case <-taken:
+			t.Error("expected the takeover to wait for the fenced write")
+		case <-time.After(300 * time.Millisecond):
+		}
+	})
+	if err := d.SaveRun(aCtx, &db.RunDocument{Job: "hourly", Outcome: db.RunSucceeded}); err != nil {
+		t.Fatalf("expected a's write, checked before the takeover, to go through, got %v", err)
+	}
+	d.SetFenceHook(nil)
+
+	select {
+	case b := <-taken:
+		if b == nil || b.Token <= a.Token {
+			t.Fatalf("expected b to take the lease over with a newer token, got %+v", b)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the takeover to go through once a's write was done")
+	}
+	if err := d.SaveRun(aCtx, &db.RunDocument{Job: "hourly", Outcome: db.RunSucceeded}); errors.Cause(err) != db.ErrFenced {
+		t.Errorf("expected ErrFenced writing after the takeover, got %v", err)
+	}
+}
+
+func TestDB_JobState(t *testing.T) {
+	d, done := newTestDB(t)
+	defer done()
+
+	ctx := context.Background()
+	if _, err := d.FindJobState(ctx, "hourly"); errors.Cause(err) != db.ErrNotFound {
+		t.Fatalf("expected ErrNotFound for a job never paused, got %v", err)
+	}
+
+	paused, err := d.SetJobPaused(ctx, "hourly", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !paused.Paused || paused.PausedAt.IsZero() {
+		t.Fatalf("expected the job paused, got %+v", paused)
+	}
+	again, err := d.SetJobPaused(ctx, "hourly", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !again.PausedAt.Equal(paused.PausedAt) {
+		t.Errorf("expected pausing again to keep paused_at %v, got %v", paused.PausedAt, again.PausedAt)
+	}
+
+	if _, err := d.SetJobPaused(ctx, "hourly", false); err != nil {
+		t.Fatal(err)
+	}
+	st, err := d.FindJobState(ctx, "hourly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Paused || !st.PausedAt.Equal(paused.PausedAt) {
+		t.Errorf("expected the job resumed, keeping when it was paused, got %+v", st)
+	}
+}
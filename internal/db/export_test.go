@@ -0,0 +1,6 @@
+package db
+
+// SetFenceHook runs hook inside every fenced write, between checking its token and writing.
+func (db *DB) SetFenceHook(hook func()) {
+	db.fenceHook = hook
+}
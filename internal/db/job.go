@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// JobState is the state of a scheduled job shared by every replica, so that it outlives the one that
+// changed it and whichever replica runs the schedule honours it.
+type JobState struct {
+	Job    string `bson:"_id" json:"job"`
+	Paused bool   `bson:"paused" json:"paused"`
+	// PausedAt is when the job was last paused; it is kept on resuming so that missed runs can be found.
+	PausedAt  time.Time `bson:"paused_at,omitempty" json:"paused_at,omitempty"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// SetJobPaused pauses or resumes job, returning its state. Pausing a paused job keeps when it was first
+// paused.
+func (db *DB) SetJobPaused(ctx context.Context, job string, paused bool) (*JobState, error) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	filter := bson.D{{Key: "_id", Value: job}}
+	set := bson.D{{Key: "paused", Value: paused}, {Key: "updated_at", Value: now}}
+	if paused {
+		// Only a running job gets a new paused_at. When the job is already paused the filter matches
+		// nothing and the upsert fails with a duplicate key error, which leaves it as it is.
+		filter = append(filter, bson.E{Key: "paused", Value: bson.D{{Key: "$ne", Value: true}}})
+		set = append(set, bson.E{Key: "paused_at", Value: now})
+	}
+
+	var st JobState
+	err := db.jobs.FindOneAndUpdate(ctx, filter, bson.D{{Key: "$set", Value: set}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&st)
+	if paused && isDuplicateKey(err) {
+		return db.FindJobState(ctx, job)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "problem saving job state")
+	}
+	return &st, nil
+}
+
+// FindJobState returns job's state, or ErrNotFound if it has never been paused.
+func (db *DB) FindJobState(ctx context.Context, job string) (*JobState, error) {
+	var st JobState
+	err := db.jobs.FindOne(ctx, bson.D{{Key: "_id", Value: job}}).Decode(&st)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.Wrapf(ErrNotFound, "state of %s", job)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding job state")
+	}
+	return &st, nil
+}
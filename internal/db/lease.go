@@ -0,0 +1,190 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"hexbot/internal/requestctx"
+)
+
+// ErrLeaseHeld is returned when another holder has a lease that hasn't expired.
+var ErrLeaseHeld = errors.New("lease held by another holder")
+
+// ErrLeaseLost is returned when renewing or releasing a lease that has since passed to another holder.
+var ErrLeaseLost = errors.New("lease lost")
+
+// ErrFenced is returned by writes made under a lease that has since passed to another holder, judged by
+// the fencing token their context carries; see requestctx.WithFencingToken.
+var ErrFenced = errors.New("fenced off by a newer lease holder")
+
+// Lease is a named, expiring claim held by one process at a time.
+type Lease struct {
+	Name   string `bson:"_id" json:"name"`
+	Holder string `bson:"holder" json:"holder"`
+	// Token goes up by one every time the lease changes hands, and never otherwise, so work stamped with
+	// a lower token than the current one was done by a holder that has since lost the lease.
+	Token      int64     `bson:"token" json:"token"`
+	AcquiredAt time.Time `bson:"acquired_at" json:"acquired_at"`
+	RenewedAt  time.Time `bson:"renewed_at" json:"renewed_at"`
+	ExpiresAt  time.Time `bson:"expires_at" json:"expires_at"`
+}
+
+// AcquireLease takes the lease name for holder for ttl when it is free, has expired or is already
+// holder's, returning ErrLeaseHeld otherwise. Taking it over from another holder bumps its token.
+// Expiry is judged by this process's clock, so holders' clocks should agree to well within ttl.
+func (db *DB) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (*Lease, error) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	// Still ours: renew without bumping the token.
+	if l, err := db.RenewLease(ctx, name, holder, -1, ttl); err == nil {
+		return l, nil
+	} else if errors.Cause(err) != ErrLeaseLost {
+		return nil, err
+	}
+
+	// Free or expired: take it over. When it exists and is live the filter matches nothing, so the
+	// upsert tries to insert a second document with the same _id and fails with a duplicate key error.
+	var l Lease
+	err := db.leases.FindOneAndUpdate(ctx,
+		bson.D{
+			{Key: "_id", Value: name},
+			{Key: "expires_at", Value: bson.D{{Key: "$lte", Value: now}}},
+		},
+		bson.D{
+			{Key: "$set", Value: bson.D{
+				{Key: "holder", Value: holder},
+				{Key: "acquired_at", Value: now},
+				{Key: "renewed_at", Value: now},
+				{Key: "expires_at", Value: now.Add(ttl)},
+			}},
+			{Key: "$inc", Value: bson.D{{Key: "token", Value: int64(1)}}},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&l)
+	if isDuplicateKey(err) {
+		return nil, errors.Wrap(ErrLeaseHeld, name)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "problem acquiring lease")
+	}
+	return &l, nil
+}
+
+// RenewLease extends holder's lease name by ttl from now. A token of -1 renews whatever token holder
+// has; otherwise the token must match, so a holder that lost the lease and took it back can't renew
+// with its old one. It returns ErrLeaseLost when holder no longer has the lease.
+func (db *DB) RenewLease(ctx context.Context, name, holder string, token int64, ttl time.Duration) (*Lease, error) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	filter := bson.D{
+		{Key: "_id", Value: name},
+		{Key: "holder", Value: holder},
+		{Key: "expires_at", Value: bson.D{{Key: "$gt", Value: now}}},
+	}
+	if token >= 0 {
+		filter = append(filter, bson.E{Key: "token", Value: token})
+	}
+
+	var l Lease
+	err := db.leases.FindOneAndUpdate(ctx, filter,
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "renewed_at", Value: now},
+			{Key: "expires_at", Value: now.Add(ttl)},
+		}}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&l)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.Wrap(ErrLeaseLost, name)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "problem renewing lease")
+	}
+	return &l, nil
+}
+
+// ReleaseLease expires holder's lease name at once, so another holder can take it without waiting out
+// its ttl. Releasing a lease holder no longer has returns ErrLeaseLost.
+func (db *DB) ReleaseLease(ctx context.Context, name, holder string, token int64) error {
+	res, err := db.leases.UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: name}, {Key: "holder", Value: holder}, {Key: "token", Value: token}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "expires_at", Value: time.Now().UTC().Truncate(time.Millisecond)}}}},
+	)
+	if err != nil {
+		return errors.Wrap(err, "problem releasing lease")
+	}
+	if res.MatchedCount == 0 {
+		return errors.Wrap(ErrLeaseLost, name)
+	}
+	return nil
+}
+
+// FindLease returns the lease name whoever holds it, or ErrNotFound if it has never been taken.
+func (db *DB) FindLease(ctx context.Context, name string) (*Lease, error) {
+	var l Lease
+	err := db.leases.FindOne(ctx, bson.D{{Key: "_id", Value: name}}).Decode(&l)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.Wrapf(ErrNotFound, "lease %s", name)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding lease")
+	}
+	return &l, nil
+}
+
+// fenced runs write, when ctx carries a fencing token, in a transaction that first bumps a counter on
+// the lease, filtered on the lease still having that token. A takeover either lands first, so that the
+// filter matches nothing and the write is refused with ErrFenced, or waits for the transaction to end,
+// so no write lands once the lease has changed hands. Transactions need a replica set, as leader
+// election does. Work done outside an election carries no token and is never fenced.
+func (db *DB) fenced(ctx context.Context, write func(ctx context.Context) error) error {
+	token := requestctx.FencingToken(ctx)
+	if token == 0 {
+		return write(ctx)
+	}
+	name := requestctx.FencingLease(ctx)
+
+	// Ending the session aborts the transaction when it hasn't been committed.
+	return db.client.UseSession(ctx, func(sc mongo.SessionContext) error {
+		if err := sc.StartTransaction(); err != nil {
+			return errors.Wrap(err, "problem starting fenced write")
+		}
+		res, err := db.leases.UpdateOne(sc,
+			bson.D{{Key: "_id", Value: name}, {Key: "token", Value: bson.D{{Key: "$lte", Value: token}}}},
+			bson.D{{Key: "$inc", Value: bson.D{{Key: "fenced_writes", Value: int64(1)}}}},
+		)
+		if err != nil {
+			return errors.Wrap(err, "problem checking fencing token")
+		}
+		if res.MatchedCount == 0 {
+			return errors.Wrapf(ErrFenced, "lease %s has moved on from token %d", name, token)
+		}
+		if db.fenceHook != nil {
+			db.fenceHook()
+		}
+		if err := write(sc); err != nil {
+			return err
+		}
+		return errors.Wrap(sc.CommitTransaction(sc), "problem committing fenced write")
+	})
+}
+
+// isDuplicateKey reports whether err is Mongo's E11000 duplicate key error, which findAndModify returns
+// as a command error and inserts as a write error.
+func isDuplicateKey(err error) bool {
+	switch e := err.(type) {
+	case mongo.CommandError:
+		return e.Code == 11000
+	case mongo.WriteException:
+		for _, we := range e.WriteErrors {
+			if we.Code == 11000 {
+				return true
+			}
+		}
+	}
+	return err != nil && strings.Contains(err.Error(), "E11000")
+}
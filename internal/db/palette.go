@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"hexbot/internal/palette"
+	"hexbot/internal/requestctx"
+)
+
+// ErrNotFound is returned when no document has the id asked for.
+var ErrNotFound = errors.New("not found")
+
+// PaletteDocument is how a palette is stored in Mongo.
+type PaletteDocument struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	palette.Palette `bson:",inline"`
+	CreatedAt       time.Time `bson:"created_at" json:"created_at"`
+	// UpdatedAt is when the palette was last replaced, nil if it never has been.
+	UpdatedAt *time.Time `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+	Source    string     `bson:"source" json:"source"`
+	RequestID string     `bson:"request_id,omitempty" json:"request_id,omitempty"`
+}
+
+// SavePalette stores p along with the source and request id carried by ctx, returning the stored document.
+func (db *DB) SavePalette(ctx context.Context, p palette.Palette) (*PaletteDocument, error) {
+	doc := &PaletteDocument{
+		ID:        primitive.NewObjectID(),
+		Palette:   p,
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+		RequestID: requestctx.RequestID(ctx),
+	}
+	if doc.Source = requestctx.Source(ctx); doc.Source == "" {
+		doc.Source = db.source
+	}
+
+	if _, err := db.palettes.InsertOne(ctx, doc); err != nil {
+		return nil, errors.Wrap(err, "problem inserting palette")
+	}
+	return doc, nil
+}
+
+// FindPalette returns the palette with id, or ErrNotFound.
+func (db *DB) FindPalette(ctx context.Context, id primitive.ObjectID) (*PaletteDocument, error) {
+	var doc PaletteDocument
+	err := db.palettes.FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.Wrapf(ErrNotFound, "palette %s", id.Hex())
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding palette")
+	}
+	return &doc, nil
+}
+
+// PaletteQuery filters and pages through stored palettes, newest first. Zero fields do not filter.
+type PaletteQuery struct {
+	Source  string
+	Harmony palette.Harmony
+	// Limit is the page size, DefaultLimit when zero.
+	Limit int
+	// Cursor is the NextCursor of the previous page, empty for the first page.
+	Cursor string
+}
+
+// PalettePage is one page of a PaletteQuery.
+type PalettePage struct {
+	Palettes []PaletteDocument
+	// NextCursor fetches the following page; it is empty on the last page.
+	NextCursor string
+}
+
+// Validate reports whether q can be run, so that callers can tell bad input from a failing database.
+func (q PaletteQuery) Validate() error {
+	_, err := q.filter()
+	return err
+}
+
+func (q PaletteQuery) filter() (bson.D, error) {
+	filter := bson.D{}
+	if q.Source != "" {
+		filter = append(filter, bson.E{Key: "source", Value: q.Source})
+	}
+	if q.Harmony != "" {
+		filter = append(filter, bson.E{Key: "harmony", Value: q.Harmony})
+	}
+	if q.Cursor != "" {
+		after, err := primitive.ObjectIDFromHex(q.Cursor)
+		if err != nil {
+			return nil, errors.New("malformed cursor")
+		}
+		filter = append(filter, bson.E{Key: "_id", Value: bson.D{{Key: "$lt", Value: after}}})
+	}
+	return filter, nil
+}
+
+// FindPalettes returns the page of palettes matching q.
+func (db *DB) FindPalettes(ctx context.Context, q PaletteQuery) (*PalettePage, error) {
+	filter, err := q.filter()
+	if err != nil {
+		return nil, err
+	}
+	limit := ColourQuery{Limit: q.Limit}.limit()
+
+	cur, err := db.palettes.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(int64(limit+1)))
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding palettes")
+	}
+	defer cur.Close(ctx)
+
+	page := &PalettePage{Palettes: []PaletteDocument{}}
+	for cur.Next(ctx) {
+		var doc PaletteDocument
+		if err := cur.Decode(&doc); err != nil {
+			return nil, errors.Wrap(err, "problem decoding palette")
+		}
+		page.Palettes = append(page.Palettes, doc)
+	}
+	if err := cur.Err(); err != nil {
+		return nil, errors.Wrap(err, "problem iterating palettes")
+	}
+
+	// One extra document is requested to know whether there is a next page.
+	if len(page.Palettes) > limit {
+		page.Palettes = page.Palettes[:limit]
+		page.NextCursor = page.Palettes[limit-1].ID.Hex()
+	}
+	return page, nil
+}
+
+// UpdatePalette replaces the colours, names and attribution of the palette with id, keeping when and by
+// which request it was created. It returns the updated document, or ErrNotFound.
+func (db *DB) UpdatePalette(ctx context.Context, id primitive.ObjectID, p palette.Palette) (*PaletteDocument, error) {
+	doc, err := db.FindPalette(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	doc.Palette, doc.UpdatedAt = p, &now
+
+	res, err := db.palettes.ReplaceOne(ctx, bson.D{{Key: "_id", Value: id}}, doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem replacing palette")
+	}
+	if res.MatchedCount == 0 {
+		return nil, errors.Wrapf(ErrNotFound, "palette %s", id.Hex())
+	}
+	return doc, nil
+}
+
+// DeletePalette removes the palette with id, or returns ErrNotFound.
+func (db *DB) DeletePalette(ctx context.Context, id primitive.ObjectID) error {
+	res, err := db.palettes.DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		return errors.Wrap(err, "problem deleting palette")
+	}
+	if res.DeletedCount == 0 {
+		return errors.Wrapf(ErrNotFound, "palette %s", id.Hex())
+	}
+	return nil
+}
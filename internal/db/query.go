@@ -0,0 +1,311 @@
+package db
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"hexbot/internal/colour"
+)
+
+// Fields colours can be sorted by.
+const (
+	SortFetchedAt  = "fetched_at"
+	SortHue        = "h"
+	SortSaturation = "s"
+	SortLightness  = "l"
+)
+
+const (
+	// exportBatchSize is how many colours EachColour asks Mongo for at a time.
+	exportBatchSize = 500
+
+	// DefaultLimit is the page size used when a query does not set one.
+	DefaultLimit = 50
+	// MaxLimit is the largest page a single query may return.
+	MaxLimit = 1000
+)
+
+// Range is an inclusive range of values.
+type Range struct {
+	Min float64
+	Max float64
+}
+
+// ColourQuery filters, sorts and pages through saved colours. Zero fields do not filter.
+type ColourQuery struct {
+	// From and To bound fetched_at; From is inclusive, To exclusive.
+	From time.Time
+	To   time.Time
+
+	Colour *colour.Colour
+	Source string
+	// Name matches the stored nearest colour name, case insensitively.
+	Name string
+
+	R, G, B *Range
+	// Hue is in degrees. A Min greater than Max wraps through 360, so 330 to 30 selects reds.
+	Hue        *Range
+	Saturation *Range
+	Lightness  *Range
+
+	// SortBy is one of the Sort constants, fetched_at by default. Ties are broken by insertion order.
+	SortBy    string
+	Ascending bool
+
+	// Limit is the page size, DefaultLimit when zero.
+	Limit int
+	// Cursor is the NextCursor of the previous page, empty for the first page.
+	Cursor string
+}
+
+// ColourPage is one page of a ColourQuery.
+type ColourPage struct {
+	Colours []ColourDocument
+	// NextCursor fetches the following page; it is empty on the last page.
+	NextCursor string
+}
+
+// cursor is the position after the last colour of a page, opaque to callers.
+type cursor struct {
+	SortBy    string    `json:"s"`
+	Number    float64   `json:"n,omitempty"`
+	Time      time.Time `json:"t,omitempty"`
+	ID        string    `json:"id"`
+	Ascending bool      `json:"a,omitempty"`
+}
+
+// FindColours returns the page of colours matching q.
+func (db *DB) FindColours(ctx context.Context, q ColourQuery) (*ColourPage, error) {
+	filter, opts, err := q.find()
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := db.colours.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding colours")
+	}
+	defer cur.Close(ctx)
+
+	page := &ColourPage{Colours: []ColourDocument{}}
+	for cur.Next(ctx) {
+		var doc ColourDocument
+		if err := cur.Decode(&doc); err != nil {
+			return nil, errors.Wrap(err, "problem decoding colour")
+		}
+		page.Colours = append(page.Colours, doc)
+	}
+	if err := cur.Err(); err != nil {
+		return nil, errors.Wrap(err, "problem iterating colours")
+	}
+
+	// One extra document is requested to know whether there is a next page.
+	if limit := q.limit(); len(page.Colours) > limit {
+		page.Colours = page.Colours[:limit]
+		page.NextCursor = q.encodeCursor(page.Colours[limit-1])
+	}
+	return page, nil
+}
+
+// Validate reports whether q can be run, so that callers can tell bad input from a failing database.
+func (q ColourQuery) Validate() error {
+	_, _, err := q.find()
+	return err
+}
+
+// EachColour calls fn with every colour matching q, in q's order from q.Cursor on, decoding them from the
+// Mongo cursor one at a time so that exports of any size run in constant memory. Unlike FindColours, a
+// zero Limit means no limit and MaxLimit does not apply. It stops at the first error from fn.
+func (db *DB) EachColour(ctx context.Context, q ColourQuery, fn func(ColourDocument) error) error {
+	filter, opts, err := q.find()
+	if err != nil {
+		return err
+	}
+	opts.SetLimit(int64(q.Limit)).SetBatchSize(exportBatchSize)
+
+	cur, err := db.colours.Find(ctx, filter, opts)
+	if err != nil {
+		return errors.Wrap(err, "problem finding colours")
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var doc ColourDocument
+		if err := cur.Decode(&doc); err != nil {
+			return errors.Wrap(err, "problem decoding colour")
+		}
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+	return errors.Wrap(cur.Err(), "problem iterating colours")
+}
+
+// FindColoursSince returns up to limit colours saved after the one with id, oldest first, so that a
+// subscriber to the live stream can catch up on what it missed. Object ids order colours exactly when they
+// were saved by one process and to the second otherwise.
+func (db *DB) FindColoursSince(ctx context.Context, id primitive.ObjectID, limit int) ([]ColourDocument, error) {
+	limit = ColourQuery{Limit: limit}.limit()
+	cur, err := db.colours.Find(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$gt", Value: id}}}}, options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit)))
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding colours since last event")
+	}
+	defer cur.Close(ctx)
+
+	docs := []ColourDocument{}
+	for cur.Next(ctx) {
+		var doc ColourDocument
+		if err := cur.Decode(&doc); err != nil {
+			return nil, errors.Wrap(err, "problem decoding colour")
+		}
+		docs = append(docs, doc)
+	}
+	return docs, errors.Wrap(cur.Err(), "problem iterating colours")
+}
+
+func (q ColourQuery) limit() int {
+	switch {
+	case q.Limit <= 0:
+		return DefaultLimit
+	case q.Limit > MaxLimit:
+		return MaxLimit
+	}
+	return q.Limit
+}
+
+func (q ColourQuery) sortBy() (string, error) {
+	switch q.SortBy {
+	case "":
+		return SortFetchedAt, nil
+	case SortFetchedAt, SortHue, SortSaturation, SortLightness:
+		return q.SortBy, nil
+	}
+	return "", errors.Errorf("cannot sort colours by %q", q.SortBy)
+}
+
+// find builds the Mongo filter and find options for q.
+func (q ColourQuery) find() (bson.D, *options.FindOptions, error) {
+	sortBy, err := q.sortBy()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filter := bson.D{}
+	if !q.From.IsZero() || !q.To.IsZero() {
+		window := bson.D{}
+		if !q.From.IsZero() {
+			window = append(window, bson.E{Key: "$gte", Value: q.From})
+		}
+		if !q.To.IsZero() {
+			window = append(window, bson.E{Key: "$lt", Value: q.To})
+		}
+		filter = append(filter, bson.E{Key: "fetched_at", Value: window})
+	}
+	if q.Colour != nil {
+		filter = append(filter, bson.E{Key: "hex", Value: q.Colour.Hex()})
+	}
+	if q.Source != "" {
+		filter = append(filter, bson.E{Key: "source", Value: q.Source})
+	}
+	if q.Name != "" {
+		filter = append(filter, bson.E{Key: "name", Value: strings.Join(strings.Fields(strings.ToLower(q.Name)), " ")})
+	}
+	for _, r := range []struct {
+		field string
+		rng   *Range
+	}{{"r", q.R}, {"g", q.G}, {"b", q.B}, {"s", q.Saturation}, {"l", q.Lightness}} {
+		if r.rng != nil {
+			filter = append(filter, bson.E{Key: r.field, Value: bson.D{{Key: "$gte", Value: r.rng.Min}, {Key: "$lte", Value: r.rng.Max}}})
+		}
+	}
+
+	var and bson.A
+	if q.Hue != nil {
+		if q.Hue.Min <= q.Hue.Max {
+			filter = append(filter, bson.E{Key: "h", Value: bson.D{{Key: "$gte", Value: q.Hue.Min}, {Key: "$lte", Value: q.Hue.Max}}})
+		} else {
+			and = append(and, bson.D{{Key: "$or", Value: bson.A{
+				bson.D{{Key: "h", Value: bson.D{{Key: "$gte", Value: q.Hue.Min}}}},
+				bson.D{{Key: "h", Value: bson.D{{Key: "$lte", Value: q.Hue.Max}}}},
+			}}})
+		}
+	}
+
+	if q.Cursor != "" {
+		after, err := q.decodeCursor(sortBy)
+		if err != nil {
+			return nil, nil, err
+		}
+		and = append(and, after)
+	}
+	if len(and) > 0 {
+		filter = append(filter, bson.E{Key: "$and", Value: and})
+	}
+
+	dir := -1
+	if q.Ascending {
+		dir = 1
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: sortBy, Value: dir}, {Key: "_id", Value: dir}}).
+		SetLimit(int64(q.limit() + 1))
+	return filter, opts, nil
+}
+
+func (q ColourQuery) encodeCursor(last ColourDocument) string {
+	c := cursor{SortBy: q.SortBy, ID: last.ID.Hex(), Ascending: q.Ascending}
+	switch q.SortBy {
+	case SortHue:
+		c.Number = last.Hue
+	case SortSaturation:
+		c.Number = last.Saturation
+	case SortLightness:
+		c.Number = last.Lightness
+	default:
+		c.Time = last.FetchedAt
+	}
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor turns q.Cursor into a filter matching everything after it in sort order.
+func (q ColourQuery) decodeCursor(sortBy string) (bson.D, error) {
+	b, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+	if c.SortBy != q.SortBy || c.Ascending != q.Ascending {
+		return nil, errors.New("cursor was issued for a different sort order")
+	}
+	id, err := primitive.ObjectIDFromHex(c.ID)
+	if err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+
+	var value interface{} = c.Number
+	if sortBy == SortFetchedAt {
+		value = c.Time
+	}
+	op := "$lt"
+	if q.Ascending {
+		op = "$gt"
+	}
+	return bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: sortBy, Value: bson.D{{Key: op, Value: value}}}},
+		bson.D{{Key: sortBy, Value: value}, {Key: "_id", Value: bson.D{{Key: op, Value: id}}}},
+	}}}, nil
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Outcome is how a scheduled run ended.
+type Outcome string
+
+const (
+	// RunRunning marks a run that has started and not yet ended.
+	RunRunning Outcome = "running"
+	// RunSucceeded marks a run that fetched and saved its batch.
+	RunSucceeded Outcome = "succeeded"
+	// RunFailed marks a run that ended with an error.
+	RunFailed Outcome = "failed"
+	// RunSkipped marks a run that never started because the job's previous run was still going.
+	RunSkipped Outcome = "skipped"
+)
+
+// MaxRuns is the most runs FindRuns returns.
+const MaxRuns = 1000
+
+// RunDocument records one run of a scheduled job.
+type RunDocument struct {
+	ID  primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Job string             `bson:"job" json:"job"`
+	// ScheduledAt is when the schedule called for the run; StartedAt trails it by any jitter, and by a
+	// lot for runs catching up.
+	ScheduledAt time.Time `bson:"scheduled_at" json:"scheduled_at"`
+	StartedAt   time.Time `bson:"started_at" json:"started_at"`
+	EndedAt     time.Time `bson:"ended_at,omitempty" json:"ended_at,omitempty"`
+	Outcome     Outcome   `bson:"outcome" json:"outcome"`
+	Saved       int       `bson:"saved" json:"saved"`
+	Rejected    int       `bson:"rejected" json:"rejected"`
+	Merged      int       `bson:"merged" json:"merged"`
+	Error       string    `bson:"error,omitempty" json:"error,omitempty"`
+	RequestID   string    `bson:"request_id,omitempty" json:"request_id,omitempty"`
+	// FencingToken is the token of the leader election lease the run was made under, if any.
+	FencingToken int64 `bson:"fencing_token,omitempty" json:"fencing_token,omitempty"`
+}
+
+// SaveRun inserts r, giving it an id, or replaces the stored run with r's id. It returns ErrFenced when
+// ctx carries a stale fencing token.
+func (db *DB) SaveRun(ctx context.Context, r *RunDocument) error {
+	if r.ID.IsZero() {
+		r.ID = primitive.NewObjectID()
+	}
+	return db.fenced(ctx, func(ctx context.Context) error {
+		_, err := db.runs.ReplaceOne(ctx, bson.D{{Key: "_id", Value: r.ID}}, r, options.Replace().SetUpsert(true))
+		return errors.Wrap(err, "problem saving run")
+	})
+}
+
+// LastRun returns the most recently scheduled run of job, or ErrNotFound if it has never run.
+func (db *DB) LastRun(ctx context.Context, job string) (*RunDocument, error) {
+	var r RunDocument
+	err := db.runs.FindOne(ctx, bson.D{{Key: "job", Value: job}},
+		options.FindOne().SetSort(bson.D{{Key: "scheduled_at", Value: -1}, {Key: "_id", Value: -1}})).Decode(&r)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.Wrapf(ErrNotFound, "runs of %s", job)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding last run")
+	}
+	return &r, nil
+}
+
+// FindRuns returns up to limit runs of job, most recently scheduled first. limit is capped at MaxRuns.
+func (db *DB) FindRuns(ctx context.Context, job string, limit int) ([]RunDocument, error) {
+	if limit <= 0 || limit > MaxRuns {
+		limit = MaxRuns
+	}
+	cur, err := db.runs.Find(ctx, bson.D{{Key: "job", Value: job}}, options.Find().
+		SetSort(bson.D{{Key: "scheduled_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit)))
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding runs")
+	}
+	defer cur.Close(ctx)
+
+	runs := []RunDocument{}
+	for cur.Next(ctx) {
+		var r RunDocument
+		if err := cur.Decode(&r); err != nil {
+			return nil, errors.Wrap(err, "problem decoding run")
+		}
+		runs = append(runs, r)
+	}
+	return runs, errors.Wrap(cur.Err(), "problem iterating runs")
+}
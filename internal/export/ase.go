@@ -0,0 +1,59 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"unicode/utf16"
+)
+
+// ASE block types and colour types.
+const (
+	aseGroupStart  = 0xC001
+	aseGroupEnd    = 0xC002
+	aseColourEntry = 0x0001
+	aseGlobal      = 0
+)
+
+// writeASE writes an Adobe Swatch Exchange file: a big endian header followed by one group block,
+// named after the set, holding an RGB block for every swatch.
+func writeASE(w io.Writer, s Set) error {
+	var buf bytes.Buffer
+	buf.WriteString("ASEF")
+	binary.Write(&buf, binary.BigEndian, uint16(1))
+	binary.Write(&buf, binary.BigEndian, uint16(0))
+	binary.Write(&buf, binary.BigEndian, uint32(len(s.Swatches)+2))
+
+	writeASEBlock(&buf, aseGroupStart, aseName(s.Name))
+	for _, sw := range s.Swatches {
+		var block bytes.Buffer
+		block.Write(aseName(sw.label()))
+		block.WriteString("RGB ")
+		for _, v := range []uint8{sw.Colour.R(), sw.Colour.G(), sw.Colour.B()} {
+			binary.Write(&block, binary.BigEndian, math.Float32bits(float32(v)/255))
+		}
+		binary.Write(&block, binary.BigEndian, uint16(aseGlobal))
+		writeASEBlock(&buf, aseColourEntry, block.Bytes())
+	}
+	writeASEBlock(&buf, aseGroupEnd, nil)
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func writeASEBlock(buf *bytes.Buffer, kind uint16, body []byte) {
+	binary.Write(buf, binary.BigEndian, kind)
+	binary.Write(buf, binary.BigEndian, uint32(len(body)))
+	buf.Write(body)
+}
+
+// aseName is a length prefixed, null terminated UTF-16 string. The length counts code units,
+// including the terminator.
+func aseName(s string) []byte {
+	units := append(utf16.Encode([]rune(s)), 0)
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, uint16(len(units)))
+	binary.Write(&buf, binary.BigEndian, units)
+	return buf.Bytes()
+}
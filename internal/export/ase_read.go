@@ -0,0 +1,207 @@
+package export
+
+import (
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+)
+
+// aseReader reads big endian values from an ASE file, remembering the offset for errors.
+type aseReader struct {
+	b      []byte
+	offset int
+}
+
+func (r *aseReader) fail(format string, args ...interface{}) error {
+	return &ParseError{Format: ASE, Offset: int64(r.offset), Err: errors.Errorf(format, args...)}
+}
+
+func (r *aseReader) bytes(n int, what string) ([]byte, error) {
+	if n < 0 || r.offset+n > len(r.b) {
+		return nil, r.fail("file ends in the middle of %s", what)
+	}
+	b := r.b[r.offset : r.offset+n]
+	r.offset += n
+	return b, nil
+}
+
+func (r *aseReader) uint16(what string) (uint16, error) {
+	b, err := r.bytes(2, what)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(b), nil
+}
+
+func (r *aseReader) uint32(what string) (uint32, error) {
+	b, err := r.bytes(4, what)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(b), nil
+}
+
+func (r *aseReader) name() (string, error) {
+	n, err := r.uint16("a name length")
+	if err != nil {
+		return "", err
+	}
+	b, err := r.bytes(int(n)*2, "a name")
+	if err != nil {
+		return "", err
+	}
+	units := make([]uint16, n)
+	for i := range units {
+		units[i] = binary.BigEndian.Uint16(b[i*2:])
+	}
+	if n > 0 && units[n-1] == 0 {
+		units = units[:n-1]
+	}
+	return string(utf16.Decode(units)), nil
+}
+
+func (r *aseReader) floats(n int) ([]float64, error) {
+	vs := make([]float64, n)
+	for i := range vs {
+		bits, err := r.uint32("a colour value")
+		if err != nil {
+			return nil, err
+		}
+		vs[i] = float64(math.Float32frombits(bits))
+	}
+	return vs, nil
+}
+
+// readASE reads every colour entry of an Adobe Swatch Exchange file, in RGB, Gray, CMYK or LAB.
+// The first group names the set; groups are otherwise flattened.
+func readASE(in io.Reader) (*Set, error) {
+	b, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem reading ase")
+	}
+	r := &aseReader{b: b}
+
+	sig, err := r.bytes(4, "the signature")
+	if err != nil {
+		return nil, err
+	}
+	if string(sig) != "ASEF" {
+		r.offset = 0
+		return nil, r.fail("expected the signature ASEF, got %q", sig)
+	}
+	major, err := r.uint16("the version")
+	if err != nil {
+		return nil, err
+	}
+	if major != 1 {
+		return nil, r.fail("unsupported version %d", major)
+	}
+	if _, err := r.uint16("the version"); err != nil {
+		return nil, err
+	}
+	blocks, err := r.uint32("the block count")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Set{}
+	for i := uint32(0); i < blocks; i++ {
+		start := r.offset
+		kind, err := r.uint16("a block type")
+		if err != nil {
+			return nil, err
+		}
+		length, err := r.uint32("a block length")
+		if err != nil {
+			return nil, err
+		}
+		end := r.offset + int(length)
+		if end > len(r.b) {
+			return nil, r.fail("block %d claims %d bytes but only %d are left", i+1, length, len(r.b)-r.offset)
+		}
+
+		switch kind {
+		case aseGroupStart:
+			name, err := r.name()
+			if err != nil {
+				return nil, err
+			}
+			if s.Name == "" {
+				s.Name = name
+			}
+		case aseGroupEnd:
+		case aseColourEntry:
+			sw, err := r.colour()
+			if err != nil {
+				return nil, err
+			}
+			s.Swatches = append(s.Swatches, sw)
+		default:
+			r.offset = start
+			return nil, r.fail("unknown block type %#04x", kind)
+		}
+		if r.offset > end {
+			return nil, r.fail("block %d is longer than its declared %d bytes", i+1, length)
+		}
+		r.offset = end
+	}
+	return s, nil
+}
+
+func (r *aseReader) colour() (Swatch, error) {
+	name, err := r.name()
+	if err != nil {
+		return Swatch{}, err
+	}
+	modelAt := r.offset
+	model, err := r.bytes(4, "a colour model")
+	if err != nil {
+		return Swatch{}, err
+	}
+
+	var c colour.Colour
+	switch string(model) {
+	case "RGB ":
+		vs, err := r.floats(3)
+		if err != nil {
+			return Swatch{}, err
+		}
+		c = colour.RGB(unitChannel(vs[0]), unitChannel(vs[1]), unitChannel(vs[2]))
+	case "Gray":
+		vs, err := r.floats(1)
+		if err != nil {
+			return Swatch{}, err
+		}
+		g := unitChannel(vs[0])
+		c = colour.RGB(g, g, g)
+	case "CMYK":
+		vs, err := r.floats(4)
+		if err != nil {
+			return Swatch{}, err
+		}
+		c = colour.CMYK{C: vs[0], M: vs[1], Y: vs[2], K: vs[3]}.Colour()
+	case "LAB ":
+		vs, err := r.floats(3)
+		if err != nil {
+			return Swatch{}, err
+		}
+		c = colour.Lab{L: vs[0] * 100, A: vs[1], B: vs[2]}.Colour()
+	default:
+		r.offset = modelAt
+		return Swatch{}, r.fail("unknown colour model %q", model)
+	}
+	if _, err := r.uint16("a colour type"); err != nil {
+		return Swatch{}, err
+	}
+	return Swatch{Name: name, Colour: c}, nil
+}
+
+func unitChannel(v float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(1, v)) * 255))
+}
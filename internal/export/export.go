@@ -0,0 +1,150 @@
+// Package export reads and writes sets of colours in the palette formats design tools use.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/palette"
+)
+
+// Format is a palette file format.
+type Format string
+
+const (
+	// GPL is a GIMP palette, also read by Inkscape and Krita.
+	GPL Format = "gpl"
+	// ASE is Adobe Swatch Exchange, read by Photoshop, Illustrator and InDesign.
+	ASE Format = "ase"
+	// PaintNET is a paint.net palette, which reads only the first 96 colours.
+	PaintNET Format = "paintnet"
+	// CSS is custom properties on :root.
+	CSS Format = "css"
+	// SCSS is Sass variables.
+	SCSS Format = "scss"
+	// SVG is a sheet of labelled swatches.
+	SVG Format = "svg"
+	// JSON is the set as plain JSON.
+	JSON Format = "json"
+	// CSV is a name,hex table with a header row.
+	CSV Format = "csv"
+)
+
+// Formats lists every format Write understands.
+var Formats = []Format{GPL, ASE, PaintNET, CSS, SCSS, SVG, JSON, CSV}
+
+var formatInfo = map[Format]struct {
+	ext, contentType string
+}{
+	GPL:      {".gpl", "text/plain; charset=utf-8"},
+	ASE:      {".ase", "application/octet-stream"},
+	PaintNET: {".txt", "text/plain; charset=utf-8"},
+	CSS:      {".css", "text/css; charset=utf-8"},
+	SCSS:     {".scss", "text/x-scss; charset=utf-8"},
+	SVG:      {".svg", "image/svg+xml"},
+	JSON:     {".json", "application/json"},
+	CSV:      {".csv", "text/csv; charset=utf-8"},
+}
+
+// ParseFormat validates a format name.
+func ParseFormat(s string) (Format, error) {
+	if _, ok := formatInfo[Format(s)]; !ok {
+		return "", errors.Errorf("unknown export format %q, expected one of %v", s, Formats)
+	}
+	return Format(s), nil
+}
+
+// Extension is the usual file extension for f, with its dot.
+func (f Format) Extension() string {
+	return formatInfo[f].ext
+}
+
+// ContentType is the MIME type of f.
+func (f Format) ContentType() string {
+	return formatInfo[f].contentType
+}
+
+// Swatch is a named colour.
+type Swatch struct {
+	Name   string        `json:"name,omitempty"`
+	Colour colour.Colour `json:"hex"`
+}
+
+// Set is what gets exported: a named list of swatches and, optionally, how they fare with colour
+// vision deficiencies.
+type Set struct {
+	Name     string              `json:"name"`
+	Swatches []Swatch            `json:"colours"`
+	CVD      []palette.CVDReport `json:"cvd,omitempty"`
+}
+
+// Write writes s to w in format f.
+func Write(w io.Writer, f Format, s Set) error {
+	var err error
+	switch f {
+	case GPL:
+		err = writeGPL(w, s)
+	case ASE:
+		err = writeASE(w, s)
+	case PaintNET:
+		err = writePaintNET(w, s)
+	case CSS:
+		err = writeVariables(w, s, ":root {\n", "  --%s: %s;\n", "}\n")
+	case SCSS:
+		err = writeVariables(w, s, "", "$%s: %s;\n", "")
+	case SVG:
+		err = writeSVG(w, s)
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(s)
+	case CSV:
+		err = writeCSV(w, s)
+	default:
+		return errors.Errorf("unknown export format %q", f)
+	}
+	return errors.Wrapf(err, "problem writing %s", f)
+}
+
+// label is a swatch's name, or its hex when it has none.
+func (sw Swatch) label() string {
+	if sw.Name != "" {
+		return sw.Name
+	}
+	return sw.Colour.Hex()
+}
+
+// identifiers turns swatch names into unique CSS and Sass identifiers.
+func identifiers(swatches []Swatch) []string {
+	ids := make([]string, len(swatches))
+	seen := map[string]int{}
+	for i, sw := range swatches {
+		id := slug(sw.Name)
+		if id == "" {
+			id = fmt.Sprintf("colour-%d", i+1)
+		}
+		if seen[id]++; seen[id] > 1 {
+			id = fmt.Sprintf("%s-%d", id, seen[id])
+		}
+		ids[i] = id
+	}
+	return ids
+}
+
+// slug lowercases s and joins its words with hyphens, dropping anything but letters and digits.
+func slug(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	id := strings.Join(words, "-")
+	if id != "" && unicode.IsDigit(rune(id[0])) {
+		id = "c" + id
+	}
+	return id
+}
@@ -0,0 +1,173 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/export"
+	"hexbot/internal/palette"
+)
+
+var set = export.Set{
+	Name: "Hexbot history",
+	Swatches: []export.Swatch{
+		{Name: "red", Colour: colour.MustParse("#D62728")},
+		{Name: "Red", Colour: colour.MustParse("#FF0000")},
+		{Colour: colour.MustParse("#2CA02C80")},
+	},
+}
+
+func TestWrite_Text(t *testing.T) {
+	tests := []struct {
+		Format export.Format
+		Want   string
+	}{
+		{export.GPL, "GIMP Palette\nName: Hexbot history\nColumns: 8\n#\n214  39  40\tred\n255   0   0\tRed\n 44 160  44\t#2CA02C80\n"},
+		{export.PaintNET, "; paint.net Palette File\n; Hexbot history\n; Colors are written as 8-digit hexadecimal numbers: aarrggbb\nFFD62728\nFFFF0000\n802CA02C\n"},
+		{export.CSS, "/* Hexbot history */\n:root {\n  --red: #d62728;\n  --red-2: #ff0000;\n  --colour-3: #2ca02c80;\n}\n"},
+		{export.SCSS, "/* Hexbot history */\n$red: #d62728;\n$red-2: #ff0000;\n$colour-3: #2ca02c80;\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.Format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := export.Write(&buf, tt.Format, set); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.Want {
+				t.Errorf("got\n%s\nwant\n%s", buf.String(), tt.Want)
+			}
+		})
+	}
+}
+
+func TestWrite_CommentsAreClosedOnce(t *testing.T) {
+	s := export.Set{
+		Name: "*/ body { display: none } /*",
+		Swatches: []export.Swatch{
+			{Name: "black */ a { color: red } /*", Colour: colour.MustParse("#000000")},
+			{Name: "also\nblack", Colour: colour.MustParse("#000001")},
+		},
+	}
+	s.CVD = palette.CheckCVD([]colour.Colour{s.Swatches[0].Colour, s.Swatches[1].Colour}, 0)
+
+	for _, f := range []export.Format{export.CSS, export.SCSS} {
+		t.Run(string(f), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := export.Write(&buf, f, s); err != nil {
+				t.Fatal(err)
+			}
+			out := buf.String()
+			if n := strings.Count(out, "*/"); n != 2 {
+				t.Errorf("expected the name comment and the report comment to be the only ones closed, got %d closings in\n%s", n, out)
+			}
+			if !strings.Contains(out, "black * / a { color: red } /*") {
+				t.Errorf("expected the label to be escaped, got\n%s", out)
+			}
+		})
+	}
+}
+
+func TestWrite_ASE(t *testing.T) {
+	var buf bytes.Buffer
+	if err := export.Write(&buf, export.ASE, set); err != nil {
+		t.Fatal(err)
+	}
+
+	r := bytes.NewReader(buf.Bytes())
+	sig := make([]byte, 4)
+	io.ReadFull(r, sig)
+	var major, minor uint16
+	var blocks uint32
+	binary.Read(r, binary.BigEndian, &major)
+	binary.Read(r, binary.BigEndian, &minor)
+	binary.Read(r, binary.BigEndian, &blocks)
+	if string(sig) != "ASEF" || major != 1 || minor != 0 || blocks != 5 {
+		t.Fatalf("unexpected header %q %d.%d with %d blocks", sig, major, minor, blocks)
+	}
+
+	var names []string
+	var reds []float32
+	for i := 0; i < int(blocks); i++ {
+		var kind uint16
+		var length uint32
+		binary.Read(r, binary.BigEndian, &kind)
+		binary.Read(r, binary.BigEndian, &length)
+		body := make([]byte, length)
+		if _, err := io.ReadFull(r, body); err != nil {
+			t.Fatalf("block %d: %v", i, err)
+		}
+		if kind == 0xC002 {
+			continue
+		}
+		br := bytes.NewReader(body)
+		var n uint16
+		binary.Read(br, binary.BigEndian, &n)
+		units := make([]uint16, n)
+		binary.Read(br, binary.BigEndian, units)
+		names = append(names, string(utf16.Decode(units[:n-1])))
+		if kind == 0x0001 {
+			model := make([]byte, 4)
+			io.ReadFull(br, model)
+			var bits uint32
+			binary.Read(br, binary.BigEndian, &bits)
+			reds = append(reds, math.Float32frombits(bits))
+		}
+	}
+	if r.Len() != 0 {
+		t.Errorf("%d trailing bytes", r.Len())
+	}
+	if strings.Join(names, "|") != "Hexbot history|red|Red|#2CA02C80" {
+		t.Errorf("unexpected names %q", names)
+	}
+	if len(reds) != 3 || reds[1] != 1 {
+		t.Errorf("unexpected red channels %v", reds)
+	}
+}
+
+func TestWrite_SVG(t *testing.T) {
+	s := set
+	s.Name = "<history & more>"
+	s.CVD = palette.CheckCVD([]colour.Colour{s.Swatches[0].Colour, s.Swatches[1].Colour, s.Swatches[2].Colour}, 0)
+
+	var buf bytes.Buffer
+	if err := export.Write(&buf, export.SVG, s); err != nil {
+		t.Fatal(err)
+	}
+	dec := xml.NewDecoder(&buf)
+	rects := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid SVG: %v", err)
+		}
+		if el, ok := tok.(xml.StartElement); ok && el.Name.Local == "rect" {
+			rects++
+		}
+	}
+	// A background, then the swatches once as they are and once per deficiency.
+	if want := 1 + len(s.Swatches)*(1+len(colour.Deficiencies)); rects != want {
+		t.Errorf("expected %d rects, got %d", want, rects)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range export.Formats {
+		if got, err := export.ParseFormat(string(f)); err != nil || got != f || f.Extension() == "" || f.ContentType() == "" {
+			t.Errorf("ParseFormat(%q) = %q, %v", f, got, err)
+		}
+	}
+	if _, err := export.ParseFormat("pdf"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,302 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+)
+
+// Readable lists every format Read understands. SVG swatch sheets are write only.
+var Readable = []Format{GPL, ASE, PaintNET, CSS, SCSS, JSON, CSV}
+
+// ParseError locates a problem in a palette file.
+type ParseError struct {
+	Format Format
+	// Line is the 1-based line in text formats; Offset the byte offset in binary ones.
+	Line   int
+	Offset int64
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	if e.Format == ASE {
+		return fmt.Sprintf("%s at byte %d: %v", e.Format, e.Offset, e.Err)
+	}
+	return fmt.Sprintf("%s line %d: %v", e.Format, e.Line, e.Err)
+}
+
+// Cause returns the underlying error, for errors.Cause.
+func (e *ParseError) Cause() error {
+	return e.Err
+}
+
+// FormatFromPath picks a format from a file's extension.
+func FormatFromPath(path string) (Format, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, f := range Formats {
+		if f.Extension() == ext {
+			return f, nil
+		}
+	}
+	return "", errors.Errorf("can't tell the palette format of %q from its extension", path)
+}
+
+// Read parses a palette in format f. Malformed input gives a *ParseError.
+func Read(r io.Reader, f Format) (*Set, error) {
+	switch f {
+	case GPL:
+		return readGPL(r)
+	case ASE:
+		return readASE(r)
+	case PaintNET:
+		return readPaintNET(r)
+	case CSS:
+		return readVariables(r, CSS, cssVariable)
+	case SCSS:
+		return readVariables(r, SCSS, scssVariable)
+	case JSON:
+		return readJSON(r)
+	case CSV:
+		return readCSV(r)
+	}
+	return nil, errors.Errorf("can't read %s palettes, expected one of %v", f, Readable)
+}
+
+func readGPL(r io.Reader) (*Set, error) {
+	s := &Set{}
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		switch {
+		case line == 1:
+			if text != "GIMP Palette" {
+				return nil, &ParseError{Format: GPL, Line: line, Err: errors.Errorf("expected the header \"GIMP Palette\", got %q", text)}
+			}
+		case text == "" || strings.HasPrefix(text, "#"):
+		case strings.HasPrefix(text, "Name:"):
+			s.Name = strings.TrimSpace(strings.TrimPrefix(text, "Name:"))
+		case strings.HasPrefix(text, "Columns:"):
+		default:
+			fields := strings.Fields(text)
+			if len(fields) < 3 {
+				return nil, &ParseError{Format: GPL, Line: line, Err: errors.Errorf("expected red, green and blue values, got %q", text)}
+			}
+			var rgb [3]uint8
+			for i := range rgb {
+				v, err := strconv.ParseUint(fields[i], 10, 8)
+				if err != nil {
+					return nil, &ParseError{Format: GPL, Line: line, Err: errors.Errorf("channel %q is not a number from 0 to 255", fields[i])}
+				}
+				rgb[i] = uint8(v)
+			}
+			s.Swatches = append(s.Swatches, Swatch{Name: strings.Join(fields[3:], " "), Colour: colour.RGB(rgb[0], rgb[1], rgb[2])})
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, errors.Wrap(err, "problem reading gpl")
+	}
+	if s.Swatches == nil && s.Name == "" {
+		return nil, &ParseError{Format: GPL, Line: 1, Err: errors.New("empty file")}
+	}
+	return s, nil
+}
+
+func readPaintNET(r io.Reader) (*Set, error) {
+	s := &Set{}
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, ";") {
+			continue
+		}
+		if len(text) != 8 {
+			return nil, &ParseError{Format: PaintNET, Line: line, Err: errors.Errorf("expected 8 hex digits aarrggbb, got %q", text)}
+		}
+		v, err := strconv.ParseUint(text, 16, 32)
+		if err != nil {
+			return nil, &ParseError{Format: PaintNET, Line: line, Err: errors.Errorf("expected 8 hex digits aarrggbb, got %q", text)}
+		}
+		s.Swatches = append(s.Swatches, Swatch{Colour: colour.RGBA(uint8(v>>16), uint8(v>>8), uint8(v), uint8(v>>24))})
+	}
+	return s, errors.Wrap(sc.Err(), "problem reading paint.net palette")
+}
+
+var (
+	cssVariable  = regexp.MustCompile(`--([A-Za-z0-9_-]+)\s*:\s*([^;}]*)`)
+	scssVariable = regexp.MustCompile(`\$([A-Za-z0-9_-]+)\s*:\s*([^;]*)`)
+	cssComment   = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	// colourValue matches values meant to be colours, which must then parse.
+	colourValue = regexp.MustCompile(`^(#|rgba?\(|hsla?\()`)
+)
+
+// readVariables collects every declaration whose value is a colour. Other declarations, such as
+// spacing or var() references, are skipped. A leading comment names the set.
+func readVariables(r io.Reader, f Format, declaration *regexp.Regexp) (*Set, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem reading %s", f)
+	}
+	s := &Set{}
+	if m := cssComment.FindIndex(b); m != nil && len(bytes.TrimSpace(b[:m[0]])) == 0 {
+		s.Name = strings.TrimSpace(strings.Trim(string(b[m[0]:m[1]]), "/*"))
+		if i := strings.IndexByte(s.Name, '\n'); i >= 0 {
+			s.Name = strings.TrimSpace(s.Name[:i])
+		}
+	}
+	// Blank out comments, keeping newlines so line numbers stay right.
+	b = cssComment.ReplaceAllFunc(b, func(c []byte) []byte {
+		return bytes.Map(func(r rune) rune {
+			if r == '\n' {
+				return r
+			}
+			return ' '
+		}, c)
+	})
+
+	for _, m := range declaration.FindAllSubmatchIndex(b, -1) {
+		value := strings.TrimSpace(string(b[m[4]:m[5]]))
+		if !colourValue.MatchString(strings.ToLower(value)) {
+			continue
+		}
+		c, err := colour.Parse(value)
+		if err != nil {
+			return nil, &ParseError{Format: f, Line: 1 + bytes.Count(b[:m[0]], []byte("\n")), Err: err}
+		}
+		s.Swatches = append(s.Swatches, Swatch{Name: string(b[m[2]:m[3]]), Colour: c})
+	}
+	return s, nil
+}
+
+// readJSON accepts the Set written by Write, or an array of {"name", "hex"} objects or of colours.
+func readJSON(r io.Reader) (*Set, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem reading json")
+	}
+
+	s := &Set{}
+	var items []json.RawMessage
+	if t := bytes.TrimSpace(b); len(t) > 0 && t[0] == '[' {
+		err = json.Unmarshal(b, &items)
+	} else {
+		var set struct {
+			Name    string            `json:"name"`
+			Colours []json.RawMessage `json:"colours"`
+		}
+		err = json.Unmarshal(b, &set)
+		s.Name, items = set.Name, set.Colours
+	}
+	if err != nil {
+		return nil, &ParseError{Format: JSON, Line: jsonLine(b, err), Err: err}
+	}
+
+	// Raw messages are verbatim copies of the input, so finding each in turn locates it.
+	from := 0
+	for i, item := range items {
+		offset := from
+		if j := bytes.Index(b[from:], item); j >= 0 {
+			offset = from + j
+			from = offset + len(item)
+		}
+
+		var sw Swatch
+		if item[0] == '{' {
+			err = json.Unmarshal(item, &sw)
+		} else {
+			err = json.Unmarshal(item, &sw.Colour)
+		}
+		if err != nil {
+			return nil, &ParseError{Format: JSON, Line: 1 + bytes.Count(b[:offset], []byte("\n")), Err: errors.Wrapf(err, "colour %d", i+1)}
+		}
+		s.Swatches = append(s.Swatches, sw)
+	}
+	return s, nil
+}
+
+// jsonLine finds the line of a JSON decoding error, or 1 when it carries no offset.
+func jsonLine(b []byte, err error) int {
+	var offset int64
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		offset = e.Offset
+	case *json.UnmarshalTypeError:
+		offset = e.Offset
+	}
+	if offset > int64(len(b)) {
+		offset = int64(len(b))
+	}
+	return 1 + bytes.Count(b[:offset], []byte("\n"))
+}
+
+// readCSV reads name,hex rows, or a single column of colours. A header row may name the columns in
+// either order. Quoted fields can't span lines.
+func readCSV(r io.Reader) (*Set, error) {
+	s := &Set{}
+	nameCol, hexCol := 0, 1
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		cr := csv.NewReader(strings.NewReader(text))
+		cr.TrimLeadingSpace = true
+		rec, err := cr.Read()
+		if err != nil {
+			if pe, ok := err.(*csv.ParseError); ok {
+				err = pe.Err
+			}
+			return nil, &ParseError{Format: CSV, Line: line, Err: err}
+		}
+		if len(s.Swatches) == 0 && isCSVHeader(rec) {
+			for i, field := range rec {
+				switch strings.ToLower(strings.TrimSpace(field)) {
+				case "name":
+					nameCol = i
+				case "hex", "colour", "color":
+					hexCol = i
+				}
+			}
+			continue
+		}
+
+		var sw Swatch
+		switch {
+		case len(rec) == 1:
+			sw.Colour, err = colour.Parse(rec[0])
+		case len(rec) > nameCol && len(rec) > hexCol:
+			sw.Name = strings.TrimSpace(rec[nameCol])
+			sw.Colour, err = colour.Parse(rec[hexCol])
+		default:
+			err = errors.Errorf("expected a name and a colour, got %d fields", len(rec))
+		}
+		if err != nil {
+			return nil, &ParseError{Format: CSV, Line: line, Err: err}
+		}
+		s.Swatches = append(s.Swatches, sw)
+	}
+	return s, errors.Wrap(sc.Err(), "problem reading csv")
+}
+
+func isCSVHeader(rec []string) bool {
+	for _, field := range rec {
+		switch strings.ToLower(strings.TrimSpace(field)) {
+		case "name", "hex", "colour", "color":
+		default:
+			return false
+		}
+	}
+	return true
+}
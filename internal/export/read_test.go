@@ -0,0 +1,116 @@
+package export_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/export"
+)
+
+func TestRead_RoundTrip(t *testing.T) {
+	in := export.Set{
+		Name: "Hexbot history",
+		Swatches: []export.Swatch{
+			{Name: "red", Colour: colour.MustParse("#D62728")},
+			{Name: "green", Colour: colour.MustParse("#2CA02C")},
+		},
+	}
+
+	for _, f := range export.Readable {
+		t.Run(string(f), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := export.Write(&buf, f, in); err != nil {
+				t.Fatal(err)
+			}
+			out, err := export.Read(&buf, f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(out.Swatches) != len(in.Swatches) {
+				t.Fatalf("expected %d swatches, got %+v", len(in.Swatches), out.Swatches)
+			}
+			for i, sw := range out.Swatches {
+				if sw.Colour != in.Swatches[i].Colour {
+					t.Errorf("swatch %d is %s, want %s", i, sw.Colour, in.Swatches[i].Colour)
+				}
+				// paint.net files have no names.
+				if f != export.PaintNET && sw.Name != in.Swatches[i].Name {
+					t.Errorf("swatch %d is named %q, want %q", i, sw.Name, in.Swatches[i].Name)
+				}
+			}
+		})
+	}
+}
+
+func TestRead_Variants(t *testing.T) {
+	tests := []struct {
+		Format export.Format
+		In     string
+		Want   []string
+	}{
+		{export.CSS, "/* brand\n   colours */\n:root { --space: 4px; --Brand-Red: rgb(214 39 40); --link: var(--brand-red) }\n.x{--a:#FFF}", []string{"Brand-Red #D62728", "a #FFFFFF"}},
+		{export.SCSS, "$grid: 12;\n$ink: hsl(0, 0%, 10%);\n", []string{"ink #1A1A1A"}},
+		{export.JSON, `["#D62728", "rgb(44, 160, 44)"]`, []string{" #D62728", " #2CA02C"}},
+		{export.JSON, `[{"name": "red", "hex": "#D62728"}]`, []string{"red #D62728"}},
+		{export.CSV, "hex,name\n#D62728,red\n", []string{"red #D62728"}},
+		{export.CSV, "#D62728\n#2CA02C\n", []string{" #D62728", " #2CA02C"}},
+		{export.GPL, "GIMP Palette\nName: x\n# comment\n\n  0 0   255 deep blue\n", []string{"deep blue #0000FF"}},
+	}
+
+	for _, tt := range tests {
+		s, err := export.Read(strings.NewReader(tt.In), tt.Format)
+		if err != nil {
+			t.Errorf("Read(%s, %q) error = %v", tt.Format, tt.In, err)
+			continue
+		}
+		var got []string
+		for _, sw := range s.Swatches {
+			got = append(got, sw.Name+" "+sw.Colour.Hex())
+		}
+		if strings.Join(got, "|") != strings.Join(tt.Want, "|") {
+			t.Errorf("Read(%s, %q) = %q, want %q", tt.Format, tt.In, got, tt.Want)
+		}
+	}
+}
+
+func TestRead_Errors(t *testing.T) {
+	var ase bytes.Buffer
+	export.Write(&ase, export.ASE, export.Set{Swatches: []export.Swatch{{Colour: colour.MustParse("#D62728")}}})
+	truncated := ase.Bytes()[:ase.Len()-10]
+
+	tests := []struct {
+		Format export.Format
+		In     string
+		Want   string
+	}{
+		{export.GPL, "JASC-PAL\n", `gpl line 1: expected the header "GIMP Palette", got "JASC-PAL"`},
+		{export.GPL, "GIMP Palette\n255 0 0 red\n255 0 256 bad\n", `gpl line 3: channel "256" is not a number from 0 to 255`},
+		{export.PaintNET, "; x\nFFFF00\n", `paintnet line 2: expected 8 hex digits aarrggbb, got "FFFF00"`},
+		{export.CSS, ":root {\n  --ok: #fff;\n  --bad: #12345G;\n}", `css line 3: `},
+		{export.JSON, "[\n  \"#FFFFFF\",\n  \"#XYZ\"\n]", `json line 3: colour 2: `},
+		{export.JSON, "{\"name\": \"x\",\n \"colours\": [}", `json line 2: `},
+		{export.CSV, "name,hex\nred,#D62728\nblue\n", `csv line 3: `},
+		{export.ASE, "ASEX", `ase at byte 0: expected the signature ASEF, got "ASEX"`},
+		{export.ASE, string(truncated), `ase at byte `},
+	}
+
+	for _, tt := range tests {
+		_, err := export.Read(strings.NewReader(tt.In), tt.Format)
+		if err == nil {
+			t.Errorf("Read(%s, %q) expected an error", tt.Format, tt.In)
+			continue
+		}
+		if _, ok := err.(*export.ParseError); !ok {
+			t.Errorf("Read(%s, %q) error %v is a %T, want *export.ParseError", tt.Format, tt.In, err, err)
+		}
+		if !strings.HasPrefix(err.Error(), tt.Want) {
+			t.Errorf("Read(%s, %q) error = %q, want prefix %q", tt.Format, tt.In, err, tt.Want)
+		}
+	}
+
+	if _, err := export.Read(strings.NewReader(""), export.SVG); err == nil {
+		t.Error("expected an error reading svg")
+	}
+}
@@ -0,0 +1,87 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"hexbot/internal/colour"
+)
+
+// Swatch sheet layout, in pixels.
+const (
+	svgColumns = 8
+	svgSwatch  = 96
+	svgLabel   = 40
+	svgGap     = 16
+	svgHeading = 40
+)
+
+// writeSVG lays the swatches out in rows under the set's name, each labelled with its name and hex.
+// When the set has a colour vision deficiency report, a section per deficiency follows showing the
+// simulated colours, with clashing swatches outlined.
+func writeSVG(w io.Writer, s Set) error {
+	rows := (len(s.Swatches) + svgColumns - 1) / svgColumns
+	if rows == 0 {
+		rows = 1
+	}
+	columns := svgColumns
+	if len(s.Swatches) < columns && len(s.Swatches) > 0 {
+		columns = len(s.Swatches)
+	}
+	section := svgHeading + rows*(svgSwatch+svgLabel+svgGap)
+	width := svgGap + columns*(svgSwatch+svgGap)
+	height := svgGap + section*(1+len(s.CVD))
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif">`+"\n", width, height, width, height)
+	fmt.Fprintf(bw, `  <rect width="%d" height="%d" fill="#FFFFFF"/>`+"\n", width, height)
+
+	y := svgGap
+	writeSVGSection(bw, y, s.Name, s.Swatches, nil)
+	for _, r := range s.CVD {
+		y += section
+		simulated := make([]Swatch, len(s.Swatches))
+		for i, sw := range s.Swatches {
+			simulated[i] = Swatch{Name: sw.Name, Colour: r.Simulated[i]}
+		}
+		clashes := map[int]bool{}
+		for _, c := range r.Conflicts {
+			clashes[c.A], clashes[c.B] = true, true
+		}
+		writeSVGSection(bw, y, string(r.Deficiency), simulated, clashes)
+	}
+
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+func writeSVGSection(w io.Writer, y int, heading string, swatches []Swatch, clashes map[int]bool) {
+	fmt.Fprintf(w, `  <text x="%d" y="%d" font-size="20" fill="#222222">%s</text>`+"\n", svgGap, y+24, escape(heading))
+	y += svgHeading
+	for i, sw := range swatches {
+		x := svgGap + (i%svgColumns)*(svgSwatch+svgGap)
+		top := y + (i/svgColumns)*(svgSwatch+svgLabel+svgGap)
+		c := sw.Colour
+		fill := colour.RGB(c.R(), c.G(), c.B()).Hex()
+		stroke := `stroke="#CCCCCC"`
+		if clashes[i] {
+			stroke = `stroke="#D00000" stroke-width="4" stroke-dasharray="8 4"`
+		}
+		fmt.Fprintf(w, `  <rect x="%d" y="%d" width="%d" height="%d" rx="8" fill="%s" fill-opacity="%.3f" %s/>`+"\n",
+			x, top, svgSwatch, svgSwatch, fill, float64(c.A())/255, stroke)
+		if sw.Name != "" {
+			fmt.Fprintf(w, `  <text x="%d" y="%d" font-size="12" fill="#222222">%s</text>`+"\n", x, top+svgSwatch+16, escape(sw.Name))
+		}
+		fmt.Fprintf(w, `  <text x="%d" y="%d" font-size="12" font-family="monospace" fill="#666666">%s</text>`+"\n", x, top+svgSwatch+32, c.Hex())
+	}
+}
+
+func escape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(strings.TrimSpace(s)))
+	return buf.String()
+}
@@ -0,0 +1,86 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// gplColumns is how many swatches GIMP shows per row.
+const gplColumns = 8
+
+func writeGPL(w io.Writer, s Set) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "GIMP Palette")
+	fmt.Fprintf(bw, "Name: %s\n", oneLine(s.Name))
+	fmt.Fprintf(bw, "Columns: %d\n", gplColumns)
+	writeConflicts(bw, "# ", s)
+	fmt.Fprintln(bw, "#")
+	for _, sw := range s.Swatches {
+		c := sw.Colour
+		fmt.Fprintf(bw, "%3d %3d %3d\t%s\n", c.R(), c.G(), c.B(), oneLine(sw.label()))
+	}
+	return bw.Flush()
+}
+
+func writePaintNET(w io.Writer, s Set) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "; paint.net Palette File")
+	fmt.Fprintf(bw, "; %s\n", oneLine(s.Name))
+	fmt.Fprintln(bw, "; Colors are written as 8-digit hexadecimal numbers: aarrggbb")
+	writeConflicts(bw, "; ", s)
+	for _, sw := range s.Swatches {
+		c := sw.Colour
+		fmt.Fprintf(bw, "%02X%02X%02X%02X\n", c.A(), c.R(), c.G(), c.B())
+	}
+	return bw.Flush()
+}
+
+func writeVariables(w io.Writer, s Set, open, line, close string) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "/* %s */\n", commentText(s.Name))
+	if len(s.CVD) > 0 {
+		fmt.Fprintln(bw, "/*")
+		writeConflicts(bw, " * ", s)
+		fmt.Fprintln(bw, " */")
+	}
+	bw.WriteString(open)
+	for i, id := range identifiers(s.Swatches) {
+		fmt.Fprintf(bw, line, id, strings.ToLower(s.Swatches[i].Colour.Hex()))
+	}
+	bw.WriteString(close)
+	return bw.Flush()
+}
+
+func writeCSV(w io.Writer, s Set) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"name", "hex"})
+	for _, sw := range s.Swatches {
+		cw.Write([]string{sw.Name, sw.Colour.Hex()})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeConflicts comments on every pair of swatches flagged in the set's colour vision deficiency report.
+// Labels are escaped for block comments, which the CSS and Sass formats write them in.
+func writeConflicts(w io.Writer, prefix string, s Set) {
+	for _, r := range s.CVD {
+		for _, c := range r.Conflicts {
+			fmt.Fprintf(w, "%s%s: %s and %s are hard to tell apart (ΔE %.1f)\n", prefix, r.Deficiency,
+				commentText(s.Swatches[c.A].label()), commentText(s.Swatches[c.B].label()), c.Distance)
+		}
+	}
+}
+
+// oneLine keeps names from breaking line based formats.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// commentText keeps names from breaking line based formats or closing a /* */ comment early.
+func commentText(s string) string {
+	return strings.Replace(oneLine(s), "*/", "* /", -1)
+}
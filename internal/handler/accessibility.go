@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/service"
+)
+
+// GetAccessibility scores the colour hex as text on black, white and every stored colour named by an
+// against query parameter, which may be repeated or comma separated.
+func (h *Handle) GetAccessibility(w http.ResponseWriter, r *http.Request, hex string) {
+	c, err := parseHex(hex)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var against []colour.Colour
+	for _, v := range r.URL.Query()["against"] {
+		for _, s := range strings.Split(v, ",") {
+			bg, err := parseHex(s)
+			if err != nil {
+				h.writeError(w, http.StatusBadRequest, "against: "+err.Error())
+				return
+			}
+			against = append(against, bg)
+		}
+	}
+
+	a, err := h.service.Accessibility(r.Context(), c, against...)
+	if errors.Cause(err) == service.ErrNotFound {
+		h.writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		h.log.Error("problem scoring colour accessibility", err)
+		h.writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, a)
+}
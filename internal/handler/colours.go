@@ -0,0 +1,305 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/db"
+	"hexbot/internal/export"
+	"hexbot/internal/render"
+)
+
+// ColourPage is the body of a GET /colours response.
+type ColourPage struct {
+	Colours []db.ColourDocument `json:"colours"`
+	// NextCursor is passed as cursor to fetch the following page; it is absent on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// colourRepresentations are the forms GetColours answers in, JSON unless asked otherwise.
+var colourRepresentations = []representation{
+	{format: "json", contentType: "application/json"},
+	{format: "csv", contentType: "text/csv; charset=utf-8"},
+	{format: "ndjson", contentType: "application/x-ndjson", aliases: []string{"application/ndjson", "application/jsonl"}},
+	{format: "png", contentType: "image/png"},
+	{format: "svg", contentType: "image/svg+xml"},
+}
+
+// exportFlushInterval is how many colours a streamed export writes between flushes.
+const exportFlushInterval = 100
+
+// GetColours lists saved colours. The query parameters mirror the list command's flags: from and to are
+// RFC 3339 times, r, g, b, hue, saturation and lightness are min:max ranges, sort is fetched_at, h, s or l,
+// and asc, limit and cursor page through the results.
+//
+// The format parameter or, without one, the Accept header picks the representation. JSON, a PNG strip of
+// swatches and an SVG sheet are one page, with a Link header to the next; size and labels shape the PNG.
+// CSV and NDJSON stream every matching colour from cursor on, or the first limit of them, straight from
+// the database, so exports of any size run in constant memory.
+func (h *Handle) GetColours(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Accept")
+	rep, status, err := negotiate(r, colourRepresentations)
+	if err != nil {
+		h.writeError(w, status, err.Error())
+		return
+	}
+	q, err := colourQuery(r.URL.Query())
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if rep.format == "csv" || rep.format == "ndjson" {
+		h.exportColours(w, r, q, rep)
+		return
+	}
+
+	var opts render.Options
+	if rep.format == "png" {
+		if opts, err = renderOptions(r.URL.Query()); err != nil {
+			h.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	page, err := h.service.ListColours(r.Context(), q)
+	if err != nil {
+		h.log.Error("problem listing colours", err)
+		h.writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if page.NextCursor != "" {
+		next := *r.URL
+		v := next.Query()
+		v.Set("cursor", page.NextCursor)
+		next.RawQuery = v.Encode()
+		w.Header().Set("Link", "<"+next.RequestURI()+`>; rel="next"`)
+	}
+
+	switch rep.format {
+	case "png":
+		colours := make([]colour.Colour, len(page.Colours))
+		for i, doc := range page.Colours {
+			colours[i] = doc.Colour
+		}
+		if len(colours) == 0 {
+			h.writeError(w, http.StatusNotFound, "no colours match the query")
+			return
+		}
+		img, err := render.Strip(colours, opts)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.writePNG(w, img)
+	case "svg":
+		set := export.Set{Name: "Colours", Swatches: make([]export.Swatch, len(page.Colours))}
+		for i, doc := range page.Colours {
+			set.Swatches[i] = export.Swatch{Name: doc.Name, Colour: doc.Colour}
+		}
+		w.Header().Set("Content-Type", rep.contentType)
+		if err := export.Write(w, export.SVG, set); err != nil {
+			h.log.Warn("problem writing response: " + err.Error())
+		}
+	default:
+		h.writeJSON(w, http.StatusOK, ColourPage{Colours: page.Colours, NextCursor: page.NextCursor})
+	}
+}
+
+// exportColours streams the colours matching q as CSV or NDJSON, flushing as it goes. The status is sent
+// with the first colour, so a query failing before then is still answered with an error; one failing
+// later can only cut the response short.
+func (h *Handle) exportColours(w http.ResponseWriter, r *http.Request, q db.ColourQuery, rep representation) {
+	var cw colourWriter
+	start := func() error {
+		w.Header().Set("Content-Type", rep.contentType)
+		w.WriteHeader(http.StatusOK)
+		if rep.format == "csv" {
+			cw = newCSVColourWriter(w)
+		} else {
+			cw = ndjsonColourWriter{json.NewEncoder(w)}
+		}
+		return cw.begin()
+	}
+	flusher, _ := w.(http.Flusher)
+	flush := func() error {
+		if err := cw.flush(); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	}
+
+	n := 0
+	err := h.service.EachColour(r.Context(), q, func(doc db.ColourDocument) error {
+		if cw == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := cw.write(doc); err != nil {
+			return err
+		}
+		if n++; n%exportFlushInterval == 0 {
+			return flush()
+		}
+		return nil
+	})
+	switch {
+	case err != nil && cw == nil:
+		h.log.Error("problem exporting colours", err)
+		h.writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	case err != nil:
+		h.log.Warn(fmt.Sprintf("problem exporting colours after %d: %s", n, err))
+		return
+	case cw == nil:
+		err = start()
+	}
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		h.log.Warn("problem writing response: " + err.Error())
+	}
+}
+
+// GetColour describes the colour hex: its value in other colour spaces, its nearest name and, if it has
+// been fetched, its stored record.
+func (h *Handle) GetColour(w http.ResponseWriter, r *http.Request, hex string) {
+	c, err := parseHex(hex)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	d, err := h.service.Describe(r.Context(), c)
+	if err != nil {
+		h.log.Error("problem describing colour", err)
+		h.writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, d)
+}
+
+// colourQuery reads the GetColours query parameters and checks the result can be run.
+func colourQuery(v url.Values) (db.ColourQuery, error) {
+	q := db.ColourQuery{
+		Source: v.Get("source"),
+		Name:   v.Get("name"),
+		SortBy: v.Get("sort"),
+		Cursor: v.Get("cursor"),
+	}
+	var err error
+	for name, t := range map[string]*time.Time{"from": &q.From, "to": &q.To} {
+		if s := v.Get(name); s != "" {
+			if *t, err = time.Parse(time.RFC3339, s); err != nil {
+				return q, errors.Errorf("%s must be an RFC 3339 time, got %q", name, s)
+			}
+		}
+	}
+	if s := v.Get("hex"); s != "" {
+		c, err := parseHex(s)
+		if err != nil {
+			return q, errors.Wrap(err, "hex")
+		}
+		q.Colour = &c
+	}
+	for name, rng := range map[string]**db.Range{
+		"r": &q.R, "g": &q.G, "b": &q.B, "hue": &q.Hue, "saturation": &q.Saturation, "lightness": &q.Lightness,
+	} {
+		if *rng, err = queryRange(v.Get(name), name); err != nil {
+			return q, err
+		}
+	}
+	if s := v.Get("asc"); s != "" {
+		if q.Ascending, err = strconv.ParseBool(s); err != nil {
+			return q, errors.New("asc must be true or false")
+		}
+	}
+	if q.Limit, err = queryInt(v.Get("limit"), "limit"); err != nil {
+		return q, err
+	}
+	if q.Limit < 0 {
+		return q, errors.Errorf("limit must be between 1 and %d, got %d", db.MaxLimit, q.Limit)
+	}
+	return q, q.Validate()
+}
+
+// queryRange parses "min:max", returning nil when v is empty.
+func queryRange(v, name string) (*db.Range, error) {
+	if v == "" {
+		return nil, nil
+	}
+	parts := strings.Split(v, ":")
+	if len(parts) == 2 {
+		min, errMin := strconv.ParseFloat(parts[0], 64)
+		max, errMax := strconv.ParseFloat(parts[1], 64)
+		if errMin == nil && errMax == nil {
+			return &db.Range{Min: min, Max: max}, nil
+		}
+	}
+	return nil, errors.Errorf("%s must look like min:max, got %q", name, v)
+}
+
+// colourWriter writes a streamed export of colours.
+type colourWriter interface {
+	begin() error
+	write(doc db.ColourDocument) error
+	flush() error
+}
+
+// csvColours are the columns of a CSV export.
+var csvColours = []string{"id", "hex", "r", "g", "b", "h", "s", "l", "luminance", "name", "source", "fetched_at", "seen_count"}
+
+type csvColourWriter struct {
+	w *csv.Writer
+}
+
+func newCSVColourWriter(w io.Writer) csvColourWriter {
+	return csvColourWriter{csv.NewWriter(w)}
+}
+
+func (c csvColourWriter) begin() error {
+	return c.w.Write(csvColours)
+}
+
+func (c csvColourWriter) write(doc db.ColourDocument) error {
+	float := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	return c.w.Write([]string{
+		doc.ID.Hex(), doc.Colour.Hex(), strconv.Itoa(doc.R), strconv.Itoa(doc.G), strconv.Itoa(doc.B),
+		float(doc.Hue), float(doc.Saturation), float(doc.Lightness), float(doc.Luminance),
+		doc.Name, doc.Source, doc.FetchedAt.UTC().Format(time.RFC3339Nano), strconv.Itoa(doc.SeenCount),
+	})
+}
+
+func (c csvColourWriter) flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// ndjsonColourWriter writes one JSON colour document per line.
+type ndjsonColourWriter struct {
+	enc *json.Encoder
+}
+
+func (n ndjsonColourWriter) begin() error {
+	return nil
+}
+
+func (n ndjsonColourWriter) write(doc db.ColourDocument) error {
+	return n.enc.Encode(doc)
+}
+
+func (n ndjsonColourWriter) flush() error {
+	return nil
+}
@@ -0,0 +1,170 @@
+package handler_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/db"
+	"hexbot/internal/handler"
+)
+
+var coral = db.ColourDocument{ID: primitive.NewObjectID(), Colour: colour.MustParse("#FF7F50"), Source: "test", SeenCount: 1}
+
+// cursorFor is a cursor as db would issue it for the default sort.
+func cursorFor(id primitive.ObjectID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(`{"s":"","t":"2020-01-01T00:00:00Z","id":"` + id.Hex() + `"}`))
+}
+
+func TestGetColours_Pages(t *testing.T) {
+	next := cursorFor(coral.ID)
+	var queries []db.ColourQuery
+	h := newHandle(&fakeService{listColours: func(q db.ColourQuery) (*db.ColourPage, error) {
+		queries = append(queries, q)
+		if q.Cursor == "" {
+			return &db.ColourPage{Colours: []db.ColourDocument{coral}, NextCursor: next}, nil
+		}
+		return &db.ColourPage{Colours: []db.ColourDocument{coral}}, nil
+	}})
+
+	w := serve(h, http.MethodGet, "/colours?source=test&limit=1", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+	var page handler.ColourPage
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Colours) != 1 || page.Colours[0].Colour != coral.Colour || page.NextCursor != next {
+		t.Errorf("unexpected first page %+v", page)
+	}
+	wantLink := "</colours?cursor=" + next + `&limit=1&source=test>; rel="next"`
+	if link := w.Header().Get("Link"); link != wantLink {
+		t.Errorf("expected Link %s, got %s", wantLink, link)
+	}
+
+	w = serve(h, http.MethodGet, "/colours?source=test&limit=1&cursor="+next, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+	if link := w.Header().Get("Link"); link != "" {
+		t.Errorf("expected no Link on the last page, got %s", link)
+	}
+	if strings.Contains(w.Body.String(), "next_cursor") {
+		t.Errorf("expected no next_cursor on the last page, got %s", w.Body)
+	}
+
+	if len(queries) != 2 || queries[0].Source != "test" || queries[0].Limit != 1 || queries[1].Cursor != next {
+		t.Errorf("unexpected queries %+v", queries)
+	}
+}
+
+func TestGetColours_BadRequest(t *testing.T) {
+	tests := []struct {
+		Query string
+		Want  string
+	}{
+		{Query: "from=yesterday", Want: "from must be an RFC 3339 time"},
+		{Query: "hue=10", Want: "hue must look like min:max"},
+		{Query: "hex=red", Want: "hex"},
+		{Query: "asc=maybe", Want: "asc must be true or false"},
+		{Query: "limit=-1", Want: "limit must be between 1 and 1000"},
+		{Query: "limit=ten", Want: "limit"},
+		{Query: "cursor=!!!", Want: "malformed cursor"},
+		{Query: "sort=h&cursor=" + cursorFor(coral.ID), Want: "cursor was issued for a different sort order"},
+		{Query: "format=xml", Want: `format must be one of json, csv, ndjson, png, svg, got "xml"`},
+	}
+	h := newHandle(&fakeService{})
+	for _, tt := range tests {
+		t.Run(tt.Query, func(t *testing.T) {
+			checkError(t, serve(h, http.MethodGet, "/colours?"+tt.Query, ""), http.StatusBadRequest, tt.Want)
+		})
+	}
+}
+
+func TestGetColours_Negotiates(t *testing.T) {
+	tests := []struct {
+		Desc   string
+		Query  string
+		Accept string
+		Want   string
+	}{
+		{Desc: "json without an accept header", Want: "application/json"},
+		{Desc: "json for anything", Accept: "*/*", Want: "application/json"},
+		{Desc: "csv", Accept: "text/csv", Want: "text/csv; charset=utf-8"},
+		{Desc: "ndjson by an alias", Accept: "application/jsonl", Want: "application/x-ndjson"},
+		{Desc: "highest quality", Accept: "text/csv;q=0.5, application/x-ndjson;q=0.9", Want: "application/x-ndjson"},
+		{Desc: "most specific range", Accept: "image/*;q=0, image/svg+xml", Want: "image/svg+xml"},
+		{Desc: "q=0 rules out", Accept: "application/json;q=0, */*;q=0.1", Want: "text/csv; charset=utf-8"},
+		{Desc: "format beats accept", Query: "format=CSV", Accept: "application/json", Want: "text/csv; charset=utf-8"},
+		{Desc: "unreadable ranges are skipped", Accept: "text/csv;q=high, image/svg+xml", Want: "image/svg+xml"},
+	}
+	h := newHandle(&fakeService{
+		listColours: func(db.ColourQuery) (*db.ColourPage, error) {
+			return &db.ColourPage{Colours: []db.ColourDocument{coral}}, nil
+		},
+		eachColour: func(_ db.ColourQuery, fn func(db.ColourDocument) error) error {
+			return fn(coral)
+		},
+	})
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			w := serve(h, http.MethodGet, "/colours?"+tt.Query, "", "Accept", tt.Accept)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != tt.Want {
+				t.Errorf("expected %s, got %s", tt.Want, ct)
+			}
+			if vary := w.Header().Get("Vary"); vary != "Accept" {
+				t.Errorf("expected Vary: Accept, got %q", vary)
+			}
+		})
+	}
+
+	checkError(t, serve(h, http.MethodGet, "/colours", "", "Accept", "text/html, image/png;q=0"),
+		http.StatusNotAcceptable, "none of application/json, text/csv, application/x-ndjson, image/png, image/svg+xml is acceptable")
+}
+
+func TestGetColours_Exports(t *testing.T) {
+	h := newHandle(&fakeService{eachColour: func(_ db.ColourQuery, fn func(db.ColourDocument) error) error {
+		return fn(coral)
+	}})
+	w := serve(h, http.MethodGet, "/colours?format=csv", "")
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "id,hex,") || !strings.HasPrefix(lines[1], coral.ID.Hex()+",#FF7F50,") {
+		t.Errorf("unexpected CSV export %q", w.Body)
+	}
+
+	w = serve(h, http.MethodGet, "/colours?format=ndjson", "")
+	var doc db.ColourDocument
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil || doc.ID != coral.ID {
+		t.Errorf("unexpected NDJSON export %q: %v", w.Body, err)
+	}
+}
+
+func TestGetColours_Fails(t *testing.T) {
+	h := newHandle(&fakeService{
+		listColours: func(db.ColourQuery) (*db.ColourPage, error) {
+			return nil, errors.New("mongo is down")
+		},
+		eachColour: func(db.ColourQuery, func(db.ColourDocument) error) error {
+			return errors.New("mongo is down")
+		},
+	})
+	checkError(t, serve(h, http.MethodGet, "/colours", ""), http.StatusInternalServerError, "internal error")
+	checkError(t, serve(h, http.MethodGet, "/colours?format=ndjson", ""), http.StatusInternalServerError, "internal error")
+
+	w := serve(h, http.MethodPost, "/colours", "")
+	checkError(t, w, http.StatusMethodNotAllowed, "method not allowed")
+	if allow := w.Header().Get("Allow"); allow != "GET" {
+		t.Errorf("expected Allow: GET, got %q", allow)
+	}
+	checkError(t, serve(h, http.MethodGet, "/colours/red/shades", ""), http.StatusNotFound, "not found")
+}
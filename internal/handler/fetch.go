@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/hexbot"
+	"hexbot/internal/requestctx"
+	"hexbot/internal/service"
+)
+
+// maxBodyBytes bounds the JSON bodies the API reads.
+const maxBodyBytes = 1 << 20
+
+// FetchRequest is the body of a POST /fetch request. Every field is optional.
+type FetchRequest struct {
+	Count  int      `json:"count"`
+	Width  int      `json:"width"`
+	Height int      `json:"height"`
+	Seed   []string `json:"seed"`
+}
+
+// FetchResponse is the body of a POST /fetch response.
+type FetchResponse struct {
+	service.SaveResult
+	// RequestID tags every colour saved by the fetch.
+	RequestID string `json:"request_id"`
+}
+
+// PostFetch fetches colours from Hexbot with the options in the body, which may be empty, and saves them.
+// It answers 503 while the circuit breaker is open, 502 when Hexbot fails and 500 when saving does.
+func (h *Handle) PostFetch(w http.ResponseWriter, r *http.Request) {
+	var req FetchRequest
+	if err := h.decodeJSON(w, r, &req, true); err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	opts := hexbot.FetchOptions{Count: req.Count, Width: req.Width, Height: req.Height, Seed: req.Seed}
+	if err := opts.Validate(); err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := h.service.FetchAndSave(r.Context(), opts)
+	if errors.Cause(err) == hexbot.ErrCircuitOpen {
+		h.writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if service.IsUpstream(err) {
+		h.log.Error("problem fetching colours", err)
+		h.writeError(w, http.StatusBadGateway, "problem fetching colours from hexbot: "+hexbot.ErrorClass(err))
+		return
+	}
+	if err != nil {
+		h.log.Error("problem saving fetched colours", err)
+		h.writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, FetchResponse{SaveResult: res, RequestID: requestctx.RequestID(r.Context())})
+}
+
+// decodeJSON reads r's body into v, rejecting unknown fields, trailing data and bodies over maxBodyBytes.
+// An empty body leaves v untouched when allowEmpty is set.
+func (h *Handle) decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}, allowEmpty bool) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err == io.EOF {
+		if allowEmpty {
+			return nil
+		}
+		return errors.New("request body is empty")
+	}
+	if err != nil {
+		return errors.Wrap(err, "problem reading request body")
+	}
+	if dec.More() {
+		return errors.New("request body holds more than one JSON value")
+	}
+	return nil
+}
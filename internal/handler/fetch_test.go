@@ -0,0 +1,102 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/handler"
+	"hexbot/internal/hexbot"
+	"hexbot/internal/service"
+)
+
+func TestPostFetch(t *testing.T) {
+	var got hexbot.FetchOptions
+	h := newHandle(&fakeService{fetchAndSave: func(opts hexbot.FetchOptions) (service.SaveResult, error) {
+		got = opts
+		return service.SaveResult{Saved: 2, Merged: 1}, nil
+	}})
+
+	w := serve(h, http.MethodPost, "/fetch", `{"count":3,"seed":["FF7F50"]}`, "X-Request-ID", "fetch-1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+	var res handler.FetchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Saved != 2 || res.Merged != 1 || res.RequestID != "fetch-1" {
+		t.Errorf("unexpected response %+v", res)
+	}
+	if got.Count != 3 || len(got.Seed) != 1 || got.Seed[0] != "FF7F50" {
+		t.Errorf("unexpected fetch options %+v", got)
+	}
+
+	if w := serve(h, http.MethodPost, "/fetch", ""); w.Code != http.StatusOK {
+		t.Errorf("expected an empty body to fetch with the defaults, got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestPostFetch_BadRequest(t *testing.T) {
+	tests := []struct {
+		Body string
+		Want string
+	}{
+		{Body: `{"count":`, Want: "problem reading request body"},
+		{Body: `{"colour":"red"}`, Want: `unknown field "colour"`},
+		{Body: `{}{}`, Want: "more than one JSON value"},
+		{Body: `{"count":5000}`, Want: "count must be between 1 and"},
+		{Body: `{"width":10}`, Want: "width and height must be given together"},
+	}
+	h := newHandle(&fakeService{})
+	for _, tt := range tests {
+		t.Run(tt.Body, func(t *testing.T) {
+			checkError(t, serve(h, http.MethodPost, "/fetch", tt.Body), http.StatusBadRequest, tt.Want)
+		})
+	}
+}
+
+func TestPostFetch_Fails(t *testing.T) {
+	tests := []struct {
+		Desc   string
+		Err    error
+		Status int
+		Want   string
+	}{
+		{
+			Desc:   "breaker open",
+			Err:    &service.UpstreamError{Err: errors.Wrap(hexbot.ErrCircuitOpen, "problem fetching")},
+			Status: http.StatusServiceUnavailable,
+			Want:   hexbot.ErrCircuitOpen.Error(),
+		},
+		{
+			Desc:   "hexbot failing",
+			Err:    &service.UpstreamError{Err: &hexbot.StatusError{StatusCode: http.StatusBadGateway, Message: "down"}},
+			Status: http.StatusBadGateway,
+			Want:   "problem fetching colours from hexbot: server_error",
+		},
+		{
+			Desc:   "saving failing",
+			Err:    errors.Wrap(errors.New("mongo is down"), "problem saving colour"),
+			Status: http.StatusInternalServerError,
+			Want:   "internal error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			h := newHandle(&fakeService{fetchAndSave: func(hexbot.FetchOptions) (service.SaveResult, error) {
+				return service.SaveResult{}, tt.Err
+			}})
+			w := serve(h, http.MethodPost, "/fetch", "")
+			checkError(t, w, tt.Status, tt.Want)
+			if strings.Contains(w.Body.String(), "mongo") {
+				t.Errorf("expected the cause kept out of the response, got %s", w.Body)
+			}
+		})
+	}
+
+	checkError(t, serve(newHandle(&fakeService{}), http.MethodGet, "/fetch", ""), http.StatusMethodNotAllowed, "method not allowed")
+}
@@ -2,50 +2,78 @@ package handler
 
 import (
 	"context"
+	"fmt"
+	"image"
+	"image/gif"
+	"time"
+
 	"github.com/River-Island/product-backbone-v2/logging"
 	"github.com/pkg/errors"
-	"io/ioutil"
-	"net/http"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/config"
+	"hexbot/internal/db"
+	"hexbot/internal/hexbot"
+	"hexbot/internal/palette"
+	"hexbot/internal/service"
 )
 
 type Service interface {
-	SaveColour(ctx context.Context, colour []byte) error
+	FetchColourFromHexbot(ctx context.Context, opts hexbot.FetchOptions) error
+	SaveColour(ctx context.Context) (service.SaveResult, error)
+	FetchAndSave(ctx context.Context, opts hexbot.FetchOptions) (service.SaveResult, error)
+	ListColours(ctx context.Context, q db.ColourQuery) (*db.ColourPage, error)
+	EachColour(ctx context.Context, q db.ColourQuery, fn func(db.ColourDocument) error) error
+	ColoursSince(ctx context.Context, id primitive.ObjectID, limit int) ([]db.ColourDocument, error)
+	Describe(ctx context.Context, c colour.Colour) (*service.Description, error)
+	Accessibility(ctx context.Context, c colour.Colour, against ...colour.Colour) (*service.Accessibility, error)
+	Palette(ctx context.Context, id primitive.ObjectID) (*db.PaletteDocument, error)
+	ListPalettes(ctx context.Context, q db.PaletteQuery) (*db.PalettePage, error)
+	CreatePalette(ctx context.Context, p palette.Palette) (*db.PaletteDocument, error)
+	UpdatePalette(ctx context.Context, id primitive.ObjectID, p palette.Palette) (*db.PaletteDocument, error)
+	DeletePalette(ctx context.Context, id primitive.ObjectID) error
+	Canvas(ctx context.Context, opts hexbot.FetchOptions, size int) (*image.NRGBA, error)
+	Timelapse(ctx context.Context, opts service.TimelapseOptions) (*gif.GIF, error)
+	LastFetch() *service.FetchOutcome
 }
 
-type Handle struct{
-	log *logging.Logger
-	service Service
-	hexStream []byte
+type Handle struct {
+	log           *logging.Logger
+	service       Service
+	scheduler     Scheduler
+	elector       Elector
+	stream        Stream
+	streamOrigins []string
+	database      Database
+	upstream      Upstream
+	build         *BuildInfo
+	config        *config.Config
+	started       time.Time
+	metrics       Metrics
 }
 
-func NewHandle(logger *logging.Logger, s Service) *Handle{
+func NewHandle(logger *logging.Logger, s Service) *Handle {
 	return &Handle{
-		log: logger,
+		log:     logger,
 		service: s,
+		started: time.Now(),
 	}
 }
 
-
-//handler should listen on a port, GET should be done on service level
-func (h *Handle) GetHexFromHexbot(ctx context.Context) (err error){
-
-	//create interface for this
-	resp, err := http.Get("https://api.noopschallenge.com/hexbot")
+// GetHexFromHexbot fetches colours with opts and saves them, for the fetch command. Served requests use
+// PostFetch, which does the same without sharing state between requests.
+func (h *Handle) GetHexFromHexbot(ctx context.Context, opts hexbot.FetchOptions) (err error) {
+	err = h.service.FetchColourFromHexbot(ctx, opts)
 	if err != nil {
-		return errors.Wrap(err, "problem getting hex from hexbot")
+		return errors.Wrap(err, "problem fetching colour through service")
 	}
 
-	defer resp.Body.Close()
-
-	h.hexStream, err = ioutil.ReadAll(resp.Body)
-	if err != nil{
-		return errors.Wrap(err, "problem reading body of http response from hexbot")
-	}
-
-	err = h.service.SaveColour(ctx, h.hexStream)
-	if err != nil{
-		return errors.Wrap(err, "problem passing byte array to service.savecolour")
+	res, err := h.service.SaveColour(ctx)
+	if err != nil {
+		return errors.Wrap(err, "problem passing colour to service.savecolour")
 	}
+	h.log.Info(fmt.Sprintf("saved %d colours, rejected %d, merged %d", res.Saved, res.Rejected, res.Merged))
 
 	return nil
 }
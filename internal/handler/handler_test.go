@@ -0,0 +1,103 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/db"
+	"hexbot/internal/handler"
+	"hexbot/internal/hexbot"
+	"hexbot/internal/palette"
+	"hexbot/internal/service"
+)
+
+// fakeService answers with the funcs a test sets. Calling any other method panics through the nil
+// Service, which the handler answers with a 500.
+type fakeService struct {
+	handler.Service
+	listColours   func(q db.ColourQuery) (*db.ColourPage, error)
+	eachColour    func(q db.ColourQuery, fn func(db.ColourDocument) error) error
+	coloursSince  func(id primitive.ObjectID, limit int) ([]db.ColourDocument, error)
+	fetchAndSave  func(opts hexbot.FetchOptions) (service.SaveResult, error)
+	listPalettes  func(q db.PaletteQuery) (*db.PalettePage, error)
+	palette       func(id primitive.ObjectID) (*db.PaletteDocument, error)
+	createPalette func(p palette.Palette) (*db.PaletteDocument, error)
+	canvas        func(opts hexbot.FetchOptions, size int) (*image.NRGBA, error)
+}
+
+func (s *fakeService) ListColours(_ context.Context, q db.ColourQuery) (*db.ColourPage, error) {
+	return s.listColours(q)
+}
+
+func (s *fakeService) EachColour(_ context.Context, q db.ColourQuery, fn func(db.ColourDocument) error) error {
+	return s.eachColour(q, fn)
+}
+
+func (s *fakeService) ColoursSince(_ context.Context, id primitive.ObjectID, limit int) ([]db.ColourDocument, error) {
+	return s.coloursSince(id, limit)
+}
+
+func (s *fakeService) FetchAndSave(_ context.Context, opts hexbot.FetchOptions) (service.SaveResult, error) {
+	return s.fetchAndSave(opts)
+}
+
+func (s *fakeService) ListPalettes(_ context.Context, q db.PaletteQuery) (*db.PalettePage, error) {
+	return s.listPalettes(q)
+}
+
+func (s *fakeService) Palette(_ context.Context, id primitive.ObjectID) (*db.PaletteDocument, error) {
+	return s.palette(id)
+}
+
+func (s *fakeService) CreatePalette(_ context.Context, p palette.Palette) (*db.PaletteDocument, error) {
+	return s.createPalette(p)
+}
+
+func (s *fakeService) Canvas(_ context.Context, opts hexbot.FetchOptions, size int) (*image.NRGBA, error) {
+	return s.canvas(opts, size)
+}
+
+// serve sends one request to h's routes. header holds name and value pairs.
+func serve(h *handler.Handle, method, target, body string, header ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	for i := 0; i+1 < len(header); i += 2 {
+		r.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	h.Routes().ServeHTTP(w, r)
+	return w
+}
+
+// newHandle serves s without logging.
+func newHandle(s handler.Service) *handler.Handle {
+	return handler.NewHandle(logging.NopLogger, s)
+}
+
+// checkError fails t unless w answered status with an ErrorResponse whose message contains msg.
+func checkError(t *testing.T, w *httptest.ResponseRecorder, status int, msg string) {
+	t.Helper()
+	if w.Code != status {
+		t.Fatalf("expected status %d, got %d: %s", status, w.Code, w.Body)
+	}
+	var e handler.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &e); err != nil {
+		t.Fatalf("expected an error response, got %q: %v", w.Body, err)
+	}
+	if e.Error.Status != status || e.Error.Code != strings.Replace(strings.ToLower(http.StatusText(status)), " ", "_", -1) {
+		t.Errorf("expected status %d in the body, got %+v", status, e.Error)
+	}
+	if !strings.Contains(e.Error.Message, msg) {
+		t.Errorf("expected a message containing %q, got %q", msg, e.Error.Message)
+	}
+	if e.Error.RequestID == "" || e.Error.RequestID != w.Header().Get("X-Request-ID") {
+		t.Errorf("expected the request id %q in the body, got %q", w.Header().Get("X-Request-ID"), e.Error.RequestID)
+	}
+}
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+
+	"hexbot/internal/config"
+	"hexbot/internal/db"
+	"hexbot/internal/hexbot"
+	"hexbot/internal/service"
+)
+
+// readyTimeout bounds every check made by GetReady.
+const readyTimeout = 3 * time.Second
+
+// upstreamMaxAge is how long GetReady trusts the outcome of the last request made to Hexbot before
+// checking it again, so that frequent probes don't each cost a request upstream.
+const upstreamMaxAge = 30 * time.Second
+
+// Check outcomes. A failed check makes the replica unready; a degraded one is reported but leaves it
+// ready, since stored colours can still be served while fetching is impaired.
+const (
+	CheckOK       = "ok"
+	CheckDegraded = "degraded"
+	CheckFailed   = "failed"
+)
+
+// Database is the store GetReady pings.
+type Database interface {
+	Ping(ctx context.Context) (*db.ServerStatus, error)
+}
+
+// Upstream is where colours are fetched from, such as a hexbot.Resilient.
+type Upstream interface {
+	// Reachable reports whether upstream answered its last request made within maxAge, checking
+	// again when there was none.
+	Reachable(ctx context.Context, maxAge time.Duration) error
+	Breaker() *hexbot.Breaker
+}
+
+// BuildInfo identifies the running binary.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// WithHealth checks database and upstream at /readyz.
+func (h *Handle) WithHealth(database Database, upstream Upstream) *Handle {
+	h.database, h.upstream = database, upstream
+	return h
+}
+
+// WithStatus reports build and cfg, with its secrets redacted, at /debug/status.
+func (h *Handle) WithStatus(build BuildInfo, cfg config.Config) *Handle {
+	if build.GoVersion == "" {
+		build.GoVersion = runtime.Version()
+	}
+	redacted := cfg.Redacted()
+	h.build, h.config = &build, &redacted
+	return h
+}
+
+// Health is the body of a GET /healthz response.
+type Health struct {
+	Status string `json:"status"`
+	Uptime string `json:"uptime"`
+}
+
+// GetHealth reports the process is alive. It checks no dependencies, so that an orchestrator restarts
+// the replica only when it has stopped answering, not when Mongo or Hexbot are down.
+func (h *Handle) GetHealth(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, http.StatusOK, Health{Status: CheckOK, Uptime: h.uptime()})
+}
+
+// Check is the outcome of one readiness check.
+type Check struct {
+	Status  string      `json:"status"`
+	Latency string      `json:"latency,omitempty"`
+	Error   string      `json:"error,omitempty"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// Readiness is the body of a GET /readyz response. Status is the worst of the checks.
+type Readiness struct {
+	Status string           `json:"status"`
+	Checks map[string]Check `json:"checks"`
+}
+
+// SchedulerDetails is what the scheduler check reports.
+type SchedulerDetails struct {
+	Jobs    int `json:"jobs"`
+	Paused  int `json:"paused"`
+	Running int `json:"running"`
+	// Leader is set when replicas elect one of them to run the schedule.
+	Leader *bool `json:"leader,omitempty"`
+}
+
+// GetReady checks the replica can serve traffic: Mongo answers a ping, Hexbot answered its last request
+// or, when that was over upstreamMaxAge ago, a fetch, the circuit breaker is closed and, when there is
+// one, how the scheduler stands. It answers 503 when Mongo is unreachable and 200 otherwise, with every
+// check's outcome in a Readiness.
+func (h *Handle) GetReady(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+	defer cancel()
+
+	checks := map[string]func(context.Context) Check{}
+	if h.database != nil {
+		checks["mongo"] = func(ctx context.Context) Check {
+			st, err := h.database.Ping(ctx)
+			if err != nil {
+				return Check{Status: CheckFailed, Error: err.Error()}
+			}
+			return Check{Status: CheckOK, Details: st}
+		}
+	}
+	if h.upstream != nil {
+		checks["hexbot"] = func(ctx context.Context) Check {
+			if err := h.upstream.Reachable(ctx, upstreamMaxAge); err != nil {
+				return Check{Status: CheckDegraded, Error: err.Error()}
+			}
+			return Check{Status: CheckOK}
+		}
+		checks["breaker"] = func(context.Context) Check {
+			state := h.upstream.Breaker().State()
+			c := Check{Status: CheckOK, Details: map[string]string{"state": state.String()}}
+			if state != hexbot.BreakerClosed {
+				c.Status, c.Error = CheckDegraded, "the hexbot circuit breaker is "+state.String()
+			}
+			return c
+		}
+	}
+	if h.scheduler != nil {
+		checks["scheduler"] = func(context.Context) Check {
+			var d SchedulerDetails
+			for _, st := range h.scheduler.Jobs() {
+				d.Jobs++
+				if st.Paused {
+					d.Paused++
+				}
+				if st.Running {
+					d.Running++
+				}
+			}
+			if h.elector != nil {
+				leader := h.elector.Status().Leader
+				d.Leader = &leader
+			}
+			return Check{Status: CheckOK, Details: d}
+		}
+	}
+
+	res := Readiness{Status: CheckOK, Checks: make(map[string]Check, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(context.Context) Check) {
+			defer wg.Done()
+			start := time.Now()
+			c := check(ctx)
+			c.Latency = time.Since(start).Round(time.Microsecond).String()
+			mu.Lock()
+			defer mu.Unlock()
+			res.Checks[name] = c
+		}(name, check)
+	}
+	wg.Wait()
+
+	for _, c := range res.Checks {
+		if c.Status == CheckFailed || (c.Status == CheckDegraded && res.Status == CheckOK) {
+			res.Status = c.Status
+		}
+	}
+	status := http.StatusOK
+	if res.Status == CheckFailed {
+		status = http.StatusServiceUnavailable
+	}
+	h.writeJSON(w, status, res)
+}
+
+// Status is the body of a GET /debug/status response.
+type Status struct {
+	Build     *BuildInfo     `json:"build,omitempty"`
+	StartedAt time.Time      `json:"started_at"`
+	Uptime    string         `json:"uptime"`
+	Config    *config.Config `json:"config,omitempty"`
+	// LastFetch is nil until the first fetch since the process started.
+	LastFetch *service.FetchOutcome `json:"last_fetch,omitempty"`
+	Breaker   string                `json:"breaker,omitempty"`
+}
+
+// GetStatus reports the build, the configuration with its secrets redacted, the uptime and how the last
+// fetch went, for someone diagnosing a replica.
+func (h *Handle) GetStatus(w http.ResponseWriter, r *http.Request) {
+	st := Status{
+		Build:     h.build,
+		StartedAt: h.started.UTC(),
+		Uptime:    h.uptime(),
+		Config:    h.config,
+		LastFetch: h.service.LastFetch(),
+	}
+	if h.upstream != nil {
+		st.Breaker = h.upstream.Breaker().State().String()
+	}
+	h.writeJSON(w, http.StatusOK, st)
+}
+
+func (h *Handle) uptime() string {
+	return time.Since(h.started).Round(time.Second).String()
+}
@@ -0,0 +1,134 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/pkg/errors"
+
+	"hexbot/internal/db"
+	"hexbot/internal/handler"
+	"hexbot/internal/hexbot"
+)
+
+// database answers pings with err.
+type database struct {
+	err error
+}
+
+func (d database) Ping(context.Context) (*db.ServerStatus, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	return &db.ServerStatus{Version: "4.0.0", Primary: true}, nil
+}
+
+// upstream reports err as whether Hexbot is reachable.
+type upstream struct {
+	err     error
+	breaker *hexbot.Breaker
+	maxAge  time.Duration
+}
+
+func (u *upstream) Reachable(_ context.Context, maxAge time.Duration) error {
+	u.maxAge = maxAge
+	return u.err
+}
+
+func (u *upstream) Breaker() *hexbot.Breaker {
+	return u.breaker
+}
+
+func TestGetReady(t *testing.T) {
+	open := hexbot.NewBreaker(logging.NopLogger, hexbot.BreakerConfig{FailureThreshold: 1, CoolDown: time.Hour})
+	if err := open.Allow(); err != nil {
+		t.Fatal(err)
+	}
+	open.Record(false)
+
+	tests := []struct {
+		Desc     string
+		Database database
+		Upstream error
+		Breaker  *hexbot.Breaker
+		Code     int
+		Status   string
+		Checks   map[string]string
+	}{
+		{
+			Desc:   "everything up",
+			Code:   http.StatusOK,
+			Status: handler.CheckOK,
+			Checks: map[string]string{"mongo": handler.CheckOK, "hexbot": handler.CheckOK, "breaker": handler.CheckOK},
+		},
+		{
+			Desc:     "mongo down",
+			Database: database{err: errors.New("problem pinging mongo: no reachable servers")},
+			Code:     http.StatusServiceUnavailable,
+			Status:   handler.CheckFailed,
+			Checks:   map[string]string{"mongo": handler.CheckFailed, "hexbot": handler.CheckOK, "breaker": handler.CheckOK},
+		},
+		{
+			Desc:     "hexbot down",
+			Upstream: errors.New("problem getting hex: 502"),
+			Breaker:  open,
+			Code:     http.StatusOK,
+			Status:   handler.CheckDegraded,
+			Checks:   map[string]string{"mongo": handler.CheckOK, "hexbot": handler.CheckDegraded, "breaker": handler.CheckDegraded},
+		},
+		{
+			Desc:     "everything down",
+			Database: database{err: errors.New("problem pinging mongo: no reachable servers")},
+			Upstream: errors.New("problem getting hex: 502"),
+			Code:     http.StatusServiceUnavailable,
+			Status:   handler.CheckFailed,
+			Checks:   map[string]string{"mongo": handler.CheckFailed, "hexbot": handler.CheckDegraded, "breaker": handler.CheckOK},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			if tt.Breaker == nil {
+				tt.Breaker = hexbot.NewBreaker(logging.NopLogger, hexbot.BreakerConfig{})
+			}
+			u := &upstream{err: tt.Upstream, breaker: tt.Breaker}
+			h := newHandle(&fakeService{}).WithHealth(tt.Database, u)
+
+			w := serve(h, http.MethodGet, "/readyz", "")
+			if w.Code != tt.Code {
+				t.Fatalf("expected %d, got %d: %s", tt.Code, w.Code, w.Body)
+			}
+			var res handler.Readiness
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Fatal(err)
+			}
+			if res.Status != tt.Status {
+				t.Errorf("expected %s, got %s", tt.Status, res.Status)
+			}
+			for name, want := range tt.Checks {
+				c := res.Checks[name]
+				if c.Status != want {
+					t.Errorf("expected the %s check %s, got %+v", name, want, c)
+				}
+				if (want == handler.CheckOK) != (c.Error == "") {
+					t.Errorf("expected the %s check to explain only a problem, got %+v", name, c)
+				}
+			}
+			if u.maxAge <= 0 {
+				t.Errorf("expected hexbot checked against a recent outcome, got max age %v", u.maxAge)
+			}
+		})
+	}
+}
+
+func TestGetHealth(t *testing.T) {
+	h := newHandle(&fakeService{}).WithHealth(database{err: errors.New("mongo is down")}, &upstream{})
+	w := serve(h, http.MethodGet, "/healthz", "")
+	if w.Code != http.StatusOK {
+		t.Errorf("expected liveness to ignore dependencies, got %d: %s", w.Code, w.Body)
+	}
+	checkError(t, serve(h, http.MethodPost, "/readyz", ""), http.StatusMethodNotAllowed, "method not allowed")
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"hexbot/internal/metrics"
+)
+
+// Metrics records served requests and writes every metric for scraping, such as a metrics.Metrics.
+type Metrics interface {
+	ObserveRequest(method, route string, status int, d time.Duration)
+	WriteText(w io.Writer) error
+}
+
+// WithMetrics records every request in m and serves m at /metrics.
+func (h *Handle) WithMetrics(m Metrics) *Handle {
+	h.metrics = m
+	return h
+}
+
+// GetMetrics writes every metric in the Prometheus text exposition format.
+func (h *Handle) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	if h.metrics == nil {
+		h.writeError(w, http.StatusNotFound, "metrics are off")
+		return
+	}
+	w.Header().Set("Content-Type", metrics.ContentType)
+	if err := h.metrics.WriteText(w); err != nil {
+		h.log.Warn("problem writing metrics: " + err.Error())
+	}
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/requestctx"
+)
+
+// requestIDHeader carries the request id in both directions. A caller's id is kept when it looks sane.
+const requestIDHeader = "X-Request-ID"
+
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// statusRecorder remembers what a handler answered, for the request log.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	n, err := s.ResponseWriter.Write(b)
+	s.bytes += n
+	return n, err
+}
+
+// Flush lets streaming handlers flush through the recorder.
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets WebSocket handlers take over the connection through the recorder.
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := s.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer can't be hijacked")
+	}
+	if s.status == 0 {
+		s.status = http.StatusSwitchingProtocols
+	}
+	return hj.Hijack()
+}
+
+// logRequests tags every request with an id, carried by its context and echoed in the X-Request-ID
+// header, turns panics into 500s and logs one line per request once it has been answered. A handler
+// panicking with http.ErrAbortHandler still has its connection dropped by net/http. With WithMetrics,
+// requests are also recorded against the pattern of next they matched, when next is a ServeMux, so that
+// ids in paths don't split them up.
+func (h *Handle) logRequests(next http.Handler) http.Handler {
+	mux, _ := next.(*http.ServeMux)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = requestctx.NewRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+		rec := &statusRecorder{ResponseWriter: w}
+		start := time.Now()
+
+		defer func() {
+			p := recover()
+			if p == http.ErrAbortHandler {
+				// Only net/http can drop the connection, once the request is logged.
+				defer panic(p)
+			} else if p != nil {
+				h.log.Error(fmt.Sprintf("panic serving %s %s", r.Method, r.URL.Path), errors.Errorf("%v", p))
+				if rec.status == 0 {
+					h.writeError(rec, http.StatusInternalServerError, "internal error")
+				}
+			}
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+			elapsed := time.Since(start)
+			if h.metrics != nil {
+				var route string
+				if mux != nil {
+					_, route = mux.Handler(r)
+				}
+				h.metrics.ObserveRequest(r.Method, route, rec.status, elapsed)
+			}
+			msg := fmt.Sprintf("%s %s %d %dB %s request_id=%s", r.Method, r.URL.RequestURI(), rec.status, rec.bytes,
+				elapsed.Round(time.Microsecond), id)
+			switch {
+			case rec.status >= http.StatusInternalServerError:
+				h.log.Warn(msg)
+			case r.URL.Path == "/healthz" || r.URL.Path == "/readyz" || r.URL.Path == "/metrics":
+				// Orchestrators and scrapers poll these every few seconds, which would drown out everything else.
+				h.log.Debug(msg)
+			default:
+				h.log.Info(msg)
+			}
+		}()
+		next.ServeHTTP(rec, r.WithContext(requestctx.WithRequestID(r.Context(), id)))
+	})
+}
@@ -0,0 +1,26 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	"hexbot/internal/db"
+)
+
+func TestLogRequests_Panics(t *testing.T) {
+	h := newHandle(&fakeService{listColours: func(db.ColourQuery) (*db.ColourPage, error) {
+		panic("nil map")
+	}})
+	checkError(t, serve(h, http.MethodGet, "/colours", ""), http.StatusInternalServerError, "internal error")
+
+	h = newHandle(&fakeService{listColours: func(db.ColourQuery) (*db.ColourPage, error) {
+		panic(http.ErrAbortHandler)
+	}})
+	defer func() {
+		if p := recover(); p != http.ErrAbortHandler {
+			t.Errorf("expected http.ErrAbortHandler to reach net/http, got %v", p)
+		}
+	}()
+	w := serve(h, http.MethodGet, "/colours", "")
+	t.Errorf("expected the request aborted, got %d: %s", w.Code, w.Body)
+}
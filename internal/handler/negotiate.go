@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// representation is one form a resource can be returned in.
+type representation struct {
+	// format is the name given to the format query parameter.
+	format      string
+	contentType string
+	// aliases are other media types clients use to ask for it.
+	aliases []string
+}
+
+// mediaTypes are the media types naming rep, its own first.
+func (rep representation) mediaTypes() []string {
+	t, _, _ := mime.ParseMediaType(rep.contentType)
+	return append([]string{t}, rep.aliases...)
+}
+
+// negotiate picks one of offers, preferred in order, by the format query parameter or, without one, the
+// quality values of the Accept header, returning the status to answer with when none will do: 400 for an
+// unknown format and 406 when the Accept header rules every offer out.
+func negotiate(r *http.Request, offers []representation) (representation, int, error) {
+	names := make([]string, len(offers))
+	for i, rep := range offers {
+		names[i] = rep.format
+	}
+	if f := r.URL.Query().Get("format"); f != "" {
+		for _, rep := range offers {
+			if strings.EqualFold(f, rep.format) {
+				return rep, 0, nil
+			}
+		}
+		return representation{}, http.StatusBadRequest, errors.Errorf("format must be one of %s, got %q", strings.Join(names, ", "), f)
+	}
+
+	accept := acceptRanges(r.Header["Accept"])
+	if len(accept) == 0 {
+		return offers[0], 0, nil
+	}
+	best, bestQ := -1, 0.0
+	for i, rep := range offers {
+		if q := accept.quality(rep.mediaTypes()); q > bestQ {
+			best, bestQ = i, q
+		}
+	}
+	if best < 0 {
+		types := make([]string, len(offers))
+		for i, rep := range offers {
+			types[i] = rep.mediaTypes()[0]
+		}
+		return representation{}, http.StatusNotAcceptable, errors.Errorf("none of %s is acceptable", strings.Join(types, ", "))
+	}
+	return offers[best], 0, nil
+}
+
+// mediaRange is one entry of an Accept header.
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+type mediaRanges []mediaRange
+
+// acceptRanges parses Accept headers, skipping entries it can't read.
+func acceptRanges(headers []string) mediaRanges {
+	var ranges mediaRanges
+	for _, h := range headers {
+		for _, entry := range strings.Split(h, ",") {
+			t, params, err := mime.ParseMediaType(strings.TrimSpace(entry))
+			if err != nil {
+				continue
+			}
+			slash := strings.Index(t, "/")
+			if slash < 0 {
+				continue
+			}
+			mr := mediaRange{typ: t[:slash], subtype: t[slash+1:], q: 1}
+			if v, ok := params["q"]; ok {
+				if mr.q, err = strconv.ParseFloat(v, 64); err != nil || mr.q < 0 || mr.q > 1 {
+					continue
+				}
+			}
+			ranges = append(ranges, mr)
+		}
+	}
+	return ranges
+}
+
+// quality is the highest quality value the ranges give any of types, each taken from the most specific
+// range matching it, so that "image/*;q=0, image/png" accepts PNG alone among images.
+func (ranges mediaRanges) quality(types []string) float64 {
+	best := 0.0
+	for _, t := range types {
+		slash := strings.Index(t, "/")
+		q, specificity := 0.0, -1
+		for _, mr := range ranges {
+			s := -1
+			switch {
+			case mr.typ == t[:slash] && mr.subtype == t[slash+1:]:
+				s = 2
+			case mr.typ == t[:slash] && mr.subtype == "*":
+				s = 1
+			case mr.typ == "*" && mr.subtype == "*":
+				s = 0
+			}
+			if s > specificity {
+				q, specificity = mr.q, s
+			}
+		}
+		if q > best {
+			best = q
+		}
+	}
+	return best
+}
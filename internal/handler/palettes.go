@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/db"
+	"hexbot/internal/palette"
+	"hexbot/internal/service"
+)
+
+// PalettePage is the body of a GET /palettes response.
+type PalettePage struct {
+	Palettes []db.PaletteDocument `json:"palettes"`
+	// NextCursor is passed as cursor to fetch the following page; it is absent on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// palettes dispatches requests for /palettes and /palettes/{id}.
+func (h *Handle) palettes(w http.ResponseWriter, r *http.Request) {
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/palettes"), "/")
+	if id == "" {
+		switch {
+		case r.Method == http.MethodPost:
+			h.PostPalette(w, r)
+		case h.allow(w, r, http.MethodGet, http.MethodPost):
+			h.GetPalettes(w, r)
+		}
+		return
+	}
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		h.writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	switch {
+	case r.Method == http.MethodPut:
+		h.PutPalette(w, r, oid)
+	case r.Method == http.MethodDelete:
+		h.DeletePalette(w, r, oid)
+	case h.allow(w, r, http.MethodGet, http.MethodPut, http.MethodDelete):
+		h.GetPalette(w, r, oid)
+	}
+}
+
+// GetPalettes lists a page of stored palettes, newest first, filtered by the source and harmony query
+// parameters and paged with limit and cursor.
+func (h *Handle) GetPalettes(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+	q := db.PaletteQuery{Source: v.Get("source"), Cursor: v.Get("cursor")}
+	var err error
+	if s := v.Get("harmony"); s != "" {
+		if q.Harmony, err = palette.ParseHarmony(s); err != nil {
+			h.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if q.Limit, err = queryInt(v.Get("limit"), "limit"); err == nil && q.Limit < 0 {
+		err = errors.Errorf("limit must be between 1 and %d, got %d", db.MaxLimit, q.Limit)
+	}
+	if err == nil {
+		err = q.Validate()
+	}
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := h.service.ListPalettes(r.Context(), q)
+	if err != nil {
+		h.log.Error("problem listing palettes", err)
+		h.writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, PalettePage{Palettes: page.Palettes, NextCursor: page.NextCursor})
+}
+
+// GetPalette returns the stored palette with id.
+func (h *Handle) GetPalette(w http.ResponseWriter, r *http.Request, id primitive.ObjectID) {
+	doc, err := h.service.Palette(r.Context(), id)
+	h.writePalette(w, http.StatusOK, doc, err)
+}
+
+// PostPalette stores the palette in the body. A body with a seed and harmony but no colours generates them.
+func (h *Handle) PostPalette(w http.ResponseWriter, r *http.Request) {
+	var p palette.Palette
+	if err := h.decodeJSON(w, r, &p, false); err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	doc, err := h.service.CreatePalette(r.Context(), p)
+	if err == nil {
+		w.Header().Set("Location", "/palettes/"+doc.ID.Hex())
+	}
+	h.writePalette(w, http.StatusCreated, doc, err)
+}
+
+// PutPalette replaces the stored palette with id by the one in the body, read as for PostPalette.
+func (h *Handle) PutPalette(w http.ResponseWriter, r *http.Request, id primitive.ObjectID) {
+	var p palette.Palette
+	if err := h.decodeJSON(w, r, &p, false); err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	doc, err := h.service.UpdatePalette(r.Context(), id, p)
+	h.writePalette(w, http.StatusOK, doc, err)
+}
+
+// DeletePalette removes the stored palette with id.
+func (h *Handle) DeletePalette(w http.ResponseWriter, r *http.Request, id primitive.ObjectID) {
+	err := h.service.DeletePalette(r.Context(), id)
+	if err == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.writePalette(w, http.StatusNoContent, nil, err)
+}
+
+// writePalette answers with doc, or with the status matching err.
+func (h *Handle) writePalette(w http.ResponseWriter, status int, doc *db.PaletteDocument, err error) {
+	switch errors.Cause(err) {
+	case nil:
+		h.writeJSON(w, status, doc)
+	case service.ErrPaletteNotFound:
+		h.writeError(w, http.StatusNotFound, err.Error())
+	case service.ErrInvalidPalette:
+		h.writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.log.Error("problem handling palette", err)
+		h.writeError(w, http.StatusInternalServerError, "internal error")
+	}
+}
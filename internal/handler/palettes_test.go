@@ -0,0 +1,118 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/db"
+	"hexbot/internal/handler"
+	"hexbot/internal/palette"
+	"hexbot/internal/service"
+)
+
+func TestGetPalettes_Pages(t *testing.T) {
+	first := db.PaletteDocument{ID: primitive.NewObjectID(), Palette: palette.Palette{Colours: []colour.Colour{coral.Colour}}}
+	var queries []db.PaletteQuery
+	h := newHandle(&fakeService{listPalettes: func(q db.PaletteQuery) (*db.PalettePage, error) {
+		queries = append(queries, q)
+		if q.Cursor == "" {
+			return &db.PalettePage{Palettes: []db.PaletteDocument{first}, NextCursor: first.ID.Hex()}, nil
+		}
+		return &db.PalettePage{Palettes: []db.PaletteDocument{}}, nil
+	}})
+
+	w := serve(h, http.MethodGet, "/palettes?source=test&harmony=triadic&limit=1", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+	var page handler.PalettePage
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Palettes) != 1 || page.Palettes[0].ID != first.ID || page.NextCursor != first.ID.Hex() {
+		t.Errorf("unexpected first page %+v", page)
+	}
+
+	w = serve(h, http.MethodGet, "/palettes?limit=1&cursor="+page.NextCursor, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+	if w.Body.String() != "{\"palettes\":[]}\n" {
+		t.Errorf("expected an empty last page, got %s", w.Body)
+	}
+
+	want := db.PaletteQuery{Source: "test", Harmony: palette.Triadic, Limit: 1}
+	if len(queries) != 2 || queries[0] != want || queries[1].Cursor != first.ID.Hex() {
+		t.Errorf("unexpected queries %+v", queries)
+	}
+}
+
+func TestGetPalettes_BadRequest(t *testing.T) {
+	tests := []struct {
+		Query string
+		Want  string
+	}{
+		{Query: "harmony=rainbow", Want: "rainbow"},
+		{Query: "limit=0x10", Want: "limit"},
+		{Query: "limit=-5", Want: "limit must be between 1 and 1000, got -5"},
+		{Query: "cursor=next", Want: "malformed cursor"},
+	}
+	h := newHandle(&fakeService{})
+	for _, tt := range tests {
+		t.Run(tt.Query, func(t *testing.T) {
+			checkError(t, serve(h, http.MethodGet, "/palettes?"+tt.Query, ""), http.StatusBadRequest, tt.Want)
+		})
+	}
+}
+
+func TestPalette(t *testing.T) {
+	stored := &db.PaletteDocument{ID: primitive.NewObjectID(), Palette: palette.Palette{Colours: []colour.Colour{coral.Colour}}}
+	h := newHandle(&fakeService{
+		palette: func(id primitive.ObjectID) (*db.PaletteDocument, error) {
+			if id != stored.ID {
+				return nil, errors.Wrapf(service.ErrPaletteNotFound, "palette %s", id.Hex())
+			}
+			return stored, nil
+		},
+		createPalette: func(p palette.Palette) (*db.PaletteDocument, error) {
+			switch {
+			case len(p.Colours) == 0:
+				return nil, errors.Wrap(service.ErrInvalidPalette, "a palette needs colours")
+			case p.Name == "broken":
+				return nil, errors.New("mongo is down")
+			}
+			return stored, nil
+		},
+	})
+
+	w := serve(h, http.MethodGet, "/palettes/"+stored.ID.Hex(), "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+
+	w = serve(h, http.MethodPost, "/palettes", `{"colours":["#FF7F50"]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body)
+	}
+	if loc := w.Header().Get("Location"); loc != "/palettes/"+stored.ID.Hex() {
+		t.Errorf("expected the new palette's Location, got %q", loc)
+	}
+
+	missing := primitive.NewObjectID().Hex()
+	checkError(t, serve(h, http.MethodGet, "/palettes/"+missing, ""), http.StatusNotFound, "palette "+missing)
+	checkError(t, serve(h, http.MethodGet, "/palettes/coral", ""), http.StatusNotFound, "not found")
+	checkError(t, serve(h, http.MethodPost, "/palettes", `{"colours":[]}`), http.StatusBadRequest, "a palette needs colours")
+	checkError(t, serve(h, http.MethodPost, "/palettes", ""), http.StatusBadRequest, "request body is empty")
+	checkError(t, serve(h, http.MethodPost, "/palettes", `{"colours":["#FF7F50"],"name":"broken"}`), http.StatusInternalServerError, "internal error")
+
+	w = serve(h, http.MethodPatch, "/palettes/"+stored.ID.Hex(), "")
+	checkError(t, w, http.StatusMethodNotAllowed, "method not allowed")
+	if allow := w.Header().Get("Allow"); allow != "GET, PUT, DELETE" {
+		t.Errorf("expected Allow: GET, PUT, DELETE, got %q", allow)
+	}
+}
@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"fmt"
+	"image"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/hexbot"
+	"hexbot/internal/render"
+	"hexbot/internal/service"
+)
+
+// GetRender draws a PNG of kind swatch, strip, grid or canvas.
+//
+// Swatches, strips and grids draw the colours query parameter, repeated or comma separated, or the stored
+// palette named by palette; size, labels and columns control the layout. A canvas fetches colours from
+// Hexbot with the count, width, height and seed parameters and plots them, size being its longest side;
+// like PostFetch, it answers 503 while the circuit breaker is open and 502 when Hexbot fails.
+func (h *Handle) GetRender(w http.ResponseWriter, r *http.Request, kind string) {
+	q := r.URL.Query()
+	size, err := queryInt(q.Get("size"), "size")
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if kind == "canvas" {
+		opts, err := hexbot.ParseQuery(q)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if opts.Width == 0 {
+			h.writeError(w, http.StatusBadRequest, "a canvas needs a width and height")
+			return
+		}
+		if size < 0 || size > render.MaxCanvasSize {
+			h.writeError(w, http.StatusBadRequest, fmt.Sprintf("size must be between 1 and %d, got %d", render.MaxCanvasSize, size))
+			return
+		}
+		img, err := h.service.Canvas(r.Context(), opts, size)
+		if errors.Cause(err) == hexbot.ErrCircuitOpen {
+			h.writeError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		if service.IsUpstream(err) {
+			h.log.Error("problem fetching canvas colours", err)
+			h.writeError(w, http.StatusBadGateway, "problem fetching colours from hexbot: "+hexbot.ErrorClass(err))
+			return
+		}
+		if err != nil {
+			h.log.Error("problem drawing canvas", err)
+			h.writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		h.writePNG(w, img)
+		return
+	}
+
+	opts, err := renderOptions(q)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	colours, status, err := h.renderColours(r)
+	if err != nil {
+		h.writeError(w, status, err.Error())
+		return
+	}
+
+	var draw func([]colour.Colour, render.Options) (*image.NRGBA, error)
+	switch kind {
+	case "swatch":
+		if len(colours) != 1 {
+			h.writeError(w, http.StatusBadRequest, "a swatch is one colour")
+			return
+		}
+		draw = render.Grid
+	case "strip":
+		draw = render.Strip
+	case "grid":
+		draw = render.Grid
+	default:
+		h.writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	img, err := draw(colours, opts)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.writePNG(w, img)
+}
+
+// renderColours reads the colours to draw from the colours or palette query parameter, returning the
+// status to answer with when they can't be found.
+func (h *Handle) renderColours(r *http.Request) ([]colour.Colour, int, error) {
+	q := r.URL.Query()
+	if id := q.Get("palette"); id != "" {
+		if len(q["colours"]) > 0 {
+			return nil, http.StatusBadRequest, errors.New("give either colours or palette, not both")
+		}
+		oid, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, http.StatusBadRequest, errors.Errorf("palette %q is not a valid id", id)
+		}
+		doc, err := h.service.Palette(r.Context(), oid)
+		if errors.Cause(err) == service.ErrPaletteNotFound {
+			return nil, http.StatusNotFound, err
+		}
+		if err != nil {
+			h.log.Error("problem loading palette to render", err)
+			return nil, http.StatusInternalServerError, errors.New("internal error")
+		}
+		return doc.Colours, 0, nil
+	}
+
+	var colours []colour.Colour
+	for _, v := range q["colours"] {
+		for _, s := range strings.Split(v, ",") {
+			c, err := parseHex(s)
+			if err != nil {
+				return nil, http.StatusBadRequest, errors.Wrap(err, "colours")
+			}
+			colours = append(colours, c)
+		}
+	}
+	if len(colours) == 0 {
+		return nil, http.StatusBadRequest, errors.New("give colours or a palette to draw")
+	}
+	return colours, 0, nil
+}
+
+// GetTimelapse animates the colours saved between the from and to query parameters, RFC 3339 times
+// defaulting to the last day, as a GIF. group is fetch, hour or day, delay a duration such as 500ms, and
+// source, size, labels and columns are as for GetRender.
+func (h *Handle) GetTimelapse(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var opts service.TimelapseOptions
+	var err error
+	if opts.Options, err = renderOptions(q); err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for name, t := range map[string]*time.Time{"from": &opts.From, "to": &opts.To} {
+		if v := q.Get(name); v != "" {
+			if *t, err = time.Parse(time.RFC3339, v); err != nil {
+				h.writeError(w, http.StatusBadRequest, name+" must be an RFC 3339 time, got "+strconv.Quote(v))
+				return
+			}
+		}
+	}
+	if v := q.Get("group"); v != "" {
+		if opts.Group, err = service.ParseGrouping(v); err != nil {
+			h.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if v := q.Get("delay"); v != "" {
+		if opts.Delay, err = time.ParseDuration(v); err != nil {
+			h.writeError(w, http.StatusBadRequest, "delay must be a duration such as 500ms, got "+strconv.Quote(v))
+			return
+		}
+	}
+	opts.Source = q.Get("source")
+
+	g, err := h.service.Timelapse(r.Context(), opts)
+	if errors.Cause(err) == service.ErrNotFound {
+		h.writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Cause(err) == service.ErrInvalidTimelapse {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		h.log.Error("problem making timelapse", err)
+		h.writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	w.Header().Set("Content-Type", "image/gif")
+	if err := render.EncodeGIF(w, g); err != nil {
+		h.log.Warn("problem writing response: " + err.Error())
+	}
+}
+
+// renderOptions reads the size, labels and columns query parameters.
+func renderOptions(q url.Values) (opts render.Options, err error) {
+	if opts.Size, err = queryInt(q.Get("size"), "size"); err != nil {
+		return opts, err
+	}
+	if opts.Columns, err = queryInt(q.Get("columns"), "columns"); err != nil {
+		return opts, err
+	}
+	if v := q.Get("labels"); v != "" {
+		if opts.Labels, err = strconv.ParseBool(v); err != nil {
+			return opts, errors.New("labels must be true or false")
+		}
+	}
+	return opts, nil
+}
+
+func queryInt(v, name string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, errors.Errorf("%s must be a whole number, got %q", name, v)
+	}
+	return i, nil
+}
+
+func (h *Handle) writePNG(w http.ResponseWriter, img image.Image) {
+	w.Header().Set("Content-Type", "image/png")
+	if err := render.Encode(w, img); err != nil {
+		h.log.Warn("problem writing response: " + err.Error())
+	}
+}
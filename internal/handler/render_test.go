@@ -0,0 +1,62 @@
+package handler_test
+
+import (
+	"image"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/hexbot"
+	"hexbot/internal/service"
+)
+
+func TestGetRender_Canvas(t *testing.T) {
+	tests := []struct {
+		Desc   string
+		Err    error
+		Status int
+		Want   string
+	}{
+		{
+			Desc:   "breaker open",
+			Err:    &service.UpstreamError{Err: errors.Wrap(hexbot.ErrCircuitOpen, "problem fetching")},
+			Status: http.StatusServiceUnavailable,
+			Want:   hexbot.ErrCircuitOpen.Error(),
+		},
+		{
+			Desc:   "hexbot failing",
+			Err:    &service.UpstreamError{Err: &hexbot.StatusError{StatusCode: http.StatusInternalServerError, Message: "secret"}},
+			Status: http.StatusBadGateway,
+			Want:   "problem fetching colours from hexbot: server_error",
+		},
+		{
+			Desc:   "drawing failing",
+			Err:    errors.New("secret"),
+			Status: http.StatusInternalServerError,
+			Want:   "internal error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			h := newHandle(&fakeService{canvas: func(hexbot.FetchOptions, int) (*image.NRGBA, error) {
+				return nil, tt.Err
+			}})
+			w := serve(h, http.MethodGet, "/render/canvas.png?count=2&width=10&height=10", "")
+			checkError(t, w, tt.Status, tt.Want)
+			if strings.Contains(w.Body.String(), "secret") {
+				t.Errorf("expected the cause kept out of the response, got %s", w.Body)
+			}
+		})
+	}
+
+	h := newHandle(&fakeService{canvas: func(opts hexbot.FetchOptions, size int) (*image.NRGBA, error) {
+		return image.NewNRGBA(image.Rect(0, 0, opts.Width, opts.Height)), nil
+	}})
+	w := serve(h, http.MethodGet, "/render/canvas.png?count=2&width=10&height=10", "")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" {
+		t.Errorf("expected a PNG, got %d %s: %s", w.Code, w.Header().Get("Content-Type"), w.Body)
+	}
+	checkError(t, serve(h, http.MethodGet, "/render/canvas.png?count=2", ""), http.StatusBadRequest, "a canvas needs a width and height")
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"hexbot/internal/colour"
+)
+
+// Routes returns the HTTP API, logging every request:
+//
+//	GET /colours?from&to&hex&source&name&r&g&b&hue&saturation&lightness&sort&asc&limit&cursor&format (JSON, CSV, NDJSON, PNG or SVG)
+//	GET /colours/stream?hue&source&near&distance&last_event_id (SSE or WebSocket)
+//	GET /colours/{hex}
+//	GET /colours/{hex}/accessibility?against={hex}
+//	POST /fetch
+//	GET, POST /palettes?source&harmony&limit&cursor
+//	GET, PUT, DELETE /palettes/{id}
+//	GET /render/{swatch,strip,grid,canvas}.png
+//	GET /render/timelapse.gif
+//	GET /scheduler/jobs
+//	GET /scheduler/jobs/{name}/runs?limit={n}
+//	POST /scheduler/jobs/{name}/pause
+//	POST /scheduler/jobs/{name}/resume
+//	GET /scheduler/leader
+//	GET /healthz
+//	GET /readyz
+//	GET /debug/status
+//	GET /metrics
+//
+// Errors are answered with an ErrorResponse.
+func (h *Handle) Routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", h.notFound)
+	mux.HandleFunc("/colours", h.colours)
+	mux.HandleFunc("/colours/", h.colour)
+	mux.HandleFunc("/fetch", h.fetch)
+	mux.HandleFunc("/palettes", h.palettes)
+	mux.HandleFunc("/palettes/", h.palettes)
+	mux.HandleFunc("/render/", h.render)
+	mux.HandleFunc("/scheduler/jobs", h.schedulerJobs)
+	mux.HandleFunc("/scheduler/jobs/", h.schedulerJobs)
+	mux.HandleFunc("/scheduler/leader", h.GetLeader)
+	mux.HandleFunc("/healthz", h.get(h.GetHealth))
+	mux.HandleFunc("/readyz", h.get(h.GetReady))
+	mux.HandleFunc("/debug/status", h.get(h.GetStatus))
+	mux.HandleFunc("/metrics", h.get(h.GetMetrics))
+	return h.logRequests(mux)
+}
+
+func (h *Handle) notFound(w http.ResponseWriter, r *http.Request) {
+	h.writeError(w, http.StatusNotFound, "not found")
+}
+
+// colours dispatches requests for /colours.
+func (h *Handle) colours(w http.ResponseWriter, r *http.Request) {
+	if h.allow(w, r, http.MethodGet) {
+		h.GetColours(w, r)
+	}
+}
+
+// get answers GET and HEAD requests with next, and 405 to anything else.
+func (h *Handle) get(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.allow(w, r, http.MethodGet, http.MethodHead) {
+			next(w, r)
+		}
+	}
+}
+
+// fetch dispatches requests for /fetch.
+func (h *Handle) fetch(w http.ResponseWriter, r *http.Request) {
+	if h.allow(w, r, http.MethodPost) {
+		h.PostFetch(w, r)
+	}
+}
+
+// colour dispatches requests under /colours/{hex}/.
+func (h *Handle) colour(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/colours/"), "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "stream":
+		if h.allow(w, r, http.MethodGet) {
+			h.GetStream(w, r)
+		}
+	case len(parts) == 1 && parts[0] != "":
+		if h.allow(w, r, http.MethodGet) {
+			h.GetColour(w, r, parts[0])
+		}
+	case len(parts) == 2 && parts[1] == "accessibility":
+		if h.allow(w, r, http.MethodGet) {
+			h.GetAccessibility(w, r, parts[0])
+		}
+	default:
+		h.writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// render dispatches requests for /render/{kind}.png and /render/timelapse.gif.
+func (h *Handle) render(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/render/")
+	if name != "timelapse.gif" && (!strings.HasSuffix(name, ".png") || strings.Contains(name, "/")) {
+		h.writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if !h.allow(w, r, http.MethodGet) {
+		return
+	}
+	if name == "timelapse.gif" {
+		h.GetTimelapse(w, r)
+		return
+	}
+	h.GetRender(w, r, strings.TrimSuffix(name, ".png"))
+}
+
+// parseHex reads a colour from a path segment or query parameter, where the leading # is optional.
+func parseHex(s string) (colour.Colour, error) {
+	if !strings.HasPrefix(s, "#") {
+		s = "#" + s
+	}
+	return colour.Parse(s)
+}
+
+func (h *Handle) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.log.Warn("problem writing response: " + err.Error())
+	}
+}
+
+// allow answers 405 unless r uses one of methods.
+func (h *Handle) allow(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	h.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	return false
+}
+
+// ErrorResponse is the body of every error answered by the API.
+type ErrorResponse struct {
+	Error struct {
+		// Status repeats the HTTP status code, and Code is its text in snake case, e.g. not_found.
+		Status  int    `json:"status"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		// RequestID matches the X-Request-ID response header and the request's log line.
+		RequestID string `json:"request_id,omitempty"`
+	} `json:"error"`
+}
+
+func (h *Handle) writeError(w http.ResponseWriter, status int, msg string) {
+	var e ErrorResponse
+	e.Error.Status = status
+	e.Error.Code = strings.Replace(strings.ToLower(http.StatusText(status)), " ", "_", -1)
+	e.Error.Message = msg
+	e.Error.RequestID = w.Header().Get(requestIDHeader)
+	h.writeJSON(w, status, e)
+}
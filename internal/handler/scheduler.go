@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/db"
+	"hexbot/internal/leader"
+	"hexbot/internal/scheduler"
+)
+
+// Scheduler is the control surface of the fetch scheduler.
+type Scheduler interface {
+	Jobs() []scheduler.JobStatus
+	Pause(ctx context.Context, name string) error
+	Resume(ctx context.Context, name string) error
+	Runs(ctx context.Context, name string, limit int) ([]db.RunDocument, error)
+}
+
+// WithScheduler serves the scheduler's jobs and runs under /scheduler/.
+func (h *Handle) WithScheduler(s Scheduler) *Handle {
+	h.scheduler = s
+	return h
+}
+
+// Elector reports whether this replica leads the scheduler.
+type Elector interface {
+	Status() leader.Status
+}
+
+// WithElector serves the elector's status at /scheduler/leader.
+func (h *Handle) WithElector(e Elector) *Handle {
+	h.elector = e
+	return h
+}
+
+// GetLeader reports whether this replica holds the scheduler lease.
+func (h *Handle) GetLeader(w http.ResponseWriter, r *http.Request) {
+	if h.elector == nil {
+		h.writeError(w, http.StatusNotFound, "leader election is off")
+		return
+	}
+	if h.allow(w, r, http.MethodGet) {
+		h.writeJSON(w, http.StatusOK, h.elector.Status())
+	}
+}
+
+// schedulerJobs dispatches requests under /scheduler/jobs.
+func (h *Handle) schedulerJobs(w http.ResponseWriter, r *http.Request) {
+	if h.scheduler == nil {
+		h.writeError(w, http.StatusNotFound, "no scheduler is running")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/scheduler/jobs"), "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "":
+		if h.allow(w, r, http.MethodGet) {
+			h.writeJSON(w, http.StatusOK, h.scheduler.Jobs())
+		}
+	case len(parts) == 2 && parts[1] == "runs":
+		if h.allow(w, r, http.MethodGet) {
+			h.GetRuns(w, r, parts[0])
+		}
+	case len(parts) == 2 && (parts[1] == "pause" || parts[1] == "resume"):
+		if h.allow(w, r, http.MethodPost) {
+			h.PostPause(w, r, parts[0], parts[1] == "pause")
+		}
+	default:
+		h.writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// GetRuns lists the named job's runs, most recent first, up to the limit query parameter.
+func (h *Handle) GetRuns(w http.ResponseWriter, r *http.Request, name string) {
+	limit, err := queryInt(r.URL.Query().Get("limit"), "limit")
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	runs, err := h.scheduler.Runs(r.Context(), name, limit)
+	if errors.Cause(err) == scheduler.ErrUnknownJob {
+		h.writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		h.log.Error("problem listing runs", err)
+		h.writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, runs)
+}
+
+// PostPause pauses or resumes the named job, on every replica when the scheduler shares job state.
+func (h *Handle) PostPause(w http.ResponseWriter, r *http.Request, name string, pause bool) {
+	set := h.scheduler.Resume
+	if pause {
+		set = h.scheduler.Pause
+	}
+	err := set(r.Context(), name)
+	if errors.Cause(err) == scheduler.ErrUnknownJob {
+		h.writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		h.log.Error("problem pausing or resuming job", err)
+		h.writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	for _, st := range h.scheduler.Jobs() {
+		if st.Job.Name == name {
+			h.writeJSON(w, http.StatusOK, st)
+			return
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/db"
+	"hexbot/internal/stream"
+	"hexbot/internal/websocket"
+)
+
+const (
+	// DefaultStreamDistance is the ΔE a streamed colour may be from near when distance isn't given.
+	DefaultStreamDistance = 10
+	// MaxReplay is the most missed colours replayed to a subscriber resuming from a Last-Event-ID.
+	MaxReplay = 10000
+
+	heartbeatInterval  = 15 * time.Second
+	streamWriteTimeout = 10 * time.Second
+)
+
+// Stream is the live feed of saved colours.
+type Stream interface {
+	Subscribe(f stream.Filter) *stream.Subscription
+}
+
+// WithStream serves s at /colours/stream, over WebSockets to pages on the API's own host or one of origins.
+func (h *Handle) WithStream(s Stream, origins ...string) *Handle {
+	h.stream, h.streamOrigins = s, origins
+	return h
+}
+
+// streamWriter sends colours to one subscriber over SSE or a WebSocket.
+type streamWriter interface {
+	send(doc db.ColourDocument) error
+	heartbeat() error
+	// end tells the subscriber why the stream is over: stream.ErrSlowConsumer, stream.ErrClosed or
+	// another error.
+	end(err error)
+}
+
+// GetStream follows saved colours as they arrive, over Server-Sent Events or, when the request asks to
+// upgrade, a WebSocket. hue (min:max), source, and near with a ΔE distance filter the colours. A
+// Last-Event-ID header or last_event_id parameter first replays the colours saved since that one, so
+// subscribers cut off for falling behind or by a dropped connection can resume where they left off.
+func (h *Handle) GetStream(w http.ResponseWriter, r *http.Request) {
+	if h.stream == nil {
+		h.writeError(w, http.StatusNotFound, "the live stream is off")
+		return
+	}
+	q := r.URL.Query()
+	f, err := streamFilter(q)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var last *primitive.ObjectID
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = q.Get("last_event_id")
+	}
+	if lastID != "" {
+		id, err := primitive.ObjectIDFromHex(lastID)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, fmt.Sprintf("last event id %q is not a colour id", lastID))
+			return
+		}
+		last = &id
+	}
+
+	// Subscribing before replaying means nothing saved in between is missed; anything seen twice is skipped.
+	sub := h.stream.Subscribe(f)
+	defer sub.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	var sw streamWriter
+	if websocket.IsUpgrade(r) {
+		conn, err := websocket.Upgrade(w, r, h.streamOrigins...)
+		if err != nil {
+			h.log.Warn("problem upgrading to websocket: " + err.Error())
+			return
+		}
+		defer conn.Close(websocket.CloseNormal, "")
+		go func() {
+			conn.Discard()
+			cancel()
+		}()
+		sw = &wsWriter{conn: conn}
+	} else {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			h.writeError(w, http.StatusInternalServerError, "streaming is not supported here")
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "retry: 2000\n\n")
+		flusher.Flush()
+		sw = &sseWriter{w: w, flusher: flusher}
+	}
+
+	if last != nil {
+		if err := h.replay(ctx, sw, f, last); err != nil {
+			h.log.Error("problem replaying missed colours", err)
+			sw.end(err)
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case doc, ok := <-sub.C:
+			if !ok {
+				sw.end(sub.Err())
+				return
+			}
+			if last != nil && !stream.After(doc.ID, *last) {
+				continue
+			}
+			if err := sw.send(doc); err != nil {
+				return
+			}
+			last = &doc.ID
+		case <-heartbeat.C:
+			if err := sw.heartbeat(); err != nil {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// replay sends the colours matching f among the MaxReplay saved after last, moving last along.
+func (h *Handle) replay(ctx context.Context, sw streamWriter, f stream.Filter, last *primitive.ObjectID) error {
+	for seen := 0; seen < MaxReplay; {
+		docs, err := h.service.ColoursSince(ctx, *last, db.MaxLimit)
+		if err != nil {
+			return err
+		}
+		for _, doc := range docs {
+			*last = doc.ID
+			seen++
+			if !f.Match(doc) {
+				continue
+			}
+			if err := sw.send(doc); err != nil {
+				return err
+			}
+		}
+		if len(docs) < db.MaxLimit {
+			return nil
+		}
+	}
+	return nil
+}
+
+// streamFilter reads the hue, source, near and distance query parameters.
+func streamFilter(q url.Values) (f stream.Filter, err error) {
+	f.Source = q.Get("source")
+	if f.Hue, err = queryRange(q.Get("hue"), "hue"); err != nil {
+		return f, err
+	}
+	if v := q.Get("near"); v != "" {
+		c, err := parseHex(v)
+		if err != nil {
+			return f, errors.Wrap(err, "near")
+		}
+		f.Near, f.MaxDistance = &c, DefaultStreamDistance
+	}
+	if v := q.Get("distance"); v != "" {
+		if f.Near == nil {
+			return f, errors.New("distance needs a near colour to measure from")
+		}
+		if f.MaxDistance, err = strconv.ParseFloat(v, 64); err != nil || f.MaxDistance <= 0 {
+			return f, errors.Errorf("distance must be a positive ΔE, got %q", v)
+		}
+	}
+	return f, nil
+}
+
+// sseWriter writes Server-Sent Events, each colour carrying its id for Last-Event-ID.
+type sseWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func (s *sseWriter) send(doc db.ColourDocument) error {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(s.w, "id: %s\nevent: colour\ndata: %s\n\n", doc.ID.Hex(), b); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+func (s *sseWriter) heartbeat() error {
+	if _, err := fmt.Fprint(s.w, ": ping\n\n"); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// end sends an event named lagged, closed or failed, after which EventSource clients reconnect with the
+// id of the last colour they received.
+func (s *sseWriter) end(err error) {
+	event := "failed"
+	switch err {
+	case stream.ErrSlowConsumer:
+		event = "lagged"
+	case stream.ErrClosed:
+		event = "closed"
+	}
+	b, _ := json.Marshal(map[string]string{"message": fmt.Sprint(err)})
+	fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, b)
+	s.flusher.Flush()
+}
+
+// wsWriter sends each colour as a JSON text message.
+type wsWriter struct {
+	conn *websocket.Conn
+}
+
+func (s *wsWriter) send(doc db.ColourDocument) error {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return s.conn.WriteText(b, streamWriteTimeout)
+}
+
+func (s *wsWriter) heartbeat() error {
+	return s.conn.Ping(streamWriteTimeout)
+}
+
+// end closes with 1013 try again later when the subscriber fell behind, so that it reconnects with
+// last_event_id, and 1001 going away when the server shuts down.
+func (s *wsWriter) end(err error) {
+	switch err {
+	case stream.ErrSlowConsumer:
+		s.conn.Close(websocket.CloseTryAgainLater, "fell too far behind, reconnect with last_event_id")
+	case stream.ErrClosed:
+		s.conn.Close(websocket.CloseGoingAway, "server shutting down")
+	case nil:
+		s.conn.Close(websocket.CloseNormal, "")
+	default:
+		s.conn.Close(websocket.CloseTryAgainLater, "problem reading colours")
+	}
+}
@@ -0,0 +1,98 @@
+package handler_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/db"
+	"hexbot/internal/stream"
+)
+
+func TestGetStream_ResumesFromLastEventID(t *testing.T) {
+	docs := make([]db.ColourDocument, 5)
+	for i := range docs {
+		docs[i] = coral
+		docs[i].ID = primitive.NewObjectID()
+	}
+	docs[2].Source = "other"
+	last, missed, live := docs[0], docs[1:4], docs[4]
+
+	var since []primitive.ObjectID
+	b := stream.NewBroadcaster(0)
+	h := newHandle(&fakeService{coloursSince: func(id primitive.ObjectID, limit int) ([]db.ColourDocument, error) {
+		since = append(since, id)
+		return missed, nil
+	}}).WithStream(b)
+	srv := httptest.NewServer(h.Routes())
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	r, err := http.NewRequest(http.MethodGet, srv.URL+"/colours/stream?source=test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Last-Event-ID", last.ID.Hex())
+	res, err := http.DefaultClient.Do(r.WithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %d %s", res.StatusCode, ct)
+	}
+
+	// Publish the last replayed colour again, as happens when it is saved between subscribing and
+	// replaying, then a new one.
+	for b.Subscribers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	b.Publish(missed[2], live)
+
+	var ids []string
+	lines := bufio.NewScanner(res.Body)
+	for len(ids) < 3 && lines.Scan() {
+		if id := strings.TrimPrefix(lines.Text(), "id: "); id != lines.Text() {
+			ids = append(ids, id)
+		}
+	}
+	want := []string{missed[0].ID.Hex(), missed[2].ID.Hex(), live.ID.Hex()}
+	if strings.Join(ids, ",") != strings.Join(want, ",") {
+		t.Errorf("expected the missed colours matching the filter then the live one, once each: %v, got %v", want, ids)
+	}
+	if len(since) != 1 || since[0] != last.ID {
+		t.Errorf("expected a replay since %s, got %v", last.ID.Hex(), since)
+	}
+
+	b.Close()
+	var ended bool
+	for lines.Scan() {
+		if lines.Text() == "event: closed" {
+			ended = true
+			break
+		}
+	}
+	if !ended {
+		t.Error("expected a closed event when the stream shut down")
+	}
+}
+
+func TestGetStream_BadRequest(t *testing.T) {
+	h := newHandle(&fakeService{})
+	checkError(t, serve(h, http.MethodGet, "/colours/stream", ""), http.StatusNotFound, "the live stream is off")
+
+	h.WithStream(stream.NewBroadcaster(0))
+	checkError(t, serve(h, http.MethodGet, "/colours/stream", "", "Last-Event-ID", "42"),
+		http.StatusBadRequest, `last event id "42" is not a colour id`)
+	checkError(t, serve(h, http.MethodGet, "/colours/stream?last_event_id=42", ""),
+		http.StatusBadRequest, `last event id "42" is not a colour id`)
+	checkError(t, serve(h, http.MethodGet, "/colours/stream?distance=5", ""),
+		http.StatusBadRequest, "distance needs a near colour")
+}
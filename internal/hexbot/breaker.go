@@ -0,0 +1,146 @@
+package hexbot
+
+import (
+	"sync"
+	"time"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/pkg/errors"
+)
+
+// BreakerState is the state of a circuit breaker.
+type BreakerState int
+
+const (
+	// BreakerClosed lets every call through.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects every call until the cool down has passed.
+	BreakerOpen
+	// BreakerHalfOpen lets a single probe through to decide whether to close again.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// ErrCircuitOpen is returned without calling upstream while the breaker is open.
+var ErrCircuitOpen = errors.New("hexbot circuit breaker is open")
+
+// BreakerConfig configures a Breaker.
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the breaker.
+	FailureThreshold int
+	// CoolDown is how long the breaker stays open before allowing a probe.
+	CoolDown time.Duration
+}
+
+// Breaker is a circuit breaker that opens after repeated failures and probes upstream
+// with a single call once its cool down has passed.
+type Breaker struct {
+	log *logging.Logger
+	cfg BreakerConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewBreaker returns a closed Breaker.
+func NewBreaker(log *logging.Logger, cfg BreakerConfig) *Breaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.CoolDown <= 0 {
+		cfg.CoolDown = 30 * time.Second
+	}
+	return &Breaker{log: log, cfg: cfg, now: time.Now}
+}
+
+// State reports the current state, moving from open to half-open if the cool down has passed.
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance()
+	return b.state
+}
+
+// Allow reports whether a call may go ahead. Every allowed call must be followed by Record.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance()
+
+	switch b.state {
+	case BreakerOpen:
+		return ErrCircuitOpen
+	case BreakerHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Record reports the outcome of a call let through by Allow.
+func (b *Breaker) Record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if success {
+		b.failures = 0
+		b.probing = false
+		b.transition(BreakerClosed)
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.cfg.FailureThreshold {
+		b.probing = false
+		b.openedAt = b.now()
+		b.transition(BreakerOpen)
+	}
+}
+
+// Release gives back a call let through by Allow without recording an outcome,
+// for calls abandoned by the caller.
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// advance moves an open breaker to half-open once its cool down has passed. b.mu must be held.
+func (b *Breaker) advance() {
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cfg.CoolDown {
+		b.transition(BreakerHalfOpen)
+	}
+}
+
+// transition changes state and logs it. b.mu must be held.
+func (b *Breaker) transition(to BreakerState) {
+	if b.state == to {
+		return
+	}
+	from := b.state
+	b.state = to
+
+	msg := "hexbot circuit breaker " + from.String() + " -> " + to.String()
+	if to == BreakerOpen {
+		b.log.Warn(msg)
+		return
+	}
+	b.log.Info(msg)
+}
@@ -0,0 +1,206 @@
+package hexbot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+)
+
+const (
+	// DefaultBaseURL is the public noopschallenge Hexbot API.
+	DefaultBaseURL = "https://api.noopschallenge.com"
+	// DefaultTimeout bounds a single request to Hexbot when no timeout is configured.
+	DefaultTimeout = 10 * time.Second
+	// DefaultUserAgent is sent with every request unless overridden.
+	DefaultUserAgent = "hexbot-client/1.0"
+
+	hexbotPath = "/hexbot"
+)
+
+// Config holds everything needed to talk to a Hexbot compatible endpoint.
+type Config struct {
+	BaseURL    string
+	Timeout    time.Duration
+	UserAgent  string
+	HTTPClient *http.Client
+}
+
+// Client fetches colours from the Hexbot API over HTTP.
+type Client struct {
+	baseURL    string
+	userAgent  string
+	httpClient *http.Client
+}
+
+// Colour is a single colour as returned by Hexbot.
+// Coordinates is only set when the request carried a width and height.
+type Colour struct {
+	Value       colour.Colour `json:"value"`
+	Coordinates *Coordinates  `json:"coordinates,omitempty"`
+}
+
+// Response is the body returned by the Hexbot endpoint.
+type Response struct {
+	Colors []Colour `json:"colors"`
+}
+
+// StatusError is returned when Hexbot answers with a non 2xx status code.
+type StatusError struct {
+	StatusCode int
+	Message    string
+	// RetryAfter is how long the server asked us to wait, taken from the Retry-After header.
+	RetryAfter time.Duration
+}
+
+// DecodeError is returned when the Hexbot response body is not the JSON we expect.
+type DecodeError struct {
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return "problem decoding hexbot response: " + e.Err.Error()
+}
+
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("hexbot responded with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("hexbot responded with status %d: %s", e.StatusCode, e.Message)
+}
+
+// NewClient builds a Client from cfg, filling in defaults for anything left empty.
+func NewClient(cfg Config) (*Client, error) {
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
+		return nil, errors.Errorf("hexbot base url %q must be http or https", cfg.BaseURL)
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		timeout := cfg.Timeout
+		if timeout <= 0 {
+			timeout = DefaultTimeout
+		}
+		httpClient = &http.Client{Timeout: timeout}
+	}
+
+	userAgent := cfg.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+
+	return &Client{
+		baseURL:    baseURL,
+		userAgent:  userAgent,
+		httpClient: httpClient,
+	}, nil
+}
+
+// GetHexString fetches a single colour and returns its hex value, e.g. "#A1B2C3".
+func (c *Client) GetHexString(ctx context.Context) (string, error) {
+	colours, err := c.Fetch(ctx, FetchOptions{})
+	if err != nil {
+		return "", err
+	}
+	return colours[0].Value.Hex(), nil
+}
+
+// Fetch queries Hexbot with opts and returns every colour in the response.
+func (c *Client) Fetch(ctx context.Context, opts FetchOptions) ([]Colour, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid hexbot fetch options")
+	}
+
+	res, err := c.get(ctx, opts.Query())
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Colors) == 0 {
+		return nil, errors.New("hexbot response contained no colours")
+	}
+	return res.Colors, nil
+}
+
+// Ping checks Hexbot answers a request for a single colour.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.get(ctx, nil)
+	return err
+}
+
+func (c *Client) get(ctx context.Context, query url.Values) (*Response, error) {
+	u := c.baseURL + hexbotPath
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem building hexbot request")
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem getting hex from hexbot")
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem reading body of http response from hexbot")
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &StatusError{
+			StatusCode: resp.StatusCode,
+			Message:    errorMessage(body),
+			RetryAfter: retryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+
+	res := &Response{}
+	if err := json.Unmarshal(body, res); err != nil {
+		return nil, &DecodeError{Err: err}
+	}
+	return res, nil
+}
+
+// errorMessage pulls the message out of a Hexbot error body, falling back to the raw text.
+func errorMessage(body []byte) string {
+	var e struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &e); err == nil && e.Message != "" {
+		return e.Message
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// retryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+func retryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,249 @@
+package hexbot_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/pkg/errors"
+
+	"hexbot/internal/hexbot"
+)
+
+func TestClient_GetHexString(t *testing.T) {
+	tests := []struct {
+		Desc       string
+		Status     int
+		Body       string
+		Want       string
+		WantStatus int
+		WantErr    bool
+	}{
+		{Desc: "decodes the first colour", Status: http.StatusOK, Body: `{"colors":[{"value":"#A1B2C3"}]}`, Want: "#A1B2C3"},
+		{Desc: "empty colour list", Status: http.StatusOK, Body: `{"colors":[]}`, WantErr: true},
+		{Desc: "malformed body", Status: http.StatusOK, Body: `{"colors":`, WantErr: true},
+		{Desc: "malformed colour", Status: http.StatusOK, Body: `{"colors":[{"value":"#GGGGGG"}]}`, WantErr: true},
+		{Desc: "upstream error", Status: http.StatusBadRequest, Body: `{"message":"bad count"}`, WantStatus: http.StatusBadRequest, WantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/hexbot" {
+					t.Errorf("unexpected path %q", r.URL.Path)
+				}
+				if ua := r.Header.Get("User-Agent"); ua != "test-agent" {
+					t.Errorf("unexpected user agent %q", ua)
+				}
+				w.WriteHeader(tt.Status)
+				w.Write([]byte(tt.Body))
+			}))
+			defer srv.Close()
+
+			c, err := hexbot.NewClient(hexbot.Config{BaseURL: srv.URL, UserAgent: "test-agent"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := c.GetHexString(context.Background())
+			if (err != nil) != tt.WantErr {
+				t.Fatalf("GetHexString() error = %v, wantErr %v", err, tt.WantErr)
+			}
+			if got != tt.Want {
+				t.Errorf("GetHexString() = %q, want %q", got, tt.Want)
+			}
+			if tt.WantStatus != 0 {
+				se, ok := errors.Cause(err).(*hexbot.StatusError)
+				if !ok || se.StatusCode != tt.WantStatus {
+					t.Errorf("expected StatusError %d, got %v", tt.WantStatus, err)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_GetHexString_ContextCancelled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	c, err := hexbot.NewClient(hexbot.Config{BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.GetHexString(ctx); err == nil {
+		t.Fatal("expected an error once the context is cancelled")
+	}
+}
+
+func TestClient_Ping(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"colors":[{"value":"#A1B2C3"}]}`))
+	}))
+	defer srv.Close()
+
+	c, err := hexbot.NewClient(hexbot.Config{BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Ping(context.Background()); err != nil {
+		t.Errorf("expected ping to succeed, got %v", err)
+	}
+
+	status = http.StatusServiceUnavailable
+	b := hexbot.NewBreaker(logging.NopLogger, hexbot.BreakerConfig{FailureThreshold: 1})
+	r := hexbot.NewResilient(c, b, hexbot.RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond})
+	if err := r.Ping(context.Background()); err == nil {
+		t.Error("expected ping to fail while hexbot answers 503")
+	}
+	if b.State() != hexbot.BreakerClosed {
+		t.Errorf("expected a failed ping to leave the breaker closed, got %s", b.State())
+	}
+	if err := hexbot.NewResilient(hexbot.NewGenerator(1), b, hexbot.RetryConfig{}).Ping(context.Background()); err != nil {
+		t.Errorf("expected the offline generator to always answer, got %v", err)
+	}
+}
+
+func TestResilient_Reachable(t *testing.T) {
+	var requests int32
+	status := int32(http.StatusOK)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+		w.Write([]byte(`{"colors":[{"value":"#A1B2C3"}]}`))
+	}))
+	defer srv.Close()
+
+	c, err := hexbot.NewClient(hexbot.Config{BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := hexbot.NewBreaker(logging.NopLogger, hexbot.BreakerConfig{})
+	r := hexbot.NewResilient(c, b, hexbot.RetryConfig{MaxAttempts: 1})
+	ctx := context.Background()
+
+	if err := r.Reachable(ctx, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reachable(ctx, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("expected the second check to reuse the first, got %d requests", n)
+	}
+
+	atomic.StoreInt32(&status, http.StatusServiceUnavailable)
+	if _, err := r.Fetch(ctx, hexbot.FetchOptions{}); err == nil {
+		t.Fatal("expected the fetch to fail")
+	}
+	if err := r.Reachable(ctx, time.Minute); err == nil {
+		t.Error("expected the failed fetch to make hexbot unreachable")
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expected the check to reuse the fetch, got %d requests", n)
+	}
+
+	atomic.StoreInt32(&status, http.StatusBadRequest)
+	if err := r.Reachable(ctx, 0); err != nil {
+		t.Errorf("expected a 4xx to count as reachable, got %v", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 3 {
+		t.Errorf("expected an outcome older than maxAge to be checked again, got %d requests", n)
+	}
+}
+
+func TestNewClient_InvalidURL(t *testing.T) {
+	if _, err := hexbot.NewClient(hexbot.Config{BaseURL: "ftp://example.com"}); err == nil {
+		t.Fatal("expected an error for a non http base url")
+	}
+}
+
+func TestClient_Fetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("count") != "2" || q.Get("width") != "100" || q.Get("height") != "50" || q.Get("seed") != "FF7F50,FFD700" {
+			t.Errorf("unexpected query %q", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"colors":[{"value":"#FF7F50","coordinates":{"x":10,"y":20}},{"value":"#FFD700","coordinates":{"x":99,"y":0}}]}`))
+	}))
+	defer srv.Close()
+
+	c, err := hexbot.NewClient(hexbot.Config{BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.Fetch(context.Background(), hexbot.FetchOptions{Count: 2, Width: 100, Height: 50, Seed: []string{"#ff7f50", "FFD700"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 colours, got %d", len(got))
+	}
+	if got[0].Value.Hex() != "#FF7F50" || got[0].Coordinates == nil || got[0].Coordinates.X != 10 || got[0].Coordinates.Y != 20 {
+		t.Errorf("unexpected first colour %+v", got[0])
+	}
+}
+
+func TestFetchOptions_Validate(t *testing.T) {
+	tests := []struct {
+		Desc    string
+		Opts    hexbot.FetchOptions
+		WantErr bool
+	}{
+		{Desc: "zero value", Opts: hexbot.FetchOptions{}},
+		{Desc: "max count", Opts: hexbot.FetchOptions{Count: hexbot.MaxCount}},
+		{Desc: "count too large", Opts: hexbot.FetchOptions{Count: hexbot.MaxCount + 1}, WantErr: true},
+		{Desc: "negative count", Opts: hexbot.FetchOptions{Count: -1}, WantErr: true},
+		{Desc: "width without height", Opts: hexbot.FetchOptions{Width: 100}, WantErr: true},
+		{Desc: "width too small", Opts: hexbot.FetchOptions{Width: 5, Height: 100}, WantErr: true},
+		{Desc: "valid seed", Opts: hexbot.FetchOptions{Seed: []string{"#FFFFFF", "000000"}}},
+		{Desc: "malformed seed", Opts: hexbot.FetchOptions{Seed: []string{"FFF"}}, WantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			if err := tt.Opts.Validate(); (err != nil) != tt.WantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.WantErr)
+			}
+		})
+	}
+}
+
+func TestClient_RetryAfter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	c, err := hexbot.NewClient(hexbot.Config{BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Fetch(context.Background(), hexbot.FetchOptions{})
+	se, ok := errors.Cause(err).(*hexbot.StatusError)
+	if !ok {
+		t.Fatalf("expected a StatusError, got %v", err)
+	}
+	if se.RetryAfter != 2*time.Second {
+		t.Errorf("expected Retry-After of 2s, got %s", se.RetryAfter)
+	}
+	if !hexbot.Retryable(err) {
+		t.Error("expected 429 to be retryable")
+	}
+}
@@ -0,0 +1,71 @@
+package hexbot
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+)
+
+// Generator produces Hexbot style colours locally, without any network access.
+// Two generators built with the same seed return the same sequence of colours.
+type Generator struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// NewGenerator returns a Generator whose output is fully determined by seed.
+func NewGenerator(seed int64) *Generator {
+	return &Generator{rnd: rand.New(rand.NewSource(seed))}
+}
+
+// Fetch mirrors Client.Fetch: it returns opts.Count colours, drawn from opts.Seed when given,
+// with coordinates inside opts.Width by opts.Height when both are set.
+func (g *Generator) Fetch(ctx context.Context, opts FetchOptions) ([]Colour, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid hexbot fetch options")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, errors.Wrap(err, "problem generating colours")
+	}
+
+	count := opts.Count
+	if count == 0 {
+		count = 1
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	seeds := make([]colour.Colour, len(opts.Seed))
+	for i, s := range opts.Seed {
+		seeds[i] = colour.MustParse("#" + strings.TrimPrefix(s, "#"))
+	}
+
+	colours := make([]Colour, count)
+	for i := range colours {
+		if len(seeds) > 0 {
+			colours[i].Value = seeds[g.rnd.Intn(len(seeds))]
+		} else {
+			v := g.rnd.Intn(1 << 24)
+			colours[i].Value = colour.RGB(uint8(v>>16), uint8(v>>8), uint8(v))
+		}
+		if opts.Width > 0 && opts.Height > 0 {
+			colours[i].Coordinates = &Coordinates{X: g.rnd.Intn(opts.Width), Y: g.rnd.Intn(opts.Height)}
+		}
+	}
+	return colours, nil
+}
+
+// GetHexString returns a single generated colour.
+func (g *Generator) GetHexString(ctx context.Context) (string, error) {
+	colours, err := g.Fetch(ctx, FetchOptions{})
+	if err != nil {
+		return "", err
+	}
+	return colours[0].Value.Hex(), nil
+}
@@ -0,0 +1,57 @@
+package hexbot_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"hexbot/internal/hexbot"
+)
+
+func TestGenerator_Deterministic(t *testing.T) {
+	opts := hexbot.FetchOptions{Count: 50, Width: 100, Height: 20}
+
+	a, err := hexbot.NewGenerator(42).Fetch(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := hexbot.NewGenerator(42).Fetch(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("expected the same seed to produce the same colours")
+	}
+
+	c, err := hexbot.NewGenerator(43).Fetch(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(a, c) {
+		t.Fatal("expected a different seed to produce different colours")
+	}
+
+	for _, colour := range a {
+		if colour.Value.A() != 0xFF {
+			t.Errorf("expected an opaque colour, got %s", colour.Value)
+		}
+		if colour.Coordinates == nil || colour.Coordinates.X >= 100 || colour.Coordinates.Y >= 20 {
+			t.Errorf("coordinates out of range: %+v", colour.Coordinates)
+		}
+	}
+}
+
+func TestGenerator_SeedPalette(t *testing.T) {
+	colours, err := hexbot.NewGenerator(1).Fetch(context.Background(), hexbot.FetchOptions{Count: 100, Seed: []string{"ff7f50", "#FFD700"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range colours {
+		if h := c.Value.Hex(); h != "#FF7F50" && h != "#FFD700" {
+			t.Fatalf("colour %s is not from the seed palette", h)
+		}
+		if c.Coordinates != nil {
+			t.Fatal("expected no coordinates without a width and height")
+		}
+	}
+}
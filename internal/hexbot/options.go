@@ -0,0 +1,121 @@
+package hexbot
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Limits enforced by the upstream Hexbot API.
+const (
+	MaxCount     = 1000
+	MinDimension = 10
+	MaxDimension = 100000
+	MaxSeeds     = 10
+)
+
+var seedPattern = regexp.MustCompile(`^[0-9A-Fa-f]{6}$`)
+
+// FetchOptions are the query parameters understood by Hexbot.
+// The zero value asks for a single random colour.
+type FetchOptions struct {
+	// Count is the number of colours to return, 1 to 1000. Zero means one.
+	Count int
+	// Width and Height, when both set, make Hexbot attach x/y coordinates to every colour.
+	Width  int
+	Height int
+	// Seed restricts the colours to this palette, e.g. "FF7F50" or "#FF7F50".
+	Seed []string
+}
+
+// Coordinates is the position Hexbot assigned to a colour when queried with a width and height.
+type Coordinates struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Validate checks the options against the limits Hexbot enforces.
+func (o FetchOptions) Validate() error {
+	if o.Count < 0 || o.Count > MaxCount {
+		return errors.Errorf("count must be between 1 and %d, got %d", MaxCount, o.Count)
+	}
+	if (o.Width == 0) != (o.Height == 0) {
+		return errors.New("width and height must be given together")
+	}
+	if o.Width != 0 {
+		if o.Width < MinDimension || o.Width > MaxDimension {
+			return errors.Errorf("width must be between %d and %d, got %d", MinDimension, MaxDimension, o.Width)
+		}
+		if o.Height < MinDimension || o.Height > MaxDimension {
+			return errors.Errorf("height must be between %d and %d, got %d", MinDimension, MaxDimension, o.Height)
+		}
+	}
+	if len(o.Seed) > MaxSeeds {
+		return errors.Errorf("at most %d seed colours are allowed, got %d", MaxSeeds, len(o.Seed))
+	}
+	for _, s := range o.Seed {
+		if !seedPattern.MatchString(strings.TrimPrefix(s, "#")) {
+			return errors.Errorf("seed colour %q is not a six digit hex value", s)
+		}
+	}
+	return nil
+}
+
+// Query encodes the options as Hexbot query parameters.
+func (o FetchOptions) Query() url.Values {
+	q := url.Values{}
+	if o.Count > 0 {
+		q.Set("count", strconv.Itoa(o.Count))
+	}
+	if o.Width > 0 && o.Height > 0 {
+		q.Set("width", strconv.Itoa(o.Width))
+		q.Set("height", strconv.Itoa(o.Height))
+	}
+	if len(o.Seed) > 0 {
+		seeds := make([]string, len(o.Seed))
+		for i, s := range o.Seed {
+			seeds[i] = strings.ToUpper(strings.TrimPrefix(s, "#"))
+		}
+		q.Set("seed", strings.Join(seeds, ","))
+	}
+	return q
+}
+
+// ParseQuery is the inverse of Query: it reads Hexbot query parameters into FetchOptions and validates them.
+func ParseQuery(q url.Values) (FetchOptions, error) {
+	var opts FetchOptions
+	var err error
+
+	if opts.Count, err = intParam(q, "count"); err != nil {
+		return opts, err
+	}
+	if opts.Width, err = intParam(q, "width"); err != nil {
+		return opts, err
+	}
+	if opts.Height, err = intParam(q, "height"); err != nil {
+		return opts, err
+	}
+	if seed := q.Get("seed"); seed != "" {
+		opts.Seed = strings.Split(seed, ",")
+	}
+
+	if q.Get("count") != "" && opts.Count == 0 {
+		return opts, errors.Errorf("count must be between 1 and %d, got 0", MaxCount)
+	}
+	return opts, opts.Validate()
+}
+
+func intParam(q url.Values, key string) (int, error) {
+	v := q.Get(key)
+	if v == "" {
+		return 0, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, errors.Errorf("%s must be a whole number, got %q", key, v)
+	}
+	return i, nil
+}
@@ -0,0 +1,279 @@
+package hexbot
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Fetcher is anything that returns Hexbot colours, such as Client or Generator.
+type Fetcher interface {
+	Fetch(ctx context.Context, opts FetchOptions) ([]Colour, error)
+}
+
+// RetryConfig configures a Resilient fetcher.
+type RetryConfig struct {
+	// MaxAttempts is the total number of attempts per Fetch, including the first.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry; it doubles on every attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff between two attempts.
+	MaxDelay time.Duration
+}
+
+// Resilient decorates a Fetcher with retries, jittered exponential backoff and a circuit breaker.
+type Resilient struct {
+	next     Fetcher
+	breaker  *Breaker
+	cfg      RetryConfig
+	observer Observer
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+
+	// lastAt and lastErr are when upstream last answered or failed to, and how; see Reachable.
+	lastMu  sync.Mutex
+	lastAt  time.Time
+	lastErr error
+	// pingMu lets one Reachable at a time ping, so that concurrent health checks share its outcome.
+	pingMu sync.Mutex
+}
+
+// NewResilient wraps next. Every attempt goes through breaker.
+func NewResilient(next Fetcher, breaker *Breaker, cfg RetryConfig) *Resilient {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.BaseDelay <= 0 {
+		cfg.BaseDelay = 200 * time.Millisecond
+	}
+	if cfg.MaxDelay < cfg.BaseDelay {
+		cfg.MaxDelay = 10 * cfg.BaseDelay
+	}
+	return &Resilient{
+		next:    next,
+		breaker: breaker,
+		cfg:     cfg,
+		rnd:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Observer is told about every call made upstream, such as a metrics.Metrics.
+type Observer interface {
+	ObserveHexbotRequest(d time.Duration, err error)
+}
+
+// WithObserver tells o about every attempt and ping, each being one request to Hexbot.
+func (r *Resilient) WithObserver(o Observer) *Resilient {
+	r.observer = o
+	return r
+}
+
+// observe tells the observer how a call that started at start went.
+func (r *Resilient) observe(start time.Time, err error) {
+	if r.observer != nil {
+		r.observer.ObserveHexbotRequest(time.Since(start), err)
+	}
+}
+
+// Breaker returns the circuit breaker guarding upstream.
+func (r *Resilient) Breaker() *Breaker {
+	return r.breaker
+}
+
+// Pinger is a Fetcher that can check upstream is reachable, such as Client.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping checks the wrapped Fetcher's upstream answers, going round the retries and the breaker so that
+// health checks neither wait out backoff nor count towards opening it. Fetchers with nothing to reach,
+// such as Generator, always answer.
+func (r *Resilient) Ping(ctx context.Context) error {
+	p, ok := r.next.(Pinger)
+	if !ok {
+		return nil
+	}
+	start := time.Now()
+	err := p.Ping(ctx)
+	r.observe(start, err)
+	if ctx.Err() == nil {
+		r.remember(err)
+	}
+	return err
+}
+
+// Reachable reports how upstream answered the most recent fetch attempt or ping when it was made within
+// maxAge, and pings it otherwise, so that health checks call upstream only when nothing else has lately.
+// Requests upstream turned down count as answered.
+func (r *Resilient) Reachable(ctx context.Context, maxAge time.Duration) error {
+	r.pingMu.Lock()
+	defer r.pingMu.Unlock()
+
+	r.lastMu.Lock()
+	at, err := r.lastAt, r.lastErr
+	r.lastMu.Unlock()
+	if !at.IsZero() && time.Since(at) < maxAge {
+		return err
+	}
+	if err := r.Ping(ctx); !callerError(err) {
+		return err
+	}
+	return nil
+}
+
+// remember keeps the outcome of a request to upstream for Reachable.
+func (r *Resilient) remember(err error) {
+	if callerError(err) {
+		err = nil
+	}
+	r.lastMu.Lock()
+	defer r.lastMu.Unlock()
+	r.lastAt, r.lastErr = time.Now(), err
+}
+
+// Fetch calls the wrapped Fetcher, retrying retryable errors until MaxAttempts is reached,
+// the breaker opens or ctx is done.
+func (r *Resilient) Fetch(ctx context.Context, opts FetchOptions) ([]Colour, error) {
+	// Bad options are the caller's fault, so they are turned away before they can reach the breaker.
+	if err := opts.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid hexbot fetch options")
+	}
+
+	var err error
+	for attempt := 0; attempt < r.cfg.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			if werr := r.wait(ctx, attempt, err); werr != nil {
+				return nil, errors.Wrapf(err, "gave up after %d attempts", attempt)
+			}
+		}
+
+		if berr := r.breaker.Allow(); berr != nil {
+			if err == nil {
+				return nil, berr
+			}
+			return nil, errors.Wrapf(berr, "gave up after %d attempts: %v", attempt, err)
+		}
+
+		var colours []Colour
+		start := time.Now()
+		colours, err = r.next.Fetch(ctx, opts)
+		r.observe(start, err)
+		if err != nil && ctx.Err() != nil {
+			// The caller gave up, which says nothing about the health of upstream.
+			r.breaker.Release()
+			return nil, err
+		}
+		r.remember(err)
+
+		switch {
+		case err == nil:
+			r.breaker.Record(true)
+			return colours, nil
+		case callerError(err):
+			// Upstream answered, so it is up, but turned the request down, which says nothing about
+			// whether it is healthy either.
+			r.breaker.Release()
+			return nil, err
+		}
+		r.breaker.Record(false)
+		if !Retryable(err) {
+			return nil, err
+		}
+	}
+	return nil, errors.Wrapf(err, "gave up after %d attempts", r.cfg.MaxAttempts)
+}
+
+// wait sleeps before the given attempt, honouring any Retry-After from the previous error up to
+// MaxDelay, so that a server asking for hours can't hold the caller that long.
+func (r *Resilient) wait(ctx context.Context, attempt int, prev error) error {
+	delay := r.backoff(attempt)
+	if se, ok := errors.Cause(prev).(*StatusError); ok && se.RetryAfter > delay {
+		delay = se.RetryAfter
+		if delay > r.cfg.MaxDelay {
+			delay = r.cfg.MaxDelay
+		}
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// backoff returns a delay drawn uniformly from [0, min(MaxDelay, BaseDelay*2^(attempt-1))].
+func (r *Resilient) backoff(attempt int) time.Duration {
+	ceiling := r.cfg.BaseDelay
+	for i := 1; i < attempt && ceiling < r.cfg.MaxDelay; i++ {
+		ceiling *= 2
+	}
+	if ceiling > r.cfg.MaxDelay {
+		ceiling = r.cfg.MaxDelay
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return time.Duration(r.rnd.Int63n(int64(ceiling) + 1))
+}
+
+// ErrorClass names the kind of failure err is, for metrics: ok for nil, then timeout, canceled,
+// rate_limited, client_error and server_error for 4xx and 5xx responses, decode, network or other.
+func ErrorClass(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	switch e := errors.Cause(err).(type) {
+	case *StatusError:
+		switch {
+		case e.StatusCode == http.StatusTooManyRequests:
+			return "rate_limited"
+		case e.StatusCode >= 500:
+			return "server_error"
+		}
+		return "client_error"
+	case *DecodeError:
+		return "decode"
+	case net.Error:
+		if e.Timeout() {
+			return "timeout"
+		}
+		return "network"
+	}
+	switch errors.Cause(err) {
+	case context.DeadlineExceeded:
+		return "timeout"
+	case context.Canceled:
+		return "canceled"
+	}
+	return "other"
+}
+
+// callerError reports whether err is a 4xx response other than 429, Hexbot turning down the request
+// rather than failing to serve it.
+func callerError(err error) bool {
+	se, ok := errors.Cause(err).(*StatusError)
+	return ok && se.StatusCode >= 400 && se.StatusCode < 500 && se.StatusCode != http.StatusTooManyRequests
+}
+
+// Retryable reports whether err is worth retrying: 5xx and 429 responses, timeouts and
+// other transport failures. Other 4xx responses and undecodable bodies are fatal.
+func Retryable(err error) bool {
+	switch e := errors.Cause(err).(type) {
+	case *StatusError:
+		return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+	case *DecodeError:
+		return false
+	case net.Error:
+		return true
+	}
+	return errors.Cause(err) == context.DeadlineExceeded
+}
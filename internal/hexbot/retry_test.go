@@ -0,0 +1,240 @@
+package hexbot_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/hexbot"
+)
+
+// scriptedFetcher returns the queued errors in order, then succeeds.
+type scriptedFetcher struct {
+	errs  []error
+	calls int
+}
+
+func (f *scriptedFetcher) Fetch(ctx context.Context, opts hexbot.FetchOptions) ([]hexbot.Colour, error) {
+	f.calls++
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return nil, err
+	}
+	return []hexbot.Colour{{Value: colour.RGB(255, 255, 255)}}, nil
+}
+
+func TestResilient_Fetch(t *testing.T) {
+	unavailable := &hexbot.StatusError{StatusCode: http.StatusServiceUnavailable}
+	tests := []struct {
+		Desc      string
+		Errs      []error
+		WantCalls int
+		WantErr   bool
+	}{
+		{Desc: "succeeds first time", WantCalls: 1},
+		{Desc: "retries 5xx", Errs: []error{unavailable, unavailable}, WantCalls: 3},
+		{Desc: "retries 429", Errs: []error{&hexbot.StatusError{StatusCode: http.StatusTooManyRequests}}, WantCalls: 2},
+		{Desc: "gives up after max attempts", Errs: []error{unavailable, unavailable, unavailable}, WantCalls: 3, WantErr: true},
+		{Desc: "does not retry 4xx", Errs: []error{&hexbot.StatusError{StatusCode: http.StatusBadRequest}}, WantCalls: 1, WantErr: true},
+		{Desc: "does not retry bad json", Errs: []error{&hexbot.DecodeError{Err: errors.New("unexpected EOF")}}, WantCalls: 1, WantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			f := &scriptedFetcher{errs: tt.Errs}
+			b := hexbot.NewBreaker(logging.NopLogger, hexbot.BreakerConfig{FailureThreshold: 10})
+			r := hexbot.NewResilient(f, b, hexbot.RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+			_, err := r.Fetch(context.Background(), hexbot.FetchOptions{})
+			if (err != nil) != tt.WantErr {
+				t.Fatalf("Fetch() error = %v, wantErr %v", err, tt.WantErr)
+			}
+			if f.calls != tt.WantCalls {
+				t.Errorf("expected %d calls, got %d", tt.WantCalls, f.calls)
+			}
+		})
+	}
+}
+
+type recordingObserver struct {
+	classes []string
+}
+
+func (o *recordingObserver) ObserveHexbotRequest(d time.Duration, err error) {
+	o.classes = append(o.classes, hexbot.ErrorClass(err))
+}
+
+func TestResilient_Observer(t *testing.T) {
+	f := &scriptedFetcher{errs: []error{&hexbot.StatusError{StatusCode: http.StatusServiceUnavailable}, &hexbot.StatusError{StatusCode: http.StatusTooManyRequests}}}
+	o := &recordingObserver{}
+	b := hexbot.NewBreaker(logging.NopLogger, hexbot.BreakerConfig{FailureThreshold: 10})
+	r := hexbot.NewResilient(f, b, hexbot.RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond}).WithObserver(o)
+
+	if _, err := r.Fetch(context.Background(), hexbot.FetchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"server_error", "rate_limited", "ok"}
+	if strings.Join(o.classes, ",") != strings.Join(want, ",") {
+		t.Errorf("expected every attempt observed as %v, got %v", want, o.classes)
+	}
+}
+
+func TestErrorClass(t *testing.T) {
+	tests := []struct {
+		Err  error
+		Want string
+	}{
+		{nil, "ok"},
+		{errors.Wrap(&hexbot.StatusError{StatusCode: http.StatusBadRequest}, "wrapped"), "client_error"},
+		{&hexbot.StatusError{StatusCode: http.StatusBadGateway}, "server_error"},
+		{&hexbot.DecodeError{Err: errors.New("unexpected EOF")}, "decode"},
+		{context.DeadlineExceeded, "timeout"},
+		{context.Canceled, "canceled"},
+		{errors.New("something else"), "other"},
+	}
+	for _, tt := range tests {
+		if got := hexbot.ErrorClass(tt.Err); got != tt.Want {
+			t.Errorf("ErrorClass(%v) = %q, want %q", tt.Err, got, tt.Want)
+		}
+	}
+}
+
+func TestResilient_HonoursRetryAfter(t *testing.T) {
+	f := &scriptedFetcher{errs: []error{&hexbot.StatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: 50 * time.Millisecond}}}
+	b := hexbot.NewBreaker(logging.NopLogger, hexbot.BreakerConfig{})
+	r := hexbot.NewResilient(f, b, hexbot.RetryConfig{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Second})
+
+	start := time.Now()
+	if _, err := r.Fetch(context.Background(), hexbot.FetchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected to wait for Retry-After, waited %s", elapsed)
+	}
+}
+
+func TestResilient_ClampsRetryAfter(t *testing.T) {
+	f := &scriptedFetcher{errs: []error{&hexbot.StatusError{StatusCode: http.StatusServiceUnavailable, RetryAfter: time.Hour}}}
+	b := hexbot.NewBreaker(logging.NopLogger, hexbot.BreakerConfig{})
+	r := hexbot.NewResilient(f, b, hexbot.RetryConfig{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: 20 * time.Millisecond})
+
+	start := time.Now()
+	if _, err := r.Fetch(context.Background(), hexbot.FetchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected Retry-After to be capped at MaxDelay, waited %s", elapsed)
+	}
+}
+
+func TestResilient_Breaker(t *testing.T) {
+	tests := []struct {
+		Desc      string
+		Err       error
+		WantState hexbot.BreakerState
+	}{
+		{Desc: "5xx is a failure", Err: &hexbot.StatusError{StatusCode: http.StatusBadGateway}, WantState: hexbot.BreakerOpen},
+		{Desc: "bad json is a failure", Err: &hexbot.DecodeError{Err: errors.New("unexpected EOF")}, WantState: hexbot.BreakerOpen},
+		{Desc: "an empty response is a failure", Err: errors.New("hexbot response contained no colours"), WantState: hexbot.BreakerOpen},
+		{Desc: "4xx is not a failure", Err: &hexbot.StatusError{StatusCode: http.StatusBadRequest}, WantState: hexbot.BreakerClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			f := &scriptedFetcher{errs: []error{tt.Err}}
+			b := hexbot.NewBreaker(logging.NopLogger, hexbot.BreakerConfig{FailureThreshold: 1})
+			r := hexbot.NewResilient(f, b, hexbot.RetryConfig{MaxAttempts: 1})
+
+			if _, err := r.Fetch(context.Background(), hexbot.FetchOptions{}); err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := b.State(); got != tt.WantState {
+				t.Errorf("expected the breaker to be %s, got %s", tt.WantState, got)
+			}
+		})
+	}
+
+	t.Run("4xx releases the half-open probe", func(t *testing.T) {
+		f := &scriptedFetcher{errs: []error{&hexbot.StatusError{StatusCode: http.StatusNotFound}}}
+		b := hexbot.NewBreaker(logging.NopLogger, hexbot.BreakerConfig{FailureThreshold: 1, CoolDown: time.Millisecond})
+		if err := b.Allow(); err != nil {
+			t.Fatal(err)
+		}
+		b.Record(false)
+		time.Sleep(5 * time.Millisecond)
+
+		r := hexbot.NewResilient(f, b, hexbot.RetryConfig{MaxAttempts: 1})
+		if _, err := r.Fetch(context.Background(), hexbot.FetchOptions{}); err == nil {
+			t.Fatal("expected an error")
+		}
+		if got := b.State(); got != hexbot.BreakerHalfOpen {
+			t.Fatalf("expected the breaker to stay half-open, got %s", got)
+		}
+		if _, err := r.Fetch(context.Background(), hexbot.FetchOptions{}); err != nil {
+			t.Fatalf("expected the next probe to be let through, got %v", err)
+		}
+		if got := b.State(); got != hexbot.BreakerClosed {
+			t.Errorf("expected the breaker to close, got %s", got)
+		}
+	})
+
+	t.Run("invalid options never reach the breaker", func(t *testing.T) {
+		f := &scriptedFetcher{}
+		b := hexbot.NewBreaker(logging.NopLogger, hexbot.BreakerConfig{FailureThreshold: 1})
+		r := hexbot.NewResilient(f, b, hexbot.RetryConfig{MaxAttempts: 1})
+		if _, err := r.Fetch(context.Background(), hexbot.FetchOptions{Count: -1}); err == nil {
+			t.Fatal("expected an error")
+		}
+		if f.calls != 0 || b.State() != hexbot.BreakerClosed {
+			t.Errorf("expected no call and a closed breaker, got %d calls and %s", f.calls, b.State())
+		}
+	})
+}
+
+func TestBreaker(t *testing.T) {
+	b := hexbot.NewBreaker(logging.NopLogger, hexbot.BreakerConfig{FailureThreshold: 2, CoolDown: 20 * time.Millisecond})
+
+	for i := 0; i < 2; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatal(err)
+		}
+		b.Record(false)
+	}
+	if b.State() != hexbot.BreakerOpen {
+		t.Fatalf("expected open, got %s", b.State())
+	}
+	if err := b.Allow(); err != hexbot.ErrCircuitOpen {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if b.State() != hexbot.BreakerHalfOpen {
+		t.Fatalf("expected half-open, got %s", b.State())
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("expected a probe to be allowed, got %v", err)
+	}
+	if err := b.Allow(); err != hexbot.ErrCircuitOpen {
+		t.Fatal("expected only one probe while half-open")
+	}
+	b.Record(false)
+	if b.State() != hexbot.BreakerOpen {
+		t.Fatalf("expected a failed probe to reopen, got %s", b.State())
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if err := b.Allow(); err != nil {
+		t.Fatal(err)
+	}
+	b.Record(true)
+	if b.State() != hexbot.BreakerClosed {
+		t.Fatalf("expected a successful probe to close, got %s", b.State())
+	}
+}
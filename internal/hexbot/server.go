@@ -0,0 +1,111 @@
+package hexbot
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+)
+
+// ServerConfig controls the faults a Server injects on top of the generated colours.
+type ServerConfig struct {
+	// Latency is added before every response.
+	Latency time.Duration
+	// ErrorRate is the fraction of requests, 0 to 1, answered with a 500.
+	ErrorRate float64
+	// MalformedRate is the fraction of requests, 0 to 1, answered with a truncated JSON body.
+	MalformedRate float64
+	// Seed drives both the generated colours and the fault injection.
+	Seed int64
+}
+
+// Server is an http.Handler that imitates the public Hexbot API on /hexbot,
+// backed by a Generator so its output is reproducible.
+type Server struct {
+	log       *logging.Logger
+	cfg       ServerConfig
+	generator *Generator
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+	mux *http.ServeMux
+}
+
+// NewServer returns a Server using cfg.
+func NewServer(log *logging.Logger, cfg ServerConfig) *Server {
+	s := &Server{
+		log:       log,
+		cfg:       cfg,
+		generator: NewGenerator(cfg.Seed),
+		rnd:       rand.New(rand.NewSource(cfg.Seed)),
+		mux:       http.NewServeMux(),
+	}
+	s.mux.HandleFunc(hexbotPath, s.hexbot)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) hexbot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMessage(w, http.StatusMethodNotAllowed, "only GET is supported")
+		return
+	}
+
+	if s.cfg.Latency > 0 {
+		select {
+		case <-time.After(s.cfg.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	s.mu.Lock()
+	fail, malformed := s.rnd.Float64() < s.cfg.ErrorRate, s.rnd.Float64() < s.cfg.MalformedRate
+	s.mu.Unlock()
+
+	if fail {
+		writeMessage(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	opts, err := ParseQuery(r.URL.Query())
+	if err != nil {
+		writeMessage(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	colours, err := s.generator.Fetch(r.Context(), opts)
+	if err != nil {
+		s.log.Error("problem generating colours", err)
+		writeMessage(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	body, err := json.Marshal(Response{Colors: colours})
+	if err != nil {
+		s.log.Error("problem encoding colours", err)
+		writeMessage(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if malformed {
+		body = body[:len(body)/2]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+func writeMessage(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(struct {
+		Message string `json:"message"`
+	}{msg})
+}
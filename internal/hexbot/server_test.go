@@ -0,0 +1,83 @@
+package hexbot_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/pkg/errors"
+
+	"hexbot/internal/hexbot"
+)
+
+func TestServer_RoundTrip(t *testing.T) {
+	srv := httptest.NewServer(hexbot.NewServer(logging.NopLogger, hexbot.ServerConfig{Seed: 7}))
+	defer srv.Close()
+
+	c, err := hexbot.NewClient(hexbot.Config{BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	colours, err := c.Fetch(context.Background(), hexbot.FetchOptions{Count: 5, Width: 10, Height: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(colours) != 5 {
+		t.Fatalf("expected 5 colours, got %d", len(colours))
+	}
+	for _, colour := range colours {
+		if colour.Coordinates == nil {
+			t.Fatal("expected coordinates")
+		}
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	tests := []struct {
+		Desc       string
+		Cfg        hexbot.ServerConfig
+		Query      string
+		WantStatus int
+	}{
+		{Desc: "count out of range", Query: "?count=1001", WantStatus: http.StatusBadRequest},
+		{Desc: "count not a number", Query: "?count=lots", WantStatus: http.StatusBadRequest},
+		{Desc: "width without height", Query: "?width=100", WantStatus: http.StatusBadRequest},
+		{Desc: "injected failure", Cfg: hexbot.ServerConfig{ErrorRate: 1}, WantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			srv := httptest.NewServer(hexbot.NewServer(logging.NopLogger, tt.Cfg))
+			defer srv.Close()
+
+			resp, err := http.Get(srv.URL + "/hexbot" + tt.Query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.WantStatus {
+				t.Errorf("expected status %d, got %d", tt.WantStatus, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestServer_Malformed(t *testing.T) {
+	srv := httptest.NewServer(hexbot.NewServer(logging.NopLogger, hexbot.ServerConfig{MalformedRate: 1}))
+	defer srv.Close()
+
+	c, err := hexbot.NewClient(hexbot.Config{BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Fetch(context.Background(), hexbot.FetchOptions{})
+	if err == nil {
+		t.Fatal("expected a decoding error")
+	}
+	if _, ok := errors.Cause(err).(*hexbot.StatusError); ok {
+		t.Fatal("expected a decoding error, not a status error")
+	}
+}
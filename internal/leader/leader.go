@@ -0,0 +1,193 @@
+// Package leader elects one of several replicas to do work that must not be duplicated, such as
+// scheduled fetches, using an expiring lease in Mongo.
+package leader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/pkg/errors"
+
+	"hexbot/internal/db"
+	"hexbot/internal/requestctx"
+)
+
+// DefaultTTL is how long a lease lasts without renewal when Config sets no TTL.
+const DefaultTTL = 15 * time.Second
+
+// Store takes, renews and gives up leases.
+type Store interface {
+	AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (*db.Lease, error)
+	RenewLease(ctx context.Context, name, holder string, token int64, ttl time.Duration) (*db.Lease, error)
+	ReleaseLease(ctx context.Context, name, holder string, token int64) error
+}
+
+// Config tunes an election.
+type Config struct {
+	// Name identifies the lease; replicas competing for the same work use the same name.
+	Name string
+	// Holder identifies this replica, Holder() when empty.
+	Holder string
+	// TTL is how long the lease lasts without renewal, DefaultTTL when zero. The leader renews every
+	// third of it and followers try to take over as often, so when a leader dies another replica leads
+	// within TTL plus a third of it of the leader's last renewal.
+	TTL time.Duration
+}
+
+// Holder names this process by host and pid.
+func Holder() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// Elector campaigns for a lease and runs work while it holds it.
+type Elector struct {
+	log   *logging.Logger
+	store Store
+	cfg   Config
+
+	mu    sync.Mutex
+	lease *db.Lease
+}
+
+// Status is what an elector knows about the lease.
+type Status struct {
+	Leader bool   `json:"leader"`
+	Holder string `json:"holder"`
+	// Token and ExpiresAt describe this replica's lease while it leads.
+	Token     int64      `json:"token,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// New returns an elector for cfg.
+func New(log *logging.Logger, store Store, cfg Config) *Elector {
+	if cfg.Holder == "" {
+		cfg.Holder = Holder()
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultTTL
+	}
+	return &Elector{log: log, store: store, cfg: cfg}
+}
+
+// Status reports whether this replica leads.
+func (e *Elector) Status() Status {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	st := Status{Holder: e.cfg.Holder}
+	if e.lease != nil {
+		st.Leader, st.Token = true, e.lease.Token
+		expires := e.lease.ExpiresAt
+		st.ExpiresAt = &expires
+	}
+	return st
+}
+
+// Run campaigns until ctx is cancelled. Each time this replica wins the lease it calls lead with a
+// context carrying the lease's fencing token, see requestctx.FencingToken, which is cancelled as soon as
+// the lease is lost or can't be renewed before it would expire. The DB refuses writes carrying a token
+// older than the lease's, in case work carries on after losing it. Run waits for lead to return before
+// campaigning again, and gives up the lease on the way out so another replica can take over at once.
+func (e *Elector) Run(ctx context.Context, lead func(ctx context.Context)) {
+	every := e.cfg.TTL / 3
+	for {
+		lease, err := e.store.AcquireLease(ctx, e.cfg.Name, e.cfg.Holder, e.cfg.TTL)
+		switch {
+		case err == nil:
+			e.log.Info(fmt.Sprintf("leading %s with token %d", e.cfg.Name, lease.Token))
+			e.lead(ctx, lease, lead)
+			e.log.Info(fmt.Sprintf("stopped leading %s", e.cfg.Name))
+		case errors.Cause(err) == db.ErrLeaseHeld:
+		case ctx.Err() == nil:
+			e.log.Warn("problem campaigning for " + e.cfg.Name + ": " + err.Error())
+		}
+		if !sleep(ctx, every) {
+			return
+		}
+	}
+}
+
+// lead runs work while renewing lease, returning once work has returned.
+func (e *Elector) lead(ctx context.Context, lease *db.Lease, work func(ctx context.Context)) {
+	e.setLease(lease)
+	defer e.setLease(nil)
+
+	leadCtx, cancel := context.WithCancel(requestctx.WithFencingToken(ctx, lease.Name, lease.Token))
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		work(leadCtx)
+	}()
+
+	e.keep(leadCtx, lease, done)
+	cancel()
+	<-done
+
+	// Give the lease up if it is still ours, whether shutting down or stepping down.
+	rctx, rcancel := context.WithTimeout(context.Background(), e.cfg.TTL/3)
+	defer rcancel()
+	if err := e.store.ReleaseLease(rctx, e.cfg.Name, e.cfg.Holder, lease.Token); err != nil && errors.Cause(err) != db.ErrLeaseLost {
+		e.log.Warn("problem releasing " + e.cfg.Name + ": " + err.Error())
+	}
+}
+
+// keep renews the lease every third of its TTL until ctx is cancelled, work finishes, the lease is lost,
+// or renewals keep failing until the lease is about to expire.
+func (e *Elector) keep(ctx context.Context, lease *db.Lease, done <-chan struct{}) {
+	every := e.cfg.TTL / 3
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		rctx, cancel := context.WithTimeout(ctx, every)
+		renewed, err := e.store.RenewLease(rctx, e.cfg.Name, e.cfg.Holder, lease.Token, e.cfg.TTL)
+		cancel()
+		switch {
+		case err == nil:
+			lease = renewed
+			e.setLease(lease)
+		case errors.Cause(err) == db.ErrLeaseLost:
+			e.log.Warn(fmt.Sprintf("lost %s with token %d", e.cfg.Name, lease.Token))
+			return
+		case time.Until(lease.ExpiresAt) < every:
+			// Another replica may take over before the next attempt, so step down now.
+			e.log.Error("problem renewing "+e.cfg.Name+", stepping down", err)
+			return
+		default:
+			e.log.Warn("problem renewing " + e.cfg.Name + ", will retry: " + err.Error())
+		}
+	}
+}
+
+func (e *Elector) setLease(l *db.Lease) {
+	e.mu.Lock()
+	e.lease = l
+	e.mu.Unlock()
+}
+
+// sleep waits for d, returning false if ctx is cancelled first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
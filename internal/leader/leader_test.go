@@ -0,0 +1,219 @@
+package leader_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/pkg/errors"
+
+	"hexbot/internal/db"
+	"hexbot/internal/leader"
+	"hexbot/internal/requestctx"
+)
+
+// memStore keeps leases in memory with the same rules as the Mongo store.
+type memStore struct {
+	mu     sync.Mutex
+	leases map[string]db.Lease
+	// down makes every call fail, as if Mongo were unreachable.
+	down bool
+}
+
+func (s *memStore) AcquireLease(_ context.Context, name, holder string, ttl time.Duration) (*db.Lease, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return nil, errors.New("connection refused")
+	}
+	now := time.Now()
+	l, ok := s.leases[name]
+	switch {
+	case ok && l.Holder == holder && now.Before(l.ExpiresAt):
+	case ok && now.Before(l.ExpiresAt):
+		return nil, errors.Wrap(db.ErrLeaseHeld, name)
+	default:
+		l = db.Lease{Name: name, Holder: holder, Token: l.Token + 1, AcquiredAt: now}
+	}
+	l.RenewedAt, l.ExpiresAt = now, now.Add(ttl)
+	if s.leases == nil {
+		s.leases = map[string]db.Lease{}
+	}
+	s.leases[name] = l
+	return &l, nil
+}
+
+func (s *memStore) RenewLease(_ context.Context, name, holder string, token int64, ttl time.Duration) (*db.Lease, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return nil, errors.New("connection refused")
+	}
+	now := time.Now()
+	l, ok := s.leases[name]
+	if !ok || l.Holder != holder || l.Token != token || !now.Before(l.ExpiresAt) {
+		return nil, errors.Wrap(db.ErrLeaseLost, name)
+	}
+	l.RenewedAt, l.ExpiresAt = now, now.Add(ttl)
+	s.leases[name] = l
+	return &l, nil
+}
+
+func (s *memStore) ReleaseLease(_ context.Context, name, holder string, token int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.leases[name]
+	if !ok || l.Holder != holder || l.Token != token {
+		return errors.Wrap(db.ErrLeaseLost, name)
+	}
+	l.ExpiresAt = time.Now()
+	s.leases[name] = l
+	return nil
+}
+
+func (s *memStore) setDown(down bool) {
+	s.mu.Lock()
+	s.down = down
+	s.mu.Unlock()
+}
+
+// replica runs an elector until stopped, reporting the tokens it led with.
+type replica struct {
+	elector *leader.Elector
+	stop    func()
+	mu      sync.Mutex
+	tokens  []int64
+}
+
+func (r *replica) led() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int64(nil), r.tokens...)
+}
+
+func startReplica(store leader.Store, holder string, ttl time.Duration) *replica {
+	r := &replica{elector: leader.New(logging.NopLogger, store, leader.Config{Name: "scheduler", Holder: holder, TTL: ttl})}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.elector.Run(ctx, func(ctx context.Context) {
+			r.mu.Lock()
+			r.tokens = append(r.tokens, requestctx.FencingToken(ctx))
+			r.mu.Unlock()
+			<-ctx.Done()
+		})
+	}()
+	r.stop = func() {
+		cancel()
+		<-done
+	}
+	return r
+}
+
+// testElection runs three replicas against store, checking exactly one leads, then stops the leader
+// and checks another takes over within the bound.
+func testElection(t *testing.T, store leader.Store, ttl time.Duration) {
+	var replicas []*replica
+	for i := 0; i < 3; i++ {
+		r := startReplica(store, fmt.Sprintf("replica-%d", i), ttl)
+		defer r.stop()
+		replicas = append(replicas, r)
+	}
+
+	leaderOf := func() (int, int) {
+		lead, n := -1, 0
+		for i, r := range replicas {
+			if r.elector.Status().Leader {
+				lead, n = i, n+1
+			}
+		}
+		return lead, n
+	}
+
+	time.Sleep(ttl / 2)
+	first, n := leaderOf()
+	if n != 1 {
+		t.Fatalf("expected exactly one leader, got %d", n)
+	}
+	token := replicas[first].elector.Status().Token
+	if led := replicas[first].led(); len(led) != 1 || led[0] != token {
+		t.Errorf("expected the leader's work to carry token %d, got %v", token, led)
+	}
+
+	// A leader that stops cleanly hands over within one retry interval; the bound for a dead one is
+	// TTL plus the retry interval.
+	stopped := time.Now()
+	replicas[first].stop()
+	var next int
+	for {
+		if next, n = leaderOf(); n == 1 {
+			break
+		}
+		if time.Since(stopped) > ttl+ttl/3+ttl/4 {
+			t.Fatalf("no replica took over within %s", time.Since(stopped))
+		}
+		time.Sleep(ttl / 20)
+	}
+	if next == first {
+		t.Fatal("expected a different replica to lead")
+	}
+	if got := replicas[next].elector.Status().Token; got <= token {
+		t.Errorf("expected the token to increase on takeover, got %d after %d", got, token)
+	}
+}
+
+func TestElector(t *testing.T) {
+	testElection(t, &memStore{}, 300*time.Millisecond)
+}
+
+func TestElector_StepsDownWhenStoreFails(t *testing.T) {
+	store := &memStore{}
+	ttl := 300 * time.Millisecond
+	r := startReplica(store, "only", ttl)
+	defer r.stop()
+
+	time.Sleep(ttl / 2)
+	if !r.elector.Status().Leader {
+		t.Fatal("expected the only replica to lead")
+	}
+	store.setDown(true)
+	// Renewals fail from the next one on; the leader steps down before its lease could expire.
+	time.Sleep(ttl)
+	if r.elector.Status().Leader {
+		t.Error("expected the leader to step down once it couldn't renew")
+	}
+
+	store.setDown(false)
+	time.Sleep(ttl)
+	// Having let the lease go, it comes back with a new token.
+	if led := r.led(); len(led) != 2 || led[1] <= led[0] {
+		t.Errorf("expected the replica to lead again with a higher token, got %v", led)
+	}
+}
+
+// TestElector_Mongo runs the election against the mongod named by MONGO_TEST_URI.
+func TestElector_Mongo(t *testing.T) {
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI not set, skipping mongo integration test")
+	}
+	d, err := db.NewDB(context.Background(), logging.NopLogger, db.Config{
+		URI:        uri,
+		Database:   fmt.Sprintf("hexbot_test_%d", time.Now().UnixNano()),
+		Collection: "colours",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		d.Drop(context.Background())
+		d.Disconnect(context.Background())
+	}()
+
+	testElection(t, d, time.Second)
+}
@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+
+	"hexbot/internal/db"
+	"hexbot/internal/hexbot"
+	"hexbot/internal/service"
+)
+
+// Metrics are what the server exports at /metrics. It observes Hexbot requests, saved batches,
+// scheduled runs and HTTP requests, and monitors Mongo commands through CommandMonitor.
+type Metrics struct {
+	*Registry
+
+	hexbotRequests      *Counter
+	hexbotDuration      *Histogram
+	coloursSaved        *Counter
+	coloursDeduplicated *Counter
+	mongoCommands       *Histogram
+	schedulerRuns       *Counter
+	httpRequests        *Counter
+	httpDuration        *Histogram
+}
+
+// New registers every metric in a new registry.
+func New() *Metrics {
+	r := NewRegistry()
+	return &Metrics{
+		Registry: r,
+		hexbotRequests: r.NewCounter("hexbot_upstream_requests_total",
+			"Requests made to Hexbot, by outcome: ok or the class of error.", "class"),
+		hexbotDuration: r.NewHistogram("hexbot_upstream_request_duration_seconds",
+			"How long requests to Hexbot took.", nil),
+		coloursSaved: r.NewCounter("hexbot_colours_saved_total",
+			"Colours saved to the database."),
+		coloursDeduplicated: r.NewCounter("hexbot_colours_deduplicated_total",
+			"Near duplicate colours not saved, by whether they were rejected or merged.", "action"),
+		mongoCommands: r.NewHistogram("hexbot_mongo_command_duration_seconds",
+			"How long Mongo commands took, by command and whether they succeeded.", nil, "command", "outcome"),
+		schedulerRuns: r.NewCounter("hexbot_scheduler_runs_total",
+			"Scheduled runs that ended, by job and outcome.", "job", "outcome"),
+		httpRequests: r.NewCounter("hexbot_http_requests_total",
+			"HTTP requests served, by method, route and status.", "method", "route", "status"),
+		httpDuration: r.NewHistogram("hexbot_http_request_duration_seconds",
+			"How long HTTP requests took to serve, by method and route.", nil, "method", "route"),
+	}
+}
+
+// ObserveHexbotRequest records one request to Hexbot, see hexbot.Resilient.WithObserver.
+func (m *Metrics) ObserveHexbotRequest(d time.Duration, err error) {
+	m.hexbotRequests.Inc(hexbot.ErrorClass(err))
+	m.hexbotDuration.Observe(d.Seconds())
+}
+
+// ObserveSave records a saved batch, see service.ColourService.WithObserver.
+func (m *Metrics) ObserveSave(res service.SaveResult) {
+	m.coloursSaved.Add(float64(res.Saved))
+	m.coloursDeduplicated.Add(float64(res.Rejected), "rejected")
+	m.coloursDeduplicated.Add(float64(res.Merged), "merged")
+}
+
+// ObserveRun records a scheduled run that has ended, see scheduler.Scheduler.WithObserver.
+func (m *Metrics) ObserveRun(r db.RunDocument) {
+	m.schedulerRuns.Inc(r.Job, string(r.Outcome))
+}
+
+// ObserveRequest records a served HTTP request. route is the pattern that matched the request rather
+// than its path, and methods outside the standard ones count as OTHER, so that clients can't add series.
+func (m *Metrics) ObserveRequest(method, route string, status int, d time.Duration) {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions:
+	default:
+		method = "OTHER"
+	}
+	m.httpRequests.Inc(method, route, strconv.Itoa(status))
+	m.httpDuration.Observe(d.Seconds(), method, route)
+}
+
+// CommandMonitor times every Mongo command, for db.Config.Monitor.
+func (m *Metrics) CommandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		// The driver only times commands it has seen start, so Started must be set even though there is
+		// nothing to do yet.
+		Started: func(context.Context, *event.CommandStartedEvent) {},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			m.mongoCommands.Observe(time.Duration(e.DurationNanos).Seconds(), e.CommandName, "succeeded")
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			m.mongoCommands.Observe(time.Duration(e.DurationNanos).Seconds(), e.CommandName, "failed")
+		},
+	}
+}
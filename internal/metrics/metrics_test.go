@@ -0,0 +1,56 @@
+package metrics_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+
+	"hexbot/internal/db"
+	"hexbot/internal/hexbot"
+	"hexbot/internal/metrics"
+	"hexbot/internal/service"
+)
+
+func TestMetrics(t *testing.T) {
+	m := metrics.New()
+	m.ObserveHexbotRequest(20*time.Millisecond, nil)
+	m.ObserveHexbotRequest(time.Second, &hexbot.StatusError{StatusCode: http.StatusServiceUnavailable})
+	m.ObserveSave(service.SaveResult{Saved: 3, Rejected: 1, Merged: 2})
+	m.ObserveRun(db.RunDocument{Job: "hourly", Outcome: db.RunSucceeded})
+	m.ObserveRequest(http.MethodGet, "/colours/", http.StatusOK, time.Millisecond)
+	m.ObserveRequest("BREW", "/", http.StatusMethodNotAllowed, time.Millisecond)
+
+	mon := m.CommandMonitor()
+	finished := event.CommandFinishedEvent{CommandName: "find", DurationNanos: int64(3 * time.Millisecond)}
+	mon.Started(context.Background(), &event.CommandStartedEvent{CommandName: "find"})
+	mon.Succeeded(context.Background(), &event.CommandSucceededEvent{CommandFinishedEvent: finished})
+	mon.Failed(context.Background(), &event.CommandFailedEvent{CommandFinishedEvent: finished, Failure: "boom"})
+
+	var buf bytes.Buffer
+	if err := m.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`hexbot_upstream_requests_total{class="ok"} 1`,
+		`hexbot_upstream_requests_total{class="server_error"} 1`,
+		`hexbot_upstream_request_duration_seconds_count 2`,
+		`hexbot_colours_saved_total 3`,
+		`hexbot_colours_deduplicated_total{action="merged"} 2`,
+		`hexbot_colours_deduplicated_total{action="rejected"} 1`,
+		`hexbot_mongo_command_duration_seconds_bucket{command="find",outcome="failed",le="0.005"} 1`,
+		`hexbot_mongo_command_duration_seconds_count{command="find",outcome="succeeded"} 1`,
+		`hexbot_scheduler_runs_total{job="hourly",outcome="succeeded"} 1`,
+		`hexbot_http_requests_total{method="GET",route="/colours/",status="200"} 1`,
+		`hexbot_http_requests_total{method="OTHER",route="/",status="405"} 1`,
+		`hexbot_http_request_duration_seconds_count{method="GET",route="/colours/"} 1`,
+	} {
+		if !strings.Contains(buf.String(), want+"\n") {
+			t.Errorf("expected %s in:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,275 @@
+// Package metrics keeps counters and histograms and writes them in the Prometheus text exposition
+// format, for scraping at /metrics.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the media type of WriteText's output.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are histogram bucket upper bounds suited to latencies in seconds.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+var namePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// Registry holds metrics to be written together.
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[string]metric
+}
+
+type metric interface {
+	writeText(w *bufio.Writer)
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{metrics: map[string]metric{}}
+}
+
+// NewCounter registers a counter partitioned by the given labels. It panics if the name is invalid or
+// taken, as those are programming errors.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{vec: newVec(name, help, "counter", labels)}
+	if len(labels) == 0 {
+		c.vec.get(nil)
+	}
+	r.register(name, c)
+	return c
+}
+
+// NewHistogram registers a histogram with the given bucket upper bounds, DefaultBuckets when nil,
+// partitioned by the given labels. It panics if the name is invalid or taken.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	if !sort.Float64sAreSorted(buckets) {
+		panic(fmt.Sprintf("metrics: buckets of %s are not in increasing order", name))
+	}
+	for _, l := range labels {
+		if l == "le" {
+			panic(fmt.Sprintf("metrics: histogram %s cannot have an le label", name))
+		}
+	}
+	h := &Histogram{vec: newVec(name, help, "histogram", labels), buckets: buckets}
+	if len(labels) == 0 {
+		h.vec.get(nil)
+	}
+	r.register(name, h)
+	return h
+}
+
+func (r *Registry) register(name string, m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.metrics[name]; ok {
+		panic(fmt.Sprintf("metrics: %s is already registered", name))
+	}
+	r.metrics[name] = m
+}
+
+// WriteText writes every metric in the Prometheus text exposition format, ordered by name.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	metrics := make([]metric, len(names))
+	sort.Strings(names)
+	for i, name := range names {
+		metrics[i] = r.metrics[name]
+	}
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.writeText(bw)
+	}
+	return bw.Flush()
+}
+
+// Counter is a count that only goes up, one per combination of label values.
+type Counter struct {
+	vec *vec
+}
+
+// Inc adds one to the count with the given label values.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds delta, which must not be negative, to the count with the given label values.
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("metrics: counter %s cannot go down", c.vec.name))
+	}
+	c.vec.mu.Lock()
+	defer c.vec.mu.Unlock()
+	c.vec.get(labelValues).value += delta
+}
+
+func (c *Counter) writeText(w *bufio.Writer) {
+	v := c.vec
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.writeHeader(w)
+	for _, s := range v.sorted() {
+		writeSample(w, v.name, v.labels, s.values, "", 0, s.value)
+	}
+}
+
+// Histogram counts observations into buckets, one set per combination of label values.
+type Histogram struct {
+	vec     *vec
+	buckets []float64
+}
+
+// Observe records x against the given label values.
+func (h *Histogram) Observe(x float64, labelValues ...string) {
+	h.vec.mu.Lock()
+	defer h.vec.mu.Unlock()
+	s := h.vec.get(labelValues)
+	if s.buckets == nil {
+		s.buckets = make([]uint64, len(h.buckets))
+	}
+	if i := sort.SearchFloat64s(h.buckets, x); i < len(h.buckets) {
+		s.buckets[i]++
+	}
+	s.count++
+	s.value += x
+}
+
+func (h *Histogram) writeText(w *bufio.Writer) {
+	v := h.vec
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.writeHeader(w)
+	for _, s := range v.sorted() {
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			if s.buckets != nil {
+				cumulative += s.buckets[i]
+			}
+			writeSample(w, v.name+"_bucket", v.labels, s.values, "le", bound, float64(cumulative))
+		}
+		writeSample(w, v.name+"_bucket", v.labels, s.values, "le", math.Inf(1), float64(s.count))
+		writeSample(w, v.name+"_sum", v.labels, s.values, "", 0, s.value)
+		writeSample(w, v.name+"_count", v.labels, s.values, "", 0, float64(s.count))
+	}
+}
+
+// vec is a metric's description and its series, one per combination of label values.
+type vec struct {
+	name, help, typ string
+	labels          []string
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	values []string
+	// value is a counter's count or a histogram's sum.
+	value   float64
+	buckets []uint64
+	count   uint64
+}
+
+func newVec(name, help, typ string, labels []string) *vec {
+	if !namePattern.MatchString(name) {
+		panic(fmt.Sprintf("metrics: %q is not a valid metric name", name))
+	}
+	for _, l := range labels {
+		if !namePattern.MatchString(l) || strings.Contains(l, ":") {
+			panic(fmt.Sprintf("metrics: %q is not a valid label name", l))
+		}
+	}
+	return &vec{name: name, help: help, typ: typ, labels: labels, series: map[string]*series{}}
+}
+
+// get returns the series with values, creating it if need be. v.mu must be held.
+func (v *vec) get(values []string) *series {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", v.name, len(v.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{values: append([]string(nil), values...)}
+		v.series[key] = s
+	}
+	return s
+}
+
+// sorted returns the series ordered by their label values. v.mu must be held.
+func (v *vec) sorted() []*series {
+	out := make([]*series, 0, len(v.series))
+	for _, s := range v.series {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		for k := range out[i].values {
+			if out[i].values[k] != out[j].values[k] {
+				return out[i].values[k] < out[j].values[k]
+			}
+		}
+		return false
+	})
+	return out
+}
+
+func (v *vec) writeHeader(w *bufio.Writer) {
+	help := strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(v.help)
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.name, help, v.name, v.typ)
+}
+
+// labelEscaper escapes label values as the exposition format requires.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// writeSample writes one line, adding the extra label, such as le, when it is named.
+func writeSample(w *bufio.Writer, name string, labels, values []string, extra string, extraValue, value float64) {
+	w.WriteString(name)
+	if len(labels) > 0 || extra != "" {
+		w.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, l, labelEscaper.Replace(values[i]))
+		}
+		if extra != "" {
+			if len(labels) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, extra, formatFloat(extraValue))
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
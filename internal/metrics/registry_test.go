@@ -0,0 +1,105 @@
+package metrics_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"hexbot/internal/metrics"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	r := metrics.NewRegistry()
+	c := r.NewCounter("requests_total", "Requests served.\nBy code.", "code", "path")
+	h := r.NewHistogram("latency_seconds", "How long requests took.", []float64{0.1, 1})
+	r.NewCounter("idle_total", "Never incremented.")
+
+	c.Inc("200", "/a")
+	c.Add(2, "200", "/a")
+	c.Inc("500", `/b"\`+"\n")
+	h.Observe(0.05)
+	h.Observe(0.1)
+	h.Observe(3)
+
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP idle_total Never incremented.
+# TYPE idle_total counter
+idle_total 0
+# HELP latency_seconds How long requests took.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.1"} 2
+latency_seconds_bucket{le="1"} 2
+latency_seconds_bucket{le="+Inf"} 3
+latency_seconds_sum 3.15
+latency_seconds_count 3
+# HELP requests_total Requests served.\nBy code.
+# TYPE requests_total counter
+requests_total{code="200",path="/a"} 3
+requests_total{code="500",path="/b\"\\\n"} 1
+`
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected exposition:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestHistogram_LabelledBuckets(t *testing.T) {
+	r := metrics.NewRegistry()
+	h := r.NewHistogram("op_seconds", "Ops.", []float64{1}, "op")
+	h.Observe(0.5, "read")
+
+	var buf bytes.Buffer
+	r.WriteText(&buf)
+	if !strings.Contains(buf.String(), `op_seconds_bucket{op="read",le="1"} 1`) {
+		t.Errorf("expected the le label after the others, got:\n%s", buf.String())
+	}
+}
+
+func TestCounter_Concurrent(t *testing.T) {
+	r := metrics.NewRegistry()
+	c := r.NewCounter("hits_total", "Hits.", "kind")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				c.Inc("a")
+			}
+		}()
+	}
+	wg.Wait()
+
+	var buf bytes.Buffer
+	r.WriteText(&buf)
+	if !strings.Contains(buf.String(), `hits_total{kind="a"} 8000`) {
+		t.Errorf("expected 8000 hits, got:\n%s", buf.String())
+	}
+}
+
+func TestRegistry_Panics(t *testing.T) {
+	tests := []struct {
+		Desc string
+		Do   func(r *metrics.Registry)
+	}{
+		{"invalid name", func(r *metrics.Registry) { r.NewCounter("bad-name", "") }},
+		{"duplicate name", func(r *metrics.Registry) { r.NewCounter("x", ""); r.NewCounter("x", "") }},
+		{"le label on a histogram", func(r *metrics.Registry) { r.NewHistogram("h", "", nil, "le") }},
+		{"unsorted buckets", func(r *metrics.Registry) { r.NewHistogram("h", "", []float64{2, 1}) }},
+		{"wrong number of label values", func(r *metrics.Registry) { r.NewCounter("c", "", "a").Inc() }},
+		{"counter going down", func(r *metrics.Registry) { r.NewCounter("c", "").Add(-1) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+			tt.Do(metrics.NewRegistry())
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package names
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+)
+
+// LoadFile reads a dictionary from path, named after the file without its extension.
+// The format follows the extension:
+//
+//	.json  either {"name": "#hex", ...} or [{"name": "...", "hex": "#hex"}, ...]
+//	.csv   name,colour rows with an optional name,hex header
+//	.txt   tab separated name and colour, with # comments and headers, as in xkcd's rgb.txt
+//
+// Colours may use any notation understood by colour.Parse.
+func LoadFile(path string) (*Dictionary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem opening colour dictionary")
+	}
+	defer f.Close()
+
+	ext := strings.ToLower(filepath.Ext(path))
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	var d *Dictionary
+	switch ext {
+	case ".json":
+		d, err = LoadJSON(name, f)
+	case ".csv":
+		d, err = LoadCSV(name, f)
+	case ".txt":
+		d, err = LoadText(name, f)
+	default:
+		return nil, errors.Errorf("unsupported colour dictionary format %q, expected .json, .csv or .txt", ext)
+	}
+	return d, errors.Wrapf(err, "problem loading colour dictionary %s", path)
+}
+
+// LoadJSON reads a JSON dictionary; see LoadFile for the accepted shapes.
+func LoadJSON(name string, r io.Reader) (*Dictionary, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, errors.Wrap(err, "invalid JSON")
+	}
+
+	var entries []Entry
+	var list []struct {
+		Name string `json:"name"`
+		Hex  string `json:"hex"`
+	}
+	var object map[string]string
+
+	switch {
+	case json.Unmarshal(raw, &list) == nil:
+		for i, e := range list {
+			c, err := colour.Parse(e.Hex)
+			if err != nil {
+				return nil, errors.Wrapf(err, "entry %d (%q)", i, e.Name)
+			}
+			entries = append(entries, Entry{Name: e.Name, Colour: c})
+		}
+	case json.Unmarshal(raw, &object) == nil:
+		keys := make([]string, 0, len(object))
+		for n := range object {
+			keys = append(keys, n)
+		}
+		sort.Strings(keys)
+		for _, n := range keys {
+			c, err := colour.Parse(object[n])
+			if err != nil {
+				return nil, errors.Wrapf(err, "entry %q", n)
+			}
+			entries = append(entries, Entry{Name: n, Colour: c})
+		}
+	default:
+		return nil, errors.New(`expected an object of "name": "colour" pairs or an array of {"name", "hex"} objects`)
+	}
+	return NewDictionary(name, entries)
+}
+
+// LoadCSV reads name,colour rows, skipping a name,hex header if present.
+func LoadCSV(name string, r io.Reader) (*Dictionary, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 2
+	cr.TrimLeadingSpace = true
+
+	var entries []Entry
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid CSV")
+		}
+		if line == 1 && strings.EqualFold(rec[0], "name") {
+			continue
+		}
+		c, err := colour.Parse(rec[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", line)
+		}
+		entries = append(entries, Entry{Name: rec[0], Colour: c})
+	}
+	return NewDictionary(name, entries)
+}
+
+// LoadText reads tab separated name and colour lines, ignoring blank lines, # comments and any header
+// lines without a tab before the first colour, such as the license line xkcd's rgb.txt starts with.
+func LoadText(name string, r io.Reader) (*Dictionary, error) {
+	var entries []Entry
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		tabbed := strings.Contains(text, "\t")
+		if text == "" || (!tabbed && (strings.HasPrefix(text, "#") || len(entries) == 0)) {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if len(fields) < 2 {
+			return nil, errors.Errorf("line %d: expected a name and a colour separated by a tab", line)
+		}
+		c, err := colour.Parse(strings.TrimSpace(fields[1]))
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", line)
+		}
+		entries = append(entries, Entry{Name: fields[0], Colour: c})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, errors.Wrap(err, "problem reading dictionary")
+	}
+	return NewDictionary(name, entries)
+}
@@ -0,0 +1,163 @@
+// Package names finds the closest human readable name for a colour in one or more
+// colour name dictionaries.
+package names
+
+import (
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+)
+
+// Names of the built in dictionaries.
+const (
+	CSS4 = "css4"
+	X11  = "x11"
+	XKCD = "xkcd"
+)
+
+// entry is a name and hex value as written in the built in dictionaries.
+type entry struct {
+	name string
+	hex  string
+}
+
+// Entry is a named colour in a Dictionary.
+type Entry struct {
+	Name   string
+	Colour colour.Colour
+	lab    colour.Lab
+}
+
+// Dictionary is a named list of colour names.
+type Dictionary struct {
+	Name    string
+	Entries []Entry
+}
+
+// Match is the result of a lookup.
+type Match struct {
+	Name       string        `json:"name"`
+	Dictionary string        `json:"dictionary"`
+	Colour     colour.Colour `json:"hex"`
+	// Distance is the CIEDE2000 difference between the looked up colour and the named one.
+	Distance float64 `json:"distance"`
+}
+
+// NewDictionary builds a Dictionary. Names are normalised to lower case and must be unique.
+func NewDictionary(name string, entries []Entry) (*Dictionary, error) {
+	if name == "" {
+		return nil, errors.New("dictionary needs a name")
+	}
+	if len(entries) == 0 {
+		return nil, errors.Errorf("dictionary %q has no colours", name)
+	}
+
+	d := &Dictionary{Name: name, Entries: make([]Entry, len(entries))}
+	seen := make(map[string]bool, len(entries))
+	for i, e := range entries {
+		e.Name = normalise(e.Name)
+		if e.Name == "" {
+			return nil, errors.Errorf("dictionary %q: colour %s has no name", name, e.Colour)
+		}
+		if seen[e.Name] {
+			return nil, errors.Errorf("dictionary %q: duplicate name %q", name, e.Name)
+		}
+		seen[e.Name] = true
+		e.lab = e.Colour.Lab()
+		d.Entries[i] = e
+	}
+	return d, nil
+}
+
+// Builtin returns the built in dictionary called name: css4, x11 or xkcd.
+func Builtin(name string) (*Dictionary, error) {
+	var entries []entry
+	switch name {
+	case CSS4:
+		entries = css4
+	case X11:
+		entries = x11
+	case XKCD:
+		entries = xkcd
+	default:
+		return nil, errors.Errorf("no built in colour dictionary called %q", name)
+	}
+
+	es := make([]Entry, len(entries))
+	for i, e := range entries {
+		es[i] = Entry{Name: e.name, Colour: colour.MustParse(e.hex)}
+	}
+	return NewDictionary(name, es)
+}
+
+// Lookup finds the nearest named colour across a set of dictionaries.
+type Lookup struct {
+	dictionaries []*Dictionary
+}
+
+// NewLookup searches dictionaries in order; on a tie the earlier dictionary wins.
+func NewLookup(dictionaries ...*Dictionary) *Lookup {
+	return &Lookup{dictionaries: dictionaries}
+}
+
+// NewDefaultLookup searches the CSS4, X11 and XKCD dictionaries.
+func NewDefaultLookup() *Lookup {
+	l := &Lookup{}
+	for _, name := range []string{CSS4, X11, XKCD} {
+		d, err := Builtin(name)
+		if err != nil {
+			panic(err)
+		}
+		l.dictionaries = append(l.dictionaries, d)
+	}
+	return l
+}
+
+// Add appends a dictionary to the search.
+func (l *Lookup) Add(d *Dictionary) {
+	l.dictionaries = append(l.dictionaries, d)
+}
+
+// Dictionaries returns the dictionaries searched, in order.
+func (l *Lookup) Dictionaries() []*Dictionary {
+	return l.dictionaries
+}
+
+// Nearest returns the named colour with the smallest CIEDE2000 difference from c.
+// It returns a zero Match when there are no dictionaries.
+func (l *Lookup) Nearest(c colour.Colour) Match {
+	lab := c.Lab()
+	best := Match{Distance: math.Inf(1)}
+	for _, d := range l.dictionaries {
+		for _, e := range d.Entries {
+			if dist := colour.DeltaE2000(lab, e.lab); dist < best.Distance {
+				best = Match{Name: e.Name, Dictionary: d.Name, Colour: e.Colour, Distance: dist}
+			}
+		}
+	}
+	if math.IsInf(best.Distance, 1) {
+		return Match{}
+	}
+	return best
+}
+
+// Find returns every colour called name, case insensitively, across all dictionaries.
+func (l *Lookup) Find(name string) []Match {
+	name = normalise(name)
+	var matches []Match
+	for _, d := range l.dictionaries {
+		for _, e := range d.Entries {
+			if e.Name == name {
+				matches = append(matches, Match{Name: e.Name, Dictionary: d.Name, Colour: e.Colour})
+			}
+		}
+	}
+	return matches
+}
+
+func normalise(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
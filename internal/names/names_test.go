@@ -0,0 +1,141 @@
+package names_test
+
+import (
+	"strings"
+	"testing"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/names"
+)
+
+func TestBuiltin(t *testing.T) {
+	for name, size := range map[string]int{names.CSS4: 148, names.X11: 143, names.XKCD: 949} {
+		d, err := names.Builtin(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(d.Entries) != size {
+			t.Errorf("%s: expected %d colours, got %d", name, size, len(d.Entries))
+		}
+	}
+	if _, err := names.Builtin("pantone"); err == nil {
+		t.Error("expected an error for an unknown dictionary")
+	}
+}
+
+func TestLookup_Nearest(t *testing.T) {
+	l := names.NewDefaultLookup()
+
+	tests := []struct {
+		Hex      string
+		WantName string
+		WantDict string
+	}{
+		{Hex: "#FF0000", WantName: "red", WantDict: names.CSS4},
+		{Hex: "#663399", WantName: "rebeccapurple", WantDict: names.CSS4},
+		{Hex: "#FE0000", WantName: "fire engine red", WantDict: names.XKCD},
+		{Hex: "#7E1E9C", WantName: "purple", WantDict: names.XKCD},
+		{Hex: "#B03060", WantName: "maroon", WantDict: names.X11},
+	}
+	for _, tt := range tests {
+		m := l.Nearest(colour.MustParse(tt.Hex))
+		if m.Name != tt.WantName || m.Dictionary != tt.WantDict {
+			t.Errorf("Nearest(%s) = %s/%s, want %s/%s", tt.Hex, m.Dictionary, m.Name, tt.WantDict, tt.WantName)
+		}
+	}
+
+	if m := names.NewLookup().Nearest(colour.RGB(1, 2, 3)); m.Name != "" {
+		t.Errorf("expected no match without dictionaries, got %+v", m)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		Desc    string
+		Load    func() (*names.Dictionary, error)
+		Want    int
+		WantErr string
+	}{
+		{
+			Desc: "json object",
+			Load: func() (*names.Dictionary, error) {
+				return names.LoadJSON("brand", strings.NewReader(`{"Brand Red": "#D00", "Brand Ink": "rgb(10, 10, 30)"}`))
+			},
+			Want: 2,
+		},
+		{
+			Desc: "json array",
+			Load: func() (*names.Dictionary, error) {
+				return names.LoadJSON("brand", strings.NewReader(`[{"name": "brand red", "hex": "#DD0000"}]`))
+			},
+			Want: 1,
+		},
+		{
+			Desc: "json bad colour",
+			Load: func() (*names.Dictionary, error) {
+				return names.LoadJSON("brand", strings.NewReader(`[{"name": "brand red", "hex": "#DD00"}, {"name": "oops", "hex": "#XYZ"}]`))
+			},
+			WantErr: `entry 1 ("oops")`,
+		},
+		{
+			Desc: "csv with header",
+			Load: func() (*names.Dictionary, error) {
+				return names.LoadCSV("brand", strings.NewReader("name,hex\nbrand red,#DD0000\nbrand ink,#0A0A1E\n"))
+			},
+			Want: 2,
+		},
+		{
+			Desc: "csv bad colour",
+			Load: func() (*names.Dictionary, error) {
+				return names.LoadCSV("brand", strings.NewReader("brand red,#DD0000\nbrand ink,#0A0A1\n"))
+			},
+			WantErr: "line 2",
+		},
+		{
+			Desc: "csv duplicate name",
+			Load: func() (*names.Dictionary, error) {
+				return names.LoadCSV("brand", strings.NewReader("Red,#DD0000\nred,#EE0000\n"))
+			},
+			WantErr: "duplicate name",
+		},
+		{
+			Desc: "xkcd rgb.txt",
+			Load: func() (*names.Dictionary, error) {
+				return names.LoadText("xkcd-full", strings.NewReader("License: http://creativecommons.org/publicdomain/zero/1.0/\ncloudy blue\t#acc2d9\t\ndark pastel green\t#56ae57\t\n"))
+			},
+			Want: 2,
+		},
+		{
+			Desc: "text with comments",
+			Load: func() (*names.Dictionary, error) {
+				return names.LoadText("brand", strings.NewReader("# brand colours\nbrand red\t#DD0000\n\n# dark\nbrand ink\t#0A0A1E\n"))
+			},
+			Want: 2,
+		},
+		{
+			Desc: "text line without a tab after the header",
+			Load: func() (*names.Dictionary, error) {
+				return names.LoadText("brand", strings.NewReader("Brand colours\nbrand red\t#DD0000\nbrand ink #0A0A1E\n"))
+			},
+			WantErr: "line 3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			d, err := tt.Load()
+			if tt.WantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.WantErr) {
+					t.Fatalf("expected an error containing %q, got %v", tt.WantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(d.Entries) != tt.Want {
+				t.Errorf("expected %d colours, got %d", tt.Want, len(d.Entries))
+			}
+		})
+	}
+}
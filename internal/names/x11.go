@@ -0,0 +1,148 @@
+package names
+
+// x11 is the X.Org rgb.txt colour list, without the numbered variants such as "gray50" or "red3".
+var x11 = []entry{
+	{"snow", "#FFFAFA"},
+	{"ghost white", "#F8F8FF"},
+	{"white smoke", "#F5F5F5"},
+	{"gainsboro", "#DCDCDC"},
+	{"floral white", "#FFFAF0"},
+	{"old lace", "#FDF5E6"},
+	{"linen", "#FAF0E6"},
+	{"antique white", "#FAEBD7"},
+	{"papaya whip", "#FFEFD5"},
+	{"blanched almond", "#FFEBCD"},
+	{"bisque", "#FFE4C4"},
+	{"peach puff", "#FFDAB9"},
+	{"navajo white", "#FFDEAD"},
+	{"moccasin", "#FFE4B5"},
+	{"cornsilk", "#FFF8DC"},
+	{"ivory", "#FFFFF0"},
+	{"lemon chiffon", "#FFFACD"},
+	{"seashell", "#FFF5EE"},
+	{"honeydew", "#F0FFF0"},
+	{"mint cream", "#F5FFFA"},
+	{"azure", "#F0FFFF"},
+	{"alice blue", "#F0F8FF"},
+	{"lavender", "#E6E6FA"},
+	{"lavender blush", "#FFF0F5"},
+	{"misty rose", "#FFE4E1"},
+	{"white", "#FFFFFF"},
+	{"black", "#000000"},
+	{"dark slate gray", "#2F4F4F"},
+	{"dark slate grey", "#2F4F4F"},
+	{"dim gray", "#696969"},
+	{"dim grey", "#696969"},
+	{"slate gray", "#708090"},
+	{"slate grey", "#708090"},
+	{"light slate gray", "#778899"},
+	{"light slate grey", "#778899"},
+	{"gray", "#BEBEBE"},
+	{"grey", "#BEBEBE"},
+	{"light grey", "#D3D3D3"},
+	{"light gray", "#D3D3D3"},
+	{"midnight blue", "#191970"},
+	{"navy", "#000080"},
+	{"navy blue", "#000080"},
+	{"cornflower blue", "#6495ED"},
+	{"dark slate blue", "#483D8B"},
+	{"slate blue", "#6A5ACD"},
+	{"medium slate blue", "#7B68EE"},
+	{"light slate blue", "#8470FF"},
+	{"medium blue", "#0000CD"},
+	{"royal blue", "#4169E1"},
+	{"blue", "#0000FF"},
+	{"dodger blue", "#1E90FF"},
+	{"deep sky blue", "#00BFFF"},
+	{"sky blue", "#87CEEB"},
+	{"light sky blue", "#87CEFA"},
+	{"steel blue", "#4682B4"},
+	{"light steel blue", "#B0C4DE"},
+	{"light blue", "#ADD8E6"},
+	{"powder blue", "#B0E0E6"},
+	{"pale turquoise", "#AFEEEE"},
+	{"dark turquoise", "#00CED1"},
+	{"medium turquoise", "#48D1CC"},
+	{"turquoise", "#40E0D0"},
+	{"cyan", "#00FFFF"},
+	{"light cyan", "#E0FFFF"},
+	{"cadet blue", "#5F9EA0"},
+	{"medium aquamarine", "#66CDAA"},
+	{"aquamarine", "#7FFFD4"},
+	{"dark green", "#006400"},
+	{"dark olive green", "#556B2F"},
+	{"dark sea green", "#8FBC8F"},
+	{"sea green", "#2E8B57"},
+	{"medium sea green", "#3CB371"},
+	{"light sea green", "#20B2AA"},
+	{"pale green", "#98FB98"},
+	{"spring green", "#00FF7F"},
+	{"lawn green", "#7CFC00"},
+	{"green", "#00FF00"},
+	{"chartreuse", "#7FFF00"},
+	{"medium spring green", "#00FA9A"},
+	{"green yellow", "#ADFF2F"},
+	{"lime green", "#32CD32"},
+	{"yellow green", "#9ACD32"},
+	{"forest green", "#228B22"},
+	{"olive drab", "#6B8E23"},
+	{"dark khaki", "#BDB76B"},
+	{"khaki", "#F0E68C"},
+	{"pale goldenrod", "#EEE8AA"},
+	{"light goldenrod yellow", "#FAFAD2"},
+	{"light yellow", "#FFFFE0"},
+	{"yellow", "#FFFF00"},
+	{"gold", "#FFD700"},
+	{"light goldenrod", "#EEDD82"},
+	{"goldenrod", "#DAA520"},
+	{"dark goldenrod", "#B8860B"},
+	{"rosy brown", "#BC8F8F"},
+	{"indian red", "#CD5C5C"},
+	{"saddle brown", "#8B4513"},
+	{"sienna", "#A0522D"},
+	{"peru", "#CD853F"},
+	{"burlywood", "#DEB887"},
+	{"beige", "#F5F5DC"},
+	{"wheat", "#F5DEB3"},
+	{"sandy brown", "#F4A460"},
+	{"tan", "#D2B48C"},
+	{"chocolate", "#D2691E"},
+	{"firebrick", "#B22222"},
+	{"brown", "#A52A2A"},
+	{"dark salmon", "#E9967A"},
+	{"salmon", "#FA8072"},
+	{"light salmon", "#FFA07A"},
+	{"orange", "#FFA500"},
+	{"dark orange", "#FF8C00"},
+	{"coral", "#FF7F50"},
+	{"light coral", "#F08080"},
+	{"tomato", "#FF6347"},
+	{"orange red", "#FF4500"},
+	{"red", "#FF0000"},
+	{"hot pink", "#FF69B4"},
+	{"deep pink", "#FF1493"},
+	{"pink", "#FFC0CB"},
+	{"light pink", "#FFB6C1"},
+	{"pale violet red", "#DB7093"},
+	{"maroon", "#B03060"},
+	{"medium violet red", "#C71585"},
+	{"violet red", "#D02090"},
+	{"magenta", "#FF00FF"},
+	{"violet", "#EE82EE"},
+	{"plum", "#DDA0DD"},
+	{"orchid", "#DA70D6"},
+	{"medium orchid", "#BA55D3"},
+	{"dark orchid", "#9932CC"},
+	{"dark violet", "#9400D3"},
+	{"blue violet", "#8A2BE2"},
+	{"purple", "#A020F0"},
+	{"medium purple", "#9370DB"},
+	{"thistle", "#D8BFD8"},
+	{"dark grey", "#A9A9A9"},
+	{"dark gray", "#A9A9A9"},
+	{"dark blue", "#00008B"},
+	{"dark cyan", "#008B8B"},
+	{"dark magenta", "#8B008B"},
+	{"dark red", "#8B0000"},
+	{"light green", "#90EE90"},
+}
@@ -0,0 +1,955 @@
+package names
+
+// xkcd is the xkcd colour survey's 949 names, https://xkcd.com/color/rgb/, most commonly given first, as
+// published at https://xkcd.com/color/rgb.txt under CC0.
+var xkcd = []entry{
+	{"purple", "#7E1E9C"},
+	{"green", "#15B01A"},
+	{"blue", "#0343DF"},
+	{"pink", "#FF81C0"},
+	{"brown", "#653700"},
+	{"red", "#E50000"},
+	{"light blue", "#95D0FC"},
+	{"teal", "#029386"},
+	{"orange", "#F97306"},
+	{"light green", "#96F97B"},
+	{"magenta", "#C20078"},
+	{"yellow", "#FFFF14"},
+	{"sky blue", "#75BBFD"},
+	{"grey", "#929591"},
+	{"lime green", "#89FE05"},
+	{"light purple", "#BF77F6"},
+	{"violet", "#9A0EEA"},
+	{"dark green", "#033500"},
+	{"turquoise", "#06C2AC"},
+	{"lavender", "#C79FEF"},
+	{"dark blue", "#00035B"},
+	{"tan", "#D1B26F"},
+	{"cyan", "#00FFFF"},
+	{"aqua", "#13EAC9"},
+	{"forest green", "#06470C"},
+	{"mauve", "#AE7181"},
+	{"dark purple", "#35063E"},
+	{"bright green", "#01FF07"},
+	{"maroon", "#650021"},
+	{"olive", "#6E750E"},
+	{"salmon", "#FF796C"},
+	{"beige", "#E6DAA6"},
+	{"royal blue", "#0504AA"},
+	{"navy blue", "#001146"},
+	{"lilac", "#CEA2FD"},
+	{"black", "#000000"},
+	{"hot pink", "#FF028D"},
+	{"light brown", "#AD8150"},
+	{"pale green", "#C7FDB5"},
+	{"peach", "#FFB07C"},
+	{"olive green", "#677A04"},
+	{"dark pink", "#CB416B"},
+	{"periwinkle", "#8E82FE"},
+	{"sea green", "#53FCA1"},
+	{"lime", "#AAFF32"},
+	{"indigo", "#380282"},
+	{"mustard", "#CEB301"},
+	{"light pink", "#FFD1DF"},
+	{"rose", "#CF6275"},
+	{"bright blue", "#0165FC"},
+	{"neon green", "#0CFF0C"},
+	{"burnt orange", "#C04E01"},
+	{"aquamarine", "#04D8B2"},
+	{"navy", "#01153E"},
+	{"grass green", "#3F9B0B"},
+	{"pale blue", "#D0FEFE"},
+	{"dark red", "#840000"},
+	{"bright purple", "#BE03FD"},
+	{"yellow green", "#C0FB2D"},
+	{"baby blue", "#A2CFFE"},
+	{"gold", "#DBB40C"},
+	{"mint green", "#8FFF9F"},
+	{"plum", "#580F41"},
+	{"royal purple", "#4B006E"},
+	{"brick red", "#8F1402"},
+	{"dark teal", "#014D4E"},
+	{"burgundy", "#610023"},
+	{"khaki", "#AAA662"},
+	{"blue green", "#137E6D"},
+	{"seafoam green", "#7AF9AB"},
+	{"kelly green", "#02AB2E"},
+	{"puke green", "#9AAE07"},
+	{"pea green", "#8EAB12"},
+	{"taupe", "#B9A281"},
+	{"dark brown", "#341C02"},
+	{"deep purple", "#36013F"},
+	{"chartreuse", "#C1F80A"},
+	{"bright pink", "#FE01B1"},
+	{"light orange", "#FDAA48"},
+	{"mint", "#9FFEB0"},
+	{"pastel green", "#B0FF9D"},
+	{"sand", "#E2CA76"},
+	{"dark orange", "#C65102"},
+	{"spring green", "#A9F971"},
+	{"puce", "#A57E52"},
+	{"seafoam", "#80F9AD"},
+	{"grey blue", "#6B8BA4"},
+	{"army green", "#4B5D16"},
+	{"dark grey", "#363737"},
+	{"dark yellow", "#D5B60A"},
+	{"goldenrod", "#FAC205"},
+	{"slate", "#516572"},
+	{"light teal", "#90E4C1"},
+	{"rust", "#A83C09"},
+	{"deep blue", "#040273"},
+	{"pale pink", "#FFCFDC"},
+	{"cerulean", "#0485D1"},
+	{"light red", "#FF474C"},
+	{"mustard yellow", "#D2BD0A"},
+	{"ochre", "#BF9005"},
+	{"pale yellow", "#FFFF84"},
+	{"crimson", "#8C000F"},
+	{"fuchsia", "#ED0DD9"},
+	{"hunter green", "#0B4008"},
+	{"blue grey", "#607C8E"},
+	{"slate blue", "#5B7C99"},
+	{"pale purple", "#B790D4"},
+	{"sea blue", "#047495"},
+	{"pinkish purple", "#D648D7"},
+	{"puke", "#A5A502"},
+	{"light grey", "#D8DCD6"},
+	{"leaf green", "#5CA904"},
+	{"light yellow", "#FFFE7A"},
+	{"eggplant", "#380835"},
+	{"steel blue", "#5A7D9A"},
+	{"moss green", "#658B38"},
+	{"robin's egg blue", "#98EFF9"},
+	{"white", "#FFFFFF"},
+	{"grey green", "#789B73"},
+	{"sage", "#87AE73"},
+	{"brick", "#A03623"},
+	{"burnt sienna", "#B04E0F"},
+	{"reddish brown", "#7F2B0A"},
+	{"cream", "#FFFFC2"},
+	{"coral", "#FC5A50"},
+	{"ocean blue", "#03719C"},
+	{"greenish", "#40A368"},
+	{"dark magenta", "#960056"},
+	{"red orange", "#FD3C06"},
+	{"bluish purple", "#703BE7"},
+	{"midnight blue", "#020035"},
+	{"light violet", "#D6B4FC"},
+	{"dusty rose", "#C0737A"},
+	{"medium blue", "#2C6FBB"},
+	{"greenish yellow", "#CDFD02"},
+	{"yellowish green", "#B0DD16"},
+	{"purplish blue", "#601EF9"},
+	{"greyish blue", "#5E819D"},
+	{"grape", "#6C3461"},
+	{"light olive", "#ACBF69"},
+	{"cornflower blue", "#5170D7"},
+	{"pinkish red", "#F10C45"},
+	{"bright red", "#FF000D"},
+	{"azure", "#069AF3"},
+	{"blue purple", "#5729CE"},
+	{"dark turquoise", "#045C5A"},
+	{"electric blue", "#0652FF"},
+	{"off white", "#FFFFE4"},
+	{"powder blue", "#B1D1FC"},
+	{"wine", "#80013F"},
+	{"dull green", "#74A662"},
+	{"apple green", "#76CD26"},
+	{"light turquoise", "#7EF4CC"},
+	{"neon purple", "#BC13FE"},
+	{"cobalt", "#1E488F"},
+	{"pinkish", "#D46A7E"},
+	{"olive drab", "#6F7632"},
+	{"dark cyan", "#0A888A"},
+	{"purple blue", "#632DE9"},
+	{"dark violet", "#34013F"},
+	{"dark lavender", "#856798"},
+	{"forrest green", "#154406"},
+	{"vomit", "#A2A415"},
+	{"pale orange", "#FFA756"},
+	{"greenish blue", "#0B8B87"},
+	{"dark tan", "#AF884A"},
+	{"green blue", "#06B48B"},
+	{"bluish green", "#10A674"},
+	{"pastel blue", "#A2BFFE"},
+	{"moss", "#769958"},
+	{"grass", "#5CAC2D"},
+	{"deep pink", "#CB0162"},
+	{"blood red", "#980002"},
+	{"sage green", "#88B378"},
+	{"aqua blue", "#02D8E9"},
+	{"terracotta", "#CA6641"},
+	{"pastel purple", "#CAA0FF"},
+	{"sienna", "#A9561E"},
+	{"dark olive", "#373E02"},
+	{"green yellow", "#C9FF27"},
+	{"scarlet", "#BE0119"},
+	{"greyish green", "#82A67D"},
+	{"chocolate", "#3D1C02"},
+	{"blue violet", "#5D06E9"},
+	{"cornflower", "#6A79F7"},
+	{"baby pink", "#FFB7CE"},
+	{"charcoal", "#343837"},
+	{"pine green", "#0A481E"},
+	{"pumpkin", "#E17701"},
+	{"greenish brown", "#696112"},
+	{"red brown", "#8B2E16"},
+	{"brownish green", "#6A6E09"},
+	{"tangerine", "#FF9408"},
+	{"salmon pink", "#FE7B7C"},
+	{"aqua green", "#12E193"},
+	{"raspberry", "#B00149"},
+	{"greyish purple", "#887191"},
+	{"rose pink", "#F7879A"},
+	{"neon pink", "#FE019A"},
+	{"cobalt blue", "#030AA7"},
+	{"orange brown", "#BE6400"},
+	{"deep red", "#9A0200"},
+	{"orange red", "#FD411E"},
+	{"dirty yellow", "#CDC50A"},
+	{"orchid", "#C875C4"},
+	{"reddish pink", "#FE2C54"},
+	{"reddish purple", "#910951"},
+	{"yellow orange", "#FCB001"},
+	{"light cyan", "#ACFFFC"},
+	{"sky", "#82CAFC"},
+	{"light magenta", "#FA5FF7"},
+	{"pale red", "#D9544D"},
+	{"emerald", "#01A049"},
+	{"dark beige", "#AC9362"},
+	{"ugly green", "#7A9703"},
+	{"jade", "#1FA774"},
+	{"greenish grey", "#96AE8D"},
+	{"dark salmon", "#C85A53"},
+	{"purplish pink", "#CE5DAE"},
+	{"dark aqua", "#05696B"},
+	{"brownish orange", "#CB7723"},
+	{"light olive green", "#A4BE5C"},
+	{"light aqua", "#8CFFDB"},
+	{"clay", "#B66A50"},
+	{"medium green", "#39AD48"},
+	{"burnt umber", "#A0450E"},
+	{"dull blue", "#49759C"},
+	{"pale brown", "#B1916E"},
+	{"emerald green", "#028F1E"},
+	{"brownish", "#9C6D57"},
+	{"mud", "#735C12"},
+	{"dark rose", "#B5485D"},
+	{"brownish red", "#9E3623"},
+	{"pink purple", "#DB4BDA"},
+	{"pinky purple", "#C94CBE"},
+	{"camo green", "#526525"},
+	{"faded green", "#7BB274"},
+	{"dusty pink", "#D58A94"},
+	{"purple pink", "#E03FD8"},
+	{"vomit green", "#89A203"},
+	{"deep green", "#02590F"},
+	{"reddish orange", "#F8481C"},
+	{"mahogany", "#4A0100"},
+	{"aubergine", "#3D0734"},
+	{"dull pink", "#D5869D"},
+	{"evergreen", "#05472A"},
+	{"dark sky blue", "#448EE4"},
+	{"very light green", "#D1FFBD"},
+	{"pastel pink", "#FFBACD"},
+	{"grey purple", "#826D8C"},
+	{"very light blue", "#D5FFFF"},
+	{"dark mauve", "#874C62"},
+	{"cadet blue", "#4E7496"},
+	{"ice blue", "#D7FFFE"},
+	{"light tan", "#FBEEAC"},
+	{"dirty green", "#667E2C"},
+	{"neon blue", "#04D9FF"},
+	{"wine red", "#7B0323"},
+	{"chocolate brown", "#411900"},
+	{"dull purple", "#84597E"},
+	{"yellow brown", "#B79400"},
+	{"denim", "#3B638C"},
+	{"eggshell", "#FFFFD4"},
+	{"jungle green", "#048243"},
+	{"dark peach", "#DE7E5D"},
+	{"poop", "#7F5E00"},
+	{"umber", "#B26400"},
+	{"light lavender", "#DFC5FE"},
+	{"bright yellow", "#FFFD01"},
+	{"golden yellow", "#FEC615"},
+	{"dusty blue", "#5A86AD"},
+	{"electric green", "#21FC0D"},
+	{"lighter green", "#75FD63"},
+	{"slate grey", "#59656D"},
+	{"teal green", "#25A36F"},
+	{"marine blue", "#01386A"},
+	{"avocado", "#90B134"},
+	{"terra cotta", "#C9643B"},
+	{"dusty purple", "#825F87"},
+	{"light maroon", "#A24857"},
+	{"reddish", "#C44240"},
+	{"dark lilac", "#9C6DA5"},
+	{"dark periwinkle", "#665FD1"},
+	{"bluish grey", "#748B97"},
+	{"puke yellow", "#C2BE0E"},
+	{"purplish", "#94568C"},
+	{"ultramarine", "#2000B1"},
+	{"barney purple", "#A00498"},
+	{"forest", "#0B5509"},
+	{"pea soup", "#929901"},
+	{"brownish yellow", "#C9B003"},
+	{"bright teal", "#01F9C6"},
+	{"bluegreen", "#017A79"},
+	{"green brown", "#544E03"},
+	{"blurple", "#5539CC"},
+	{"light sky blue", "#C6FCFF"},
+	{"periwinkle blue", "#8F99FB"},
+	{"pale violet", "#CEAEFA"},
+	{"true blue", "#010FCC"},
+	{"green grey", "#77926F"},
+	{"grey brown", "#7F7053"},
+	{"dark olive green", "#3C4D03"},
+	{"apricot", "#FFB16D"},
+	{"faded purple", "#916E99"},
+	{"cerise", "#DE0C62"},
+	{"khaki green", "#728639"},
+	{"burnt red", "#9F2305"},
+	{"light forest green", "#4F9153"},
+	{"violet blue", "#510AC9"},
+	{"pale lavender", "#EECFFE"},
+	{"acid green", "#8FFE09"},
+	{"purple grey", "#866F85"},
+	{"lemon", "#FDFF52"},
+	{"bright orange", "#FF5B00"},
+	{"soft green", "#6FC276"},
+	{"blush", "#F29E8E"},
+	{"yellowish brown", "#9B7A01"},
+	{"fluorescent green", "#08FF08"},
+	{"electric purple", "#AA23FF"},
+	{"steel", "#738595"},
+	{"dull orange", "#D8863B"},
+	{"muddy green", "#657432"},
+	{"marigold", "#FCC006"},
+	{"ocean", "#017B92"},
+	{"light mauve", "#C292A1"},
+	{"bordeaux", "#7B002C"},
+	{"light blue green", "#7EFBB3"},
+	{"yellowish", "#FAEE66"},
+	{"snot green", "#9DC100"},
+	{"light lime green", "#B9FF66"},
+	{"drab green", "#749551"},
+	{"faded blue", "#658CBB"},
+	{"dark forest green", "#002D04"},
+	{"hot purple", "#CB00F5"},
+	{"dark maroon", "#3C0008"},
+	{"brown green", "#706C11"},
+	{"swamp green", "#748500"},
+	{"light indigo", "#6D5ACF"},
+	{"purpley blue", "#5F34E7"},
+	{"lightish blue", "#3D7AFD"},
+	{"teal blue", "#01889F"},
+	{"denim blue", "#3B5B92"},
+	{"dark lime green", "#7EBD01"},
+	{"dull yellow", "#EEDC5B"},
+	{"pistachio", "#C0FA8B"},
+	{"lemon yellow", "#FDFF38"},
+	{"red violet", "#9E0168"},
+	{"dusky pink", "#CC7A8B"},
+	{"dirt", "#8A6E45"},
+	{"very dark green", "#062E03"},
+	{"medium purple", "#9E43A2"},
+	{"shit", "#7F5F00"},
+	{"dark mustard", "#A88905"},
+	{"pea soup green", "#94A617"},
+	{"bubblegum pink", "#FE83CC"},
+	{"barbie pink", "#FE46A5"},
+	{"military green", "#667C3E"},
+	{"pale teal", "#82CBB2"},
+	{"bronze", "#A87900"},
+	{"pinky red", "#FC2647"},
+	{"dull red", "#BB3F3F"},
+	{"darkish blue", "#014182"},
+	{"bluish", "#2976BB"},
+	{"dark gold", "#B59410"},
+	{"yellowy green", "#BFF128"},
+	{"pine", "#2B5D34"},
+	{"dark blue green", "#005249"},
+	{"dirty pink", "#CA7B80"},
+	{"slate green", "#658D6D"},
+	{"prussian blue", "#004577"},
+	{"bright violet", "#AD0AFD"},
+	{"lighter purple", "#A55AF4"},
+	{"steel grey", "#6F828A"},
+	{"russet", "#A13905"},
+	{"vermillion", "#F4320C"},
+	{"greyish brown", "#7A6A4F"},
+	{"red purple", "#820747"},
+	{"red pink", "#FA2A55"},
+	{"bright turquoise", "#0FFEF9"},
+	{"golden brown", "#B27A01"},
+	{"cerulean blue", "#056EEE"},
+	{"soft blue", "#6488EA"},
+	{"easter green", "#8CFD7E"},
+	{"amber", "#FEB308"},
+	{"mid blue", "#276AB3"},
+	{"shit brown", "#7B5804"},
+	{"hospital green", "#9BE5AA"},
+	{"purpleish blue", "#6140EF"},
+	{"purply blue", "#661AEE"},
+	{"silver", "#C5C9C7"},
+	{"sickly green", "#94B21C"},
+	{"melon", "#FF7855"},
+	{"dusky rose", "#BA6873"},
+	{"brown orange", "#B96902"},
+	{"darkish green", "#287C37"},
+	{"cranberry", "#9E003A"},
+	{"purpleish", "#98568D"},
+	{"ecru", "#FEFFCA"},
+	{"mocha", "#9D7651"},
+	{"bright magenta", "#FF08E8"},
+	{"coffee", "#A6814C"},
+	{"sepia", "#985E2B"},
+	{"faded red", "#D3494E"},
+	{"canary yellow", "#FFFE40"},
+	{"bluey purple", "#6241C7"},
+	{"pastel yellow", "#FFFE71"},
+	{"pale turquoise", "#A5FBD5"},
+	{"greyish pink", "#C88D94"},
+	{"marine", "#042E60"},
+	{"purplish grey", "#7A687F"},
+	{"camel", "#C69F59"},
+	{"brownish grey", "#86775F"},
+	{"burnt yellow", "#D5AB09"},
+	{"cherry red", "#F7022A"},
+	{"orangey brown", "#B16002"},
+	{"soft pink", "#FDB0C0"},
+	{"dark sea green", "#11875D"},
+	{"aqua marine", "#2EE8BB"},
+	{"robin egg blue", "#8AF1FE"},
+	{"light sea green", "#98F6B0"},
+	{"mud brown", "#60460F"},
+	{"sandstone", "#C9AE74"},
+	{"british racing green", "#05480D"},
+	{"faded pink", "#DE9DAC"},
+	{"maize", "#F4D054"},
+	{"ocre", "#C69C04"},
+	{"orange yellow", "#FFAD01"},
+	{"dark khaki", "#9B8F55"},
+	{"light lime", "#AEFD6C"},
+	{"bright light blue", "#26F7FD"},
+	{"jade green", "#2BAF6A"},
+	{"barney", "#AC1DB8"},
+	{"adobe", "#BD6C48"},
+	{"minty green", "#0BF77D"},
+	{"light navy blue", "#2E5A88"},
+	{"dusty green", "#76A973"},
+	{"very dark blue", "#000133"},
+	{"ocean green", "#3D9973"},
+	{"mustard green", "#A8B504"},
+	{"poop brown", "#7A5901"},
+	{"olive brown", "#645403"},
+	{"pink red", "#F5054F"},
+	{"light navy", "#155084"},
+	{"very light purple", "#F6CEFC"},
+	{"ivory", "#FFFFCB"},
+	{"bright lavender", "#C760FF"},
+	{"bright aqua", "#0BF9EA"},
+	{"robin's egg", "#6DEDFD"},
+	{"muted green", "#5FA052"},
+	{"medium brown", "#7F5112"},
+	{"copper", "#B66325"},
+	{"dark lime", "#84B701"},
+	{"strawberry", "#FB2943"},
+	{"dirt brown", "#836539"},
+	{"celery", "#C1FD95"},
+	{"bright sky blue", "#02CCFE"},
+	{"poo brown", "#885F01"},
+	{"pinkish brown", "#B17261"},
+	{"celadon", "#BEFDB7"},
+	{"bright lime green", "#65FE08"},
+	{"auburn", "#9A3001"},
+	{"shocking pink", "#FE02A2"},
+	{"mulberry", "#920A4E"},
+	{"carolina blue", "#8AB8FE"},
+	{"lightish green", "#61E160"},
+	{"light lilac", "#EDC8FF"},
+	{"pale olive", "#B9CC81"},
+	{"pumpkin orange", "#FB7D07"},
+	{"yellow ochre", "#CB9D06"},
+	{"fire engine red", "#FE0002"},
+	{"deep sky blue", "#0D75F8"},
+	{"watermelon", "#FD4659"},
+	{"bottle green", "#044A05"},
+	{"very dark purple", "#2A0134"},
+	{"wheat", "#FBDD7E"},
+	{"murky green", "#6C7A0E"},
+	{"brownish purple", "#76424E"},
+	{"kermit green", "#5CB200"},
+	{"primary blue", "#0804F9"},
+	{"orangey red", "#FA4224"},
+	{"pale lilac", "#E4CBFF"},
+	{"rust red", "#AA2704"},
+	{"dirty orange", "#C87606"},
+	{"pinkish grey", "#C8ACA9"},
+	{"light plum", "#9D5783"},
+	{"greeny blue", "#42B395"},
+	{"dark navy", "#000435"},
+	{"pink/purple", "#EF1DE7"},
+	{"irish green", "#019529"},
+	{"baby poop", "#937C00"},
+	{"slime green", "#99CC04"},
+	{"purplish red", "#B0054B"},
+	{"rouge", "#AB1239"},
+	{"light rose", "#FFC5CB"},
+	{"drab", "#828344"},
+	{"dark navy blue", "#00022E"},
+	{"light yellow green", "#CCFD7F"},
+	{"easter purple", "#C071FE"},
+	{"snot", "#ACBB0D"},
+	{"light salmon", "#FEA993"},
+	{"purpley pink", "#C83CB9"},
+	{"poo", "#8F7303"},
+	{"berry", "#990F4B"},
+	{"medium grey", "#7D7F7C"},
+	{"brown red", "#922B05"},
+	{"blood", "#770001"},
+	{"soft purple", "#A66FB5"},
+	{"grey pink", "#C3909B"},
+	{"bluey green", "#2BB179"},
+	{"midnight", "#03012D"},
+	{"dark indigo", "#1F0954"},
+	{"warm grey", "#978A84"},
+	{"sandy brown", "#C4A661"},
+	{"cherry", "#CF0234"},
+	{"blue/purple", "#5A06EF"},
+	{"gunmetal", "#536267"},
+	{"deep violet", "#490648"},
+	{"tree green", "#2A7E19"},
+	{"orangish brown", "#B25F03"},
+	{"shamrock green", "#02C14D"},
+	{"orangish red", "#F43605"},
+	{"greeny yellow", "#C6F808"},
+	{"ugly yellow", "#D0C101"},
+	{"french blue", "#436BAD"},
+	{"dusky purple", "#895B7B"},
+	{"butter yellow", "#FFFD74"},
+	{"light beige", "#FFFEB6"},
+	{"golden", "#F5BF03"},
+	{"dusky blue", "#475F94"},
+	{"lightblue", "#7BC8F6"},
+	{"purply pink", "#F075E6"},
+	{"off green", "#6BA353"},
+	{"ocher", "#BF9B0C"},
+	{"milk chocolate", "#7F4E1E"},
+	{"light peach", "#FFD8B1"},
+	{"deep magenta", "#A0025C"},
+	{"caramel", "#AF6F09"},
+	{"greenish teal", "#32BF84"},
+	{"pale lime", "#BEFD73"},
+	{"purple red", "#990147"},
+	{"blueberry", "#464196"},
+	{"asparagus", "#77AB56"},
+	{"pale grey", "#FDFDFE"},
+	{"light grey blue", "#9DBCD4"},
+	{"pale lime green", "#B1FF65"},
+	{"grassy green", "#419C03"},
+	{"mossy green", "#638B27"},
+	{"earth", "#A2653E"},
+	{"deep orange", "#DC4D01"},
+	{"pale aqua", "#B8FFEB"},
+	{"rose red", "#BE013C"},
+	{"stone", "#ADA587"},
+	{"rusty orange", "#CD5909"},
+	{"pea", "#A4BF20"},
+	{"sick green", "#9DB92C"},
+	{"chestnut", "#742802"},
+	{"blue/green", "#0F9B8E"},
+	{"amethyst", "#9B5FC0"},
+	{"dark mint green", "#20C073"},
+	{"pale rose", "#FDC1C5"},
+	{"muted blue", "#3B719F"},
+	{"fawn", "#CFAF7B"},
+	{"buff", "#FEF69E"},
+	{"turquoise green", "#04F489"},
+	{"muddy brown", "#886806"},
+	{"sea", "#3C9992"},
+	{"tomato", "#EF4026"},
+	{"carnation pink", "#FF7FA7"},
+	{"banana", "#FFFF7E"},
+	{"neon yellow", "#CFFF04"},
+	{"greyish", "#A8A495"},
+	{"mid green", "#50A747"},
+	{"muted purple", "#805B87"},
+	{"electric pink", "#FF0490"},
+	{"sandy", "#F1DA7A"},
+	{"ugly pink", "#CD7584"},
+	{"turquoise blue", "#06B1C4"},
+	{"light burgundy", "#A8415B"},
+	{"greenish tan", "#BCCB7A"},
+	{"dark mint", "#48C072"},
+	{"light urple", "#B36FF6"},
+	{"midnight purple", "#280137"},
+	{"pinkish orange", "#FF724C"},
+	{"pear", "#CBF85F"},
+	{"dark plum", "#3F012C"},
+	{"tealish", "#24BCA8"},
+	{"perrywinkle", "#8F8CE7"},
+	{"yellowish orange", "#FFAB0F"},
+	{"pastel orange", "#FF964F"},
+	{"iris", "#6258C4"},
+	{"ultramarine blue", "#1805DB"},
+	{"navy green", "#35530A"},
+	{"seaweed", "#18D17B"},
+	{"kiwi", "#9CEF43"},
+	{"fluro green", "#0AFF02"},
+	{"bright light green", "#2DFE54"},
+	{"vivid green", "#2FEF10"},
+	{"frog green", "#58BC08"},
+	{"dull brown", "#876E4B"},
+	{"dusk", "#4E5481"},
+	{"mustard brown", "#AC7E04"},
+	{"leafy green", "#51B73B"},
+	{"cool blue", "#4984B8"},
+	{"almost black", "#070D0D"},
+	{"yellow/green", "#C8FD3D"},
+	{"heliotrope", "#D94FF5"},
+	{"green apple", "#5EDC1F"},
+	{"baby poop green", "#8F9805"},
+	{"apple", "#6ECB3C"},
+	{"purpleish pink", "#DF4EC8"},
+	{"night blue", "#040348"},
+	{"merlot", "#730039"},
+	{"lightgreen", "#76FF7B"},
+	{"tomato red", "#EC2D01"},
+	{"key lime", "#AEFF6E"},
+	{"pale cyan", "#B7FFFA"},
+	{"vomit yellow", "#C7C10C"},
+	{"purplish brown", "#6B4247"},
+	{"bubblegum", "#FF6CB5"},
+	{"shamrock", "#01B44C"},
+	{"mango", "#FFA62B"},
+	{"lime yellow", "#D0FE1D"},
+	{"hot green", "#25FF29"},
+	{"grape purple", "#5D1451"},
+	{"faded orange", "#F0944D"},
+	{"avocado green", "#87A922"},
+	{"peacock blue", "#016795"},
+	{"weird green", "#3AE57F"},
+	{"bright lilac", "#C95EFB"},
+	{"fern green", "#548D44"},
+	{"dirty blue", "#3F829D"},
+	{"rust orange", "#C45508"},
+	{"heather", "#A484AC"},
+	{"deep teal", "#00555A"},
+	{"dark seafoam", "#1FB57A"},
+	{"baby poo", "#AB9004"},
+	{"yellowgreen", "#BBF90F"},
+	{"light sage", "#BCECAC"},
+	{"light aquamarine", "#7BFDC7"},
+	{"spearmint", "#1EF876"},
+	{"bright lime", "#87FD05"},
+	{"vibrant green", "#0ADD08"},
+	{"very pale green", "#CFFDBC"},
+	{"faded yellow", "#FEFF7F"},
+	{"bile", "#B5C306"},
+	{"viridian", "#1E9167"},
+	{"very light pink", "#FFF4F2"},
+	{"puke brown", "#947706"},
+	{"medium pink", "#F36196"},
+	{"ugly purple", "#A442A0"},
+	{"sunshine yellow", "#FFFD37"},
+	{"seaweed green", "#35AD6B"},
+	{"light periwinkle", "#C1C6FC"},
+	{"lemon green", "#ADF802"},
+	{"greeny brown", "#696006"},
+	{"dark grey blue", "#29465B"},
+	{"bright olive", "#9CBB04"},
+	{"turtle green", "#75B84F"},
+	{"pale sky blue", "#BDF6FE"},
+	{"light mustard", "#F7D560"},
+	{"diarrhea", "#9F8303"},
+	{"dark aquamarine", "#017371"},
+	{"brownish pink", "#C27E79"},
+	{"baby shit green", "#889717"},
+	{"purpley", "#8756E4"},
+	{"greyblue", "#77A1B5"},
+	{"hot magenta", "#F504C9"},
+	{"blue/grey", "#758DA3"},
+	{"pale", "#FFF9D0"},
+	{"cool green", "#33B864"},
+	{"sandy yellow", "#FDEE73"},
+	{"eggshell blue", "#C4FFF7"},
+	{"barf green", "#94AC02"},
+	{"baby green", "#8CFF9E"},
+	{"vibrant purple", "#AD03DE"},
+	{"brown grey", "#8D8468"},
+	{"water blue", "#0E87CC"},
+	{"lipstick red", "#C0022F"},
+	{"banana yellow", "#FAFE4B"},
+	{"wisteria", "#A87DC2"},
+	{"purple brown", "#673A3F"},
+	{"brown yellow", "#B29705"},
+	{"purple/pink", "#D725DE"},
+	{"lemon lime", "#BFFE28"},
+	{"grey/blue", "#647D8E"},
+	{"dusty red", "#B9484E"},
+	{"deep rose", "#C74767"},
+	{"dark seafoam green", "#3EAF76"},
+	{"muddy yellow", "#BFAC05"},
+	{"carnation", "#FD798F"},
+	{"yellowy brown", "#AE8B0C"},
+	{"violet red", "#A50055"},
+	{"twilight blue", "#0A437A"},
+	{"pure blue", "#0203E2"},
+	{"lightish red", "#FE2F4A"},
+	{"brick orange", "#C14A09"},
+	{"velvet", "#750851"},
+	{"sunflower", "#FFC512"},
+	{"light mint green", "#A6FBB2"},
+	{"light grass green", "#9AF764"},
+	{"lavender blue", "#8B88F8"},
+	{"rusty red", "#AF2F0D"},
+	{"lightish purple", "#A552E6"},
+	{"dried blood", "#4B0101"},
+	{"light blue grey", "#B7C9E2"},
+	{"leaf", "#71AA34"},
+	{"orangish", "#FC824A"},
+	{"pale olive green", "#B1D27B"},
+	{"off yellow", "#F1F33F"},
+	{"dusty orange", "#F0833A"},
+	{"butter", "#FFFF81"},
+	{"royal", "#0C1793"},
+	{"petrol", "#005F6A"},
+	{"greenish cyan", "#2AFEB7"},
+	{"duck egg blue", "#C3FBF4"},
+	{"bubble gum pink", "#FF69AF"},
+	{"bluegrey", "#85A3B2"},
+	{"warm brown", "#964E02"},
+	{"twilight", "#4E518B"},
+	{"saffron", "#FEB209"},
+	{"purple/blue", "#5D21D0"},
+	{"dark sand", "#A88F59"},
+	{"vibrant blue", "#0339F8"},
+	{"putty", "#BEAE8A"},
+	{"lawn green", "#4DA409"},
+	{"camouflage green", "#4B6113"},
+	{"blush pink", "#FE828C"},
+	{"reddy brown", "#6E1005"},
+	{"darkish red", "#A90308"},
+	{"algae green", "#21C36F"},
+	{"dark coral", "#CF524E"},
+	{"bright cyan", "#41FDFE"},
+	{"piss yellow", "#DDD618"},
+	{"pastel red", "#DB5856"},
+	{"greenish turquoise", "#00FBB0"},
+	{"dark", "#1B2431"},
+	{"ruby", "#CA0147"},
+	{"poop green", "#6F7C00"},
+	{"orangered", "#FE420F"},
+	{"dandelion", "#FEDF08"},
+	{"claret", "#680018"},
+	{"pale mauve", "#FED0FC"},
+	{"lipstick", "#D5174E"},
+	{"rosa", "#FE86A4"},
+	{"darkblue", "#030764"},
+	{"tan brown", "#AB7E4C"},
+	{"shit green", "#758000"},
+	{"red wine", "#8C0034"},
+	{"pinky", "#FC86AA"},
+	{"mud green", "#606602"},
+	{"light greenish blue", "#63F7B4"},
+	{"dull teal", "#5F9E8F"},
+	{"deep lavender", "#8D5EB7"},
+	{"vivid blue", "#152EFF"},
+	{"raw umber", "#A75E09"},
+	{"light mint", "#B6FFBB"},
+	{"light light blue", "#CAFFFB"},
+	{"highlighter green", "#1BFC06"},
+	{"greeny grey", "#7EA07A"},
+	{"bluey grey", "#89A0B0"},
+	{"algae", "#54AC68"},
+	{"sap green", "#5C8B15"},
+	{"pale salmon", "#FFB19A"},
+	{"metallic blue", "#4F738E"},
+	{"ice", "#D6FFFA"},
+	{"gross green", "#A0BF16"},
+	{"dodger blue", "#3E82FC"},
+	{"warm pink", "#FB5581"},
+	{"light green blue", "#56FCA2"},
+	{"flat green", "#699D4C"},
+	{"dark blue grey", "#1F3B4D"},
+	{"clay brown", "#B2713D"},
+	{"sand yellow", "#FCE166"},
+	{"grapefruit", "#FD5956"},
+	{"blood orange", "#FE4B03"},
+	{"very pale blue", "#D6FFFE"},
+	{"old pink", "#C77986"},
+	{"neon red", "#FF073A"},
+	{"golden rod", "#F9BC08"},
+	{"plum purple", "#4E0550"},
+	{"pale peach", "#FFE5AD"},
+	{"dark yellow green", "#728F02"},
+	{"carmine", "#9D0216"},
+	{"deep sea blue", "#015482"},
+	{"dark hot pink", "#D90166"},
+	{"warm blue", "#4B57DB"},
+	{"light khaki", "#E6F2A2"},
+	{"icky green", "#8FAE22"},
+	{"greenblue", "#23C48B"},
+	{"dirty purple", "#734A65"},
+	{"rich blue", "#021BF9"},
+	{"mushroom", "#BA9E88"},
+	{"flat blue", "#3C73A8"},
+	{"dark slate blue", "#214761"},
+	{"dark sage", "#598556"},
+	{"coral pink", "#FF6163"},
+	{"true green", "#089404"},
+	{"darkish purple", "#751973"},
+	{"dark taupe", "#7F684E"},
+	{"cool grey", "#95A3A6"},
+	{"canary", "#FDFF63"},
+	{"booger green", "#96B403"},
+	{"muted pink", "#D1768F"},
+	{"hazel", "#8E7618"},
+	{"dark royal blue", "#02066F"},
+	{"vivid purple", "#9900FA"},
+	{"racing green", "#014600"},
+	{"leather", "#AC7434"},
+	{"green/blue", "#01C08D"},
+	{"sunflower yellow", "#FFDA03"},
+	{"rich purple", "#720058"},
+	{"pale magenta", "#D767AD"},
+	{"light yellowish green", "#C2FF89"},
+	{"indigo blue", "#3A18B1"},
+	{"dark fuchsia", "#9D0759"},
+	{"yellow tan", "#FFE36E"},
+	{"wintergreen", "#20F986"},
+	{"violet pink", "#FB5FFC"},
+	{"topaz", "#13BBAF"},
+	{"seafoam blue", "#78D1B6"},
+	{"light gold", "#FDDC5C"},
+	{"grey/green", "#86A17D"},
+	{"foam green", "#90FDA9"},
+	{"creme", "#FFFFB6"},
+	{"clear blue", "#247AFD"},
+	{"ugly blue", "#31668A"},
+	{"terracota", "#CB6843"},
+	{"very dark brown", "#1D0200"},
+	{"straw", "#FCF679"},
+	{"parchment", "#FEFCAF"},
+	{"orangey yellow", "#FDB915"},
+	{"greyish teal", "#719F91"},
+	{"sapphire", "#2138AB"},
+	{"nice blue", "#107AB0"},
+	{"browny orange", "#CA6B02"},
+	{"washed out green", "#BCF5A6"},
+	{"tiffany blue", "#7BF2DA"},
+	{"light seafoam", "#A0FEBF"},
+	{"light neon green", "#4EFD54"},
+	{"light bright green", "#53FE5C"},
+	{"light bluish green", "#76FDA8"},
+	{"rosy pink", "#F6688E"},
+	{"peachy pink", "#FF9A8A"},
+	{"pale light green", "#B1FC99"},
+	{"old rose", "#C87F89"},
+	{"fern", "#63A950"},
+	{"dusk blue", "#26538D"},
+	{"camo", "#7F8F4E"},
+	{"burnt siena", "#B75203"},
+	{"tealish green", "#0CDC73"},
+	{"swamp", "#698339"},
+	{"sand brown", "#CBA560"},
+	{"rust brown", "#8B3103"},
+	{"orangeish", "#FD8D49"},
+	{"light royal blue", "#3A2EFE"},
+	{"cocoa", "#875F42"},
+	{"baby purple", "#CA9BF7"},
+	{"raw sienna", "#9A6200"},
+	{"radioactive green", "#2CFA1F"},
+	{"light pea green", "#C4FE82"},
+	{"cinnamon", "#AC4F06"},
+	{"squash", "#F2AB15"},
+	{"charcoal grey", "#3C4142"},
+	{"bright yellow green", "#9DFF00"},
+	{"baby puke green", "#B6C406"},
+	{"poison green", "#40FD14"},
+	{"light lavendar", "#EFC0FE"},
+	{"indian red", "#850E04"},
+	{"dark cream", "#FFF39A"},
+	{"toupe", "#C7AC7D"},
+	{"butterscotch", "#FDB147"},
+	{"burple", "#6832E3"},
+	{"tan green", "#A9BE70"},
+	{"sun yellow", "#FFDF22"},
+	{"pale gold", "#FDDE6C"},
+	{"light light green", "#C8FFB0"},
+	{"lichen", "#8FB67B"},
+	{"green/yellow", "#B5CE08"},
+	{"darkgreen", "#054907"},
+	{"azul", "#1D5DEC"},
+	{"sunny yellow", "#FFF917"},
+	{"sickly yellow", "#D0E429"},
+	{"kelley green", "#009337"},
+	{"bruise", "#7E4071"},
+	{"browny green", "#6F6C0A"},
+	{"battleship grey", "#6B7C85"},
+	{"off blue", "#5684AE"},
+	{"manilla", "#FFFA86"},
+	{"greenish beige", "#C9D179"},
+	{"deep brown", "#410200"},
+	{"darkish pink", "#DA467D"},
+	{"custard", "#FFFD78"},
+	{"ugly brown", "#7D7103"},
+	{"stormy blue", "#507B9C"},
+	{"liliac", "#C48EFD"},
+	{"baby shit brown", "#AD900D"},
+	{"reddish grey", "#997570"},
+	{"powder pink", "#FFB2D0"},
+	{"eggplant purple", "#430541"},
+	{"egg shell", "#FFFCC4"},
+	{"very light brown", "#D3B683"},
+	{"tea green", "#BDF8A3"},
+	{"orange pink", "#FF6F52"},
+	{"light grey green", "#B7E1A1"},
+	{"kiwi green", "#8EE53F"},
+	{"boring green", "#63B365"},
+	{"light pastel green", "#B2FBA5"},
+	{"candy pink", "#FF63E9"},
+	{"purply", "#983FB2"},
+	{"purpley grey", "#947E94"},
+	{"dusty lavender", "#AC86A8"},
+	{"desert", "#CCAD60"},
+	{"deep lilac", "#966EBD"},
+	{"pig pink", "#E78EA5"},
+	{"olive yellow", "#C2B709"},
+	{"light seafoam green", "#A7FFB5"},
+	{"light moss green", "#A6C875"},
+	{"lavender pink", "#DD85D7"},
+	{"deep aqua", "#08787F"},
+	{"bland", "#AFA88B"},
+	{"strong pink", "#FF0789"},
+	{"green teal", "#0CB577"},
+	{"deep turquoise", "#017374"},
+	{"dark green blue", "#1F6357"},
+	{"bright sea green", "#05FFA6"},
+	{"booger", "#9BB53C"},
+	{"blue with a hint of purple", "#533CC6"},
+	{"blue blue", "#2242C7"},
+	{"windows blue", "#3778BF"},
+	{"toxic green", "#61DE2A"},
+	{"strong blue", "#0C06F7"},
+	{"spruce", "#0A5F38"},
+	{"pinkish tan", "#D99B82"},
+	{"macaroni and cheese", "#EFB435"},
+	{"grey teal", "#5E9B8A"},
+	{"dusty teal", "#4C9085"},
+	{"dark grass green", "#388004"},
+	{"cement", "#A5A391"},
+	{"yellowish tan", "#FCFC81"},
+	{"warm purple", "#952E8F"},
+	{"tea", "#65AB7C"},
+	{"really light blue", "#D4FFFF"},
+	{"nasty green", "#70B23F"},
+	{"light eggplant", "#894585"},
+	{"fresh green", "#69D84F"},
+	{"electric lime", "#A8FF04"},
+	{"dust", "#B2996E"},
+	{"dark pastel green", "#56AE57"},
+	{"cloudy blue", "#ACC2D9"},
+}
@@ -0,0 +1,49 @@
+package palette
+
+import "hexbot/internal/colour"
+
+// DefaultCVDThreshold is the CIEDE2000 difference below which two palette entries are flagged as
+// indistinguishable. Differences under about 10 are hard to tell apart at a glance in a chart or UI.
+const DefaultCVDThreshold = 10
+
+// Conflict is a pair of palette entries that look too alike.
+type Conflict struct {
+	// A and B index the palette.
+	A int `json:"a"`
+	B int `json:"b"`
+	// Distance is the CIEDE2000 difference between the simulated colours; Normal, between the originals.
+	Distance float64 `json:"distance"`
+	Normal   float64 `json:"normal"`
+}
+
+// CVDReport is how a palette looks with one colour vision deficiency.
+type CVDReport struct {
+	Deficiency colour.Deficiency `json:"deficiency"`
+	Simulated  []colour.Colour   `json:"simulated"`
+	Conflicts  []Conflict        `json:"conflicts,omitempty"`
+}
+
+// CheckCVD simulates colours under every deficiency and flags each pair closer than threshold;
+// DefaultCVDThreshold when threshold is not positive.
+func CheckCVD(colours []colour.Colour, threshold float64) []CVDReport {
+	if threshold <= 0 {
+		threshold = DefaultCVDThreshold
+	}
+
+	reports := make([]CVDReport, 0, len(colour.Deficiencies))
+	for _, d := range colour.Deficiencies {
+		r := CVDReport{Deficiency: d, Simulated: make([]colour.Colour, len(colours))}
+		for i, c := range colours {
+			r.Simulated[i] = c.Simulate(d)
+		}
+		for i := range colours {
+			for j := i + 1; j < len(colours); j++ {
+				if dist := r.Simulated[i].DeltaE2000(r.Simulated[j]); dist < threshold {
+					r.Conflicts = append(r.Conflicts, Conflict{A: i, B: j, Distance: dist, Normal: colours[i].DeltaE2000(colours[j])})
+				}
+			}
+		}
+		reports = append(reports, r)
+	}
+	return reports
+}
@@ -0,0 +1,30 @@
+package palette_test
+
+import (
+	"testing"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/palette"
+)
+
+func TestCheckCVD(t *testing.T) {
+	colours := []colour.Colour{colour.MustParse("#D62728"), colour.MustParse("#2CA02C"), colour.MustParse("#1F77B4")}
+	reports := palette.CheckCVD(colours, 0)
+	if len(reports) != len(colour.Deficiencies) {
+		t.Fatalf("expected a report per deficiency, got %d", len(reports))
+	}
+
+	conflicts := map[colour.Deficiency][]palette.Conflict{}
+	for _, r := range reports {
+		if len(r.Simulated) != len(colours) {
+			t.Errorf("%s: expected %d simulated colours, got %d", r.Deficiency, len(colours), len(r.Simulated))
+		}
+		conflicts[r.Deficiency] = r.Conflicts
+	}
+	if c := conflicts[colour.Deuteranopia]; len(c) != 1 || c[0].A != 0 || c[0].B != 1 || c[0].Normal < palette.DefaultCVDThreshold {
+		t.Errorf("expected red and green to clash under deuteranopia, got %+v", c)
+	}
+	if c := conflicts[colour.Tritanopia]; len(c) != 0 {
+		t.Errorf("expected no clashes under tritanopia, got %+v", c)
+	}
+}
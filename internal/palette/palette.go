@@ -0,0 +1,138 @@
+// Package palette builds colour harmonies from a seed colour.
+package palette
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+)
+
+// Harmony is a rule for choosing colours that go with a seed.
+type Harmony string
+
+const (
+	// Complementary pairs the seed with the opposite hue.
+	Complementary Harmony = "complementary"
+	// SplitComplementary pairs the seed with the two hues either side of its complement.
+	SplitComplementary Harmony = "split-complementary"
+	// Analogous surrounds the seed with its neighbouring hues.
+	Analogous Harmony = "analogous"
+	// Triadic spaces three hues evenly around the wheel.
+	Triadic Harmony = "triadic"
+	// Tetradic spaces four hues evenly around the wheel.
+	Tetradic Harmony = "tetradic"
+	// Monochromatic keeps the seed's hue and varies its lightness.
+	Monochromatic Harmony = "monochromatic"
+)
+
+// Harmonies lists every harmony Generate understands.
+var Harmonies = []Harmony{Complementary, SplitComplementary, Analogous, Triadic, Tetradic, Monochromatic}
+
+// hueOffsets are the rotations, in degrees, applied to the seed's hue.
+var hueOffsets = map[Harmony][]float64{
+	Complementary:      {0, 180},
+	SplitComplementary: {0, 150, 210},
+	Analogous:          {-30, 0, 30},
+	Triadic:            {0, 120, 240},
+	Tetradic:           {0, 90, 180, 270},
+}
+
+// monochromeSteps is the number of colours in a monochromatic palette, spread across
+// monochromeMin to monochromeMax OKLCH lightness.
+const (
+	monochromeSteps = 5
+	monochromeMin   = 0.3
+	monochromeMax   = 0.9
+)
+
+// Palette is an ordered set of colours, either generated from a seed or imported.
+type Palette struct {
+	Name string `bson:"name,omitempty" json:"name,omitempty"`
+	// Seed and Harmony record how a generated palette was built. Both are empty for imported palettes.
+	Seed    *colour.Colour  `bson:"seed,omitempty" json:"seed,omitempty"`
+	Harmony Harmony         `bson:"harmony,omitempty" json:"harmony,omitempty"`
+	Colours []colour.Colour `bson:"colours" json:"colours"`
+	// Names, when set, holds a name for each of Colours.
+	Names []string `bson:"names,omitempty" json:"names,omitempty"`
+	// Origin records where an imported palette came from.
+	Origin *Origin `bson:"origin,omitempty" json:"origin,omitempty"`
+}
+
+// Origin attributes an imported palette to its source.
+type Origin struct {
+	// Source is the file or URL the palette was read from.
+	Source string `bson:"source" json:"source"`
+	Format string `bson:"format" json:"format"`
+	// Author and License credit whoever made the palette, when known.
+	Author  string `bson:"author,omitempty" json:"author,omitempty"`
+	License string `bson:"license,omitempty" json:"license,omitempty"`
+}
+
+// Curated reports whether p was imported rather than generated.
+func (p Palette) Curated() bool {
+	return p.Origin != nil
+}
+
+// ParseHarmony validates a harmony name.
+func ParseHarmony(s string) (Harmony, error) {
+	for _, h := range Harmonies {
+		if string(h) == s {
+			return h, nil
+		}
+	}
+	return "", errors.Errorf("unknown harmony %q, expected one of %v", s, Harmonies)
+}
+
+// Generate builds the palette h describes around seed. Colours are rotated or stepped in OKLCH, so
+// they keep the seed's perceived lightness or chroma, and the seed itself is always included unchanged.
+// Rotated colours outside the sRGB gamut lose chroma until they fit.
+func Generate(seed colour.Colour, h Harmony) (Palette, error) {
+	p := Palette{Seed: &seed, Harmony: h}
+	lch := seed.OKLCH()
+
+	if h == Monochromatic {
+		p.Colours = monochrome(seed, lch)
+		return p, nil
+	}
+
+	offsets, ok := hueOffsets[h]
+	if !ok {
+		return Palette{}, errors.Errorf("unknown harmony %q", h)
+	}
+	for _, off := range offsets {
+		if off == 0 {
+			p.Colours = append(p.Colours, seed)
+			continue
+		}
+		c := lch
+		c.H = math.Mod(c.H+off+360, 360)
+		p.Colours = append(p.Colours, c.Colour())
+	}
+	return p, nil
+}
+
+// monochrome spreads lightness evenly from dark to light, with the seed replacing the nearest step.
+func monochrome(seed colour.Colour, lch colour.OKLCH) []colour.Colour {
+	nearest, best := 0, math.Inf(1)
+	steps := make([]float64, monochromeSteps)
+	for i := range steps {
+		steps[i] = monochromeMin + float64(i)*(monochromeMax-monochromeMin)/(monochromeSteps-1)
+		if d := math.Abs(steps[i] - lch.L); d < best {
+			nearest, best = i, d
+		}
+	}
+
+	colours := make([]colour.Colour, monochromeSteps)
+	for i, l := range steps {
+		if i == nearest {
+			colours[i] = seed
+			continue
+		}
+		c := lch
+		c.L = l
+		colours[i] = c.Colour()
+	}
+	return colours
+}
@@ -0,0 +1,87 @@
+package palette_test
+
+import (
+	"math"
+	"testing"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/palette"
+)
+
+func TestGenerate(t *testing.T) {
+	seed := colour.MustParse("#3A7BD5")
+	lch := seed.OKLCH()
+
+	tests := []struct {
+		Harmony palette.Harmony
+		Hues    []float64
+	}{
+		{palette.Complementary, []float64{0, 180}},
+		{palette.SplitComplementary, []float64{0, 150, 210}},
+		{palette.Analogous, []float64{-30, 0, 30}},
+		{palette.Triadic, []float64{0, 120, 240}},
+		{palette.Tetradic, []float64{0, 90, 180, 270}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.Harmony), func(t *testing.T) {
+			p, err := palette.Generate(seed, tt.Harmony)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *p.Seed != seed || p.Harmony != tt.Harmony || len(p.Colours) != len(tt.Hues) {
+				t.Fatalf("unexpected palette %+v", p)
+			}
+			for i, c := range p.Colours {
+				got := c.OKLCH()
+				if d := hueDistance(got.H, lch.H+tt.Hues[i]); d > 3 {
+					t.Errorf("colour %d %s has hue %.1f, want %.1f", i, c, got.H, math.Mod(lch.H+tt.Hues[i]+360, 360))
+				}
+				if math.Abs(got.L-lch.L) > 0.01 {
+					t.Errorf("colour %d %s has lightness %.3f, want %.3f", i, c, got.L, lch.L)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate_Monochromatic(t *testing.T) {
+	seed := colour.MustParse("#3A7BD5")
+	p, err := palette.Generate(seed, palette.Monochromatic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hasSeed bool
+	prev := -1.0
+	for _, c := range p.Colours {
+		hasSeed = hasSeed || c == seed
+		lch := c.OKLCH()
+		if lch.L <= prev {
+			t.Errorf("expected lightness to increase, got %v", p.Colours)
+		}
+		prev = lch.L
+		if d := hueDistance(lch.H, seed.OKLCH().H); d > 5 {
+			t.Errorf("%s drifted %.1f° from the seed's hue", c, d)
+		}
+	}
+	if !hasSeed {
+		t.Errorf("expected %v to include the seed %s", p.Colours, seed)
+	}
+}
+
+func TestParseHarmony(t *testing.T) {
+	for _, h := range palette.Harmonies {
+		if got, err := palette.ParseHarmony(string(h)); err != nil || got != h {
+			t.Errorf("ParseHarmony(%q) = %q, %v", h, got, err)
+		}
+	}
+	if _, err := palette.ParseHarmony("clashing"); err == nil {
+		t.Error("expected an error for an unknown harmony")
+	}
+}
+
+func hueDistance(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	return math.Min(d, 360-d)
+}
@@ -0,0 +1,56 @@
+package render
+
+import (
+	"image"
+	"image/draw"
+	"math"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/hexbot"
+)
+
+const (
+	// DefaultCanvasSize is the longest side of a canvas, in pixels, when none is given.
+	DefaultCanvasSize = 512
+	// MaxCanvasSize is the longest side a canvas may have.
+	MaxCanvasSize = 4096
+)
+
+// Canvas plots every colour at its Hexbot coordinates on a transparent width by height area, scaled so
+// its longest side is size pixels. Each colour covers at least one pixel; later colours are drawn over
+// earlier ones at the same spot.
+func Canvas(colours []hexbot.Colour, width, height, size int) (*image.NRGBA, error) {
+	if width <= 0 || height <= 0 {
+		return nil, errors.Errorf("canvas needs a positive width and height, got %d by %d", width, height)
+	}
+	if size == 0 {
+		size = DefaultCanvasSize
+	}
+	if size < 1 || size > MaxCanvasSize {
+		return nil, errors.Errorf("size must be between 1 and %d, got %d", MaxCanvasSize, size)
+	}
+
+	scale := float64(size) / float64(width)
+	if height > width {
+		scale = float64(size) / float64(height)
+	}
+	img, err := newImage(int(math.Ceil(float64(width)*scale)), int(math.Ceil(float64(height)*scale)))
+	if err != nil {
+		return nil, err
+	}
+
+	dot := int(math.Max(1, math.Ceil(scale)))
+	for i, c := range colours {
+		p := c.Coordinates
+		if p == nil {
+			return nil, errors.Errorf("colour %d (%s) has no coordinates; fetch with a width and height", i+1, c.Value)
+		}
+		if p.X < 0 || p.X >= width || p.Y < 0 || p.Y >= height {
+			return nil, errors.Errorf("colour %d (%s) at %d,%d is outside the %d by %d canvas", i+1, c.Value, p.X, p.Y, width, height)
+		}
+		at := image.Pt(int(float64(p.X)*scale), int(float64(p.Y)*scale))
+		draw.Draw(img, image.Rect(0, 0, dot, dot).Add(at), image.NewUniform(c.Value), image.ZP, draw.Src)
+	}
+	return img, nil
+}
@@ -0,0 +1,100 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+)
+
+const (
+	// DefaultFrameDelay is how long each timelapse frame shows when no delay is given.
+	DefaultFrameDelay = 500 * time.Millisecond
+	// MinFrameDelay is the shortest delay browsers honour; shorter ones are slowed to a tenth of a second.
+	MinFrameDelay = 20 * time.Millisecond
+	// MaxTimelapsePixels bounds the area of all frames of a timelapse together.
+	MaxTimelapsePixels = 64 << 20
+)
+
+// Timelapse animates frames, each a grid of colours, looping forever. Every frame has the size of the
+// grid holding the largest one, so the layout stays put while the colours change. The GIF palette is
+// built from the colours themselves.
+func Timelapse(frames [][]colour.Colour, opts Options, delay time.Duration) (*gif.GIF, error) {
+	if delay == 0 {
+		delay = DefaultFrameDelay
+	}
+	if delay < MinFrameDelay {
+		return nil, errors.Errorf("frame delay must be at least %s, got %s", MinFrameDelay, delay)
+	}
+
+	var largest int
+	var all []colour.Colour
+	for _, f := range frames {
+		if len(f) > largest {
+			largest = len(f)
+		}
+		all = append(all, f...)
+	}
+	if largest == 0 {
+		return nil, errors.New("no colours to animate")
+	}
+	size, cols, rows, err := opts.layout(largest)
+	if err != nil {
+		return nil, err
+	}
+	if int64(cols*size)*int64(rows*size)*int64(len(frames)) > MaxTimelapsePixels {
+		return nil, errors.Errorf("%d frames of %d by %d pixels are more than the %d pixels allowed; use fewer frames or a smaller size",
+			len(frames), cols*size, rows*size, MaxTimelapsePixels)
+	}
+
+	if opts.Labels {
+		all = append(all, black, white)
+	}
+	pal := quantize(all, 255)
+	// Index 0 is transparent, for the cells a frame doesn't fill.
+	pal = append(color.Palette{color.NRGBA{}}, pal...)
+
+	g := &gif.GIF{Config: image.Config{ColorModel: pal, Width: cols * size, Height: rows * size}}
+	centis := int(delay / (10 * time.Millisecond))
+	for _, f := range frames {
+		img, err := drawGrid(f, size, cols, rows, opts.Labels)
+		if err != nil {
+			return nil, err
+		}
+		g.Image = append(g.Image, paletted(img, pal))
+		g.Delay = append(g.Delay, centis)
+		g.Disposal = append(g.Disposal, gif.DisposalBackground)
+	}
+	return g, nil
+}
+
+// EncodeGIF writes g to w.
+func EncodeGIF(w io.Writer, g *gif.GIF) error {
+	return errors.Wrap(gif.EncodeAll(w, g), "problem encoding gif")
+}
+
+// paletted maps img onto pal, sending transparent pixels to index 0 and ignoring partial alpha, which
+// GIFs can't show.
+func paletted(img *image.NRGBA, pal color.Palette) *image.Paletted {
+	p := image.NewPaletted(img.Bounds(), pal)
+	index := map[color.NRGBA]uint8{}
+	for i := 0; i < len(img.Pix); i += 4 {
+		c := color.NRGBA{R: img.Pix[i], G: img.Pix[i+1], B: img.Pix[i+2], A: 0xFF}
+		if img.Pix[i+3] == 0 {
+			continue
+		}
+		idx, ok := index[c]
+		if !ok {
+			// Skip the transparent entry so no colour matches it.
+			idx = uint8(pal[1:].Index(c) + 1)
+			index[c] = idx
+		}
+		p.Pix[i/4] = idx
+	}
+	return p
+}
@@ -0,0 +1,79 @@
+package render_test
+
+import (
+	"bytes"
+	"image/color"
+	"image/gif"
+	"testing"
+	"time"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/render"
+)
+
+func TestTimelapse(t *testing.T) {
+	frames := [][]colour.Colour{{red, green, blue, red}, {green}}
+	g, err := render.Timelapse(frames, render.Options{Size: 10}, 250*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Image) != 2 || g.Delay[0] != 25 || g.Delay[1] != 25 {
+		t.Fatalf("expected two frames of 25 hundredths, got %d frames delayed %v", len(g.Image), g.Delay)
+	}
+	for i, img := range g.Image {
+		if b := img.Bounds(); b.Dx() != 20 || b.Dy() != 20 {
+			t.Errorf("frame %d is %v, want 20x20", i, b)
+		}
+	}
+	// The palette is exactly the three colours, after the transparent entry.
+	if pal := g.Image[0].Palette; len(pal) != 4 {
+		t.Errorf("expected a transparent entry and three colours, got %v", pal)
+	}
+	for _, tt := range []struct {
+		Frame, X, Y int
+		Want        colour.Colour
+	}{{0, 0, 0, red}, {0, 15, 5, green}, {0, 5, 15, blue}, {1, 0, 0, green}, {1, 15, 15, colour.Colour{}}} {
+		r, gr, b, a := g.Image[tt.Frame].At(tt.X, tt.Y).RGBA()
+		if got := colour.RGBA(uint8(r>>8), uint8(gr>>8), uint8(b>>8), uint8(a>>8)); got != tt.Want {
+			t.Errorf("frame %d pixel %d,%d is %s, want %s", tt.Frame, tt.X, tt.Y, got, tt.Want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := render.EncodeGIF(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	if decoded, err := gif.DecodeAll(&buf); err != nil || len(decoded.Image) != 2 {
+		t.Errorf("expected a two frame gif, got %v", err)
+	}
+
+	if _, err := render.Timelapse(nil, render.Options{}, 0); err == nil {
+		t.Error("expected an error animating no frames")
+	}
+	if _, err := render.Timelapse(frames, render.Options{}, 10*time.Millisecond); err == nil {
+		t.Error("expected an error for a delay under MinFrameDelay")
+	}
+}
+
+func TestTimelapse_Quantize(t *testing.T) {
+	// 1000 distinct greys and reds are more than a GIF palette holds.
+	var frame []colour.Colour
+	for i := 0; i < 1000; i++ {
+		frame = append(frame, colour.RGB(uint8(i), uint8(i/4), uint8(i/4)))
+	}
+	g, err := render.Timelapse([][]colour.Colour{frame}, render.Options{Size: 1}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pal := g.Image[0].Palette
+	if len(pal) != 256 {
+		t.Fatalf("expected a full 256 colour palette, got %d", len(pal))
+	}
+	// Every colour lands on a palette entry close to it.
+	for i, c := range frame {
+		got := g.Image[0].At(i%32, i/32).(color.NRGBA)
+		if d := int(got.R) - int(c.R()); d > 8 || d < -8 {
+			t.Fatalf("colour %s was drawn as %v", c, got)
+		}
+	}
+}
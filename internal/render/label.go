@@ -0,0 +1,73 @@
+package render
+
+import (
+	"image"
+	"image/draw"
+	"strings"
+
+	"hexbot/internal/colour"
+)
+
+const (
+	glyphWidth  = 3
+	glyphHeight = 5
+)
+
+// glyphs is a 3 by 5 pixel font covering hex codes. Each row is 3 bits, most significant on the left.
+var glyphs = map[rune][glyphHeight]uint8{
+	'0': {0x7, 0x5, 0x5, 0x5, 0x7},
+	'1': {0x2, 0x6, 0x2, 0x2, 0x7},
+	'2': {0x7, 0x1, 0x7, 0x4, 0x7},
+	'3': {0x7, 0x1, 0x3, 0x1, 0x7},
+	'4': {0x5, 0x5, 0x7, 0x1, 0x1},
+	'5': {0x7, 0x4, 0x7, 0x1, 0x7},
+	'6': {0x7, 0x4, 0x7, 0x5, 0x7},
+	'7': {0x7, 0x1, 0x2, 0x2, 0x2},
+	'8': {0x7, 0x5, 0x7, 0x5, 0x7},
+	'9': {0x7, 0x5, 0x7, 0x1, 0x7},
+	'A': {0x2, 0x5, 0x7, 0x5, 0x5},
+	'B': {0x6, 0x5, 0x6, 0x5, 0x6},
+	'C': {0x3, 0x4, 0x4, 0x4, 0x3},
+	'D': {0x6, 0x5, 0x5, 0x5, 0x6},
+	'E': {0x7, 0x4, 0x6, 0x4, 0x7},
+	'F': {0x7, 0x4, 0x6, 0x4, 0x4},
+}
+
+var (
+	black = colour.RGB(0, 0, 0)
+	white = colour.RGB(0xFF, 0xFF, 0xFF)
+)
+
+// label writes c's hex code along the bottom of cell, in black or white, whichever contrasts more.
+// The # is left off, as three pixels can't draw one distinct from an H. Cells too small for legible text
+// are left unlabelled.
+func label(img *image.NRGBA, cell image.Rectangle, c colour.Colour) {
+	text := strings.TrimPrefix(c.Opaque().Hex(), "#")
+	// Glyphs are a pixel apart, with a glyph wide margin either side.
+	units := len(text)*(glyphWidth+1) - 1 + 2*glyphWidth
+	scale := cell.Dx() / units
+	if scale < 1 {
+		return
+	}
+
+	ink := black
+	if colour.ContrastRatio(white, c.Opaque()) > colour.ContrastRatio(black, c.Opaque()) {
+		ink = white
+	}
+	src := image.NewUniform(ink)
+
+	x := cell.Min.X + glyphWidth*scale
+	y := cell.Max.Y - (glyphHeight+glyphWidth)*scale
+	for _, r := range text {
+		for row, bits := range glyphs[r] {
+			for col := 0; col < glyphWidth; col++ {
+				if bits&(1<<uint(glyphWidth-1-col)) == 0 {
+					continue
+				}
+				px := image.Rect(0, 0, scale, scale).Add(image.Pt(x+col*scale, y+row*scale))
+				draw.Draw(img, px, src, image.ZP, draw.Src)
+			}
+		}
+		x += (glyphWidth + 1) * scale
+	}
+}
@@ -0,0 +1,130 @@
+package render
+
+import (
+	"image/color"
+	"sort"
+
+	"hexbot/internal/colour"
+)
+
+// box is a set of distinct colours, each weighted by how often it appears, used by median cut.
+type box struct {
+	colours []color.NRGBA
+	counts  []int
+}
+
+// quantize reduces colours to a palette of at most n. When there are few enough distinct colours they
+// are used as they are; otherwise median cut repeatedly splits the box of colours spanning the widest
+// channel range at its weighted median, and each box contributes its weighted average.
+func quantize(colours []colour.Colour, n int) color.Palette {
+	seen := map[color.NRGBA]int{}
+	all := &box{}
+	for _, c := range colours {
+		nc := color.NRGBA{R: c.R(), G: c.G(), B: c.B(), A: 0xFF}
+		i, ok := seen[nc]
+		if !ok {
+			i = len(all.colours)
+			seen[nc] = i
+			all.colours = append(all.colours, nc)
+			all.counts = append(all.counts, 0)
+		}
+		all.counts[i]++
+	}
+
+	boxes := []*box{all}
+	for len(boxes) < n {
+		widest, channel, span := -1, 0, 0
+		for i, b := range boxes {
+			if len(b.colours) < 2 {
+				continue
+			}
+			if c, s := b.widest(); s > span {
+				widest, channel, span = i, c, s
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		a, b := boxes[widest].split(channel)
+		boxes[widest] = a
+		boxes = append(boxes, b)
+	}
+
+	pal := make(color.Palette, len(boxes))
+	for i, b := range boxes {
+		pal[i] = b.average()
+	}
+	return pal
+}
+
+func value(c color.NRGBA, channel int) uint8 {
+	switch channel {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	}
+	return c.B
+}
+
+// widest returns the channel with the largest range across the box, and that range.
+func (b *box) widest() (channel, span int) {
+	for ch := 0; ch < 3; ch++ {
+		min, max := uint8(0xFF), uint8(0)
+		for _, c := range b.colours {
+			v := value(c, ch)
+			if v < min {
+				min = v
+			}
+			if v > max {
+				max = v
+			}
+		}
+		if int(max)-int(min) > span {
+			channel, span = ch, int(max)-int(min)
+		}
+	}
+	return channel, span
+}
+
+// split sorts the box along channel and cuts it where half the weight lies on either side, leaving
+// at least one colour in each half.
+func (b *box) split(channel int) (*box, *box) {
+	sort.Sort(byChannel{b, channel})
+	var total int
+	for _, n := range b.counts {
+		total += n
+	}
+	at, weight := 1, b.counts[0]
+	for at < len(b.colours)-1 && weight*2 < total {
+		weight += b.counts[at]
+		at++
+	}
+	return &box{b.colours[:at], b.counts[:at]}, &box{b.colours[at:], b.counts[at:]}
+}
+
+func (b *box) average() color.NRGBA {
+	var r, g, bl, total int
+	for i, c := range b.colours {
+		n := b.counts[i]
+		r, g, bl, total = r+int(c.R)*n, g+int(c.G)*n, bl+int(c.B)*n, total+n
+	}
+	return color.NRGBA{R: uint8((r + total/2) / total), G: uint8((g + total/2) / total), B: uint8((bl + total/2) / total), A: 0xFF}
+}
+
+// byChannel sorts a box's colours, keeping their counts alongside, by one channel.
+type byChannel struct {
+	*box
+	channel int
+}
+
+func (s byChannel) Len() int { return len(s.colours) }
+
+func (s byChannel) Less(i, j int) bool {
+	return value(s.colours[i], s.channel) < value(s.colours[j], s.channel)
+}
+
+func (s byChannel) Swap(i, j int) {
+	s.colours[i], s.colours[j] = s.colours[j], s.colours[i]
+	s.counts[i], s.counts[j] = s.counts[j], s.counts[i]
+}
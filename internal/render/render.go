@@ -0,0 +1,115 @@
+// Package render draws colours as PNG images: single swatches, labelled strips and grids, and
+// canvases that place each colour at the coordinates Hexbot gave it. Timelapses of grids are
+// animated GIFs.
+package render
+
+import (
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+)
+
+const (
+	// DefaultSize is the side of a swatch, in pixels, when Options leaves it unset.
+	DefaultSize = 64
+	// MaxSize is the largest swatch side allowed.
+	MaxSize = 1024
+	// MaxPixels bounds the area of any image, so a long list of large swatches can't exhaust memory.
+	MaxPixels = 4096 * 4096
+)
+
+// Options control how swatches are laid out.
+type Options struct {
+	// Size is the side of each swatch in pixels, DefaultSize when zero.
+	Size int
+	// Labels writes each colour's hex code on its swatch, where the swatch is large enough to hold it.
+	Labels bool
+	// Columns is the number of swatches per row of a grid, as square as possible when zero.
+	Columns int
+}
+
+func (o Options) size() (int, error) {
+	if o.Size == 0 {
+		return DefaultSize, nil
+	}
+	if o.Size < 1 || o.Size > MaxSize {
+		return 0, errors.Errorf("size must be between 1 and %d, got %d", MaxSize, o.Size)
+	}
+	return o.Size, nil
+}
+
+// Swatch draws a single colour as a square.
+func Swatch(c colour.Colour, opts Options) (*image.NRGBA, error) {
+	return Grid([]colour.Colour{c}, opts)
+}
+
+// Strip draws colours side by side in a single row.
+func Strip(colours []colour.Colour, opts Options) (*image.NRGBA, error) {
+	opts.Columns = len(colours)
+	return Grid(colours, opts)
+}
+
+// Grid draws colours in rows of opts.Columns, left to right and top to bottom. Cells after the last
+// colour are left transparent.
+func Grid(colours []colour.Colour, opts Options) (*image.NRGBA, error) {
+	if len(colours) == 0 {
+		return nil, errors.New("no colours to draw")
+	}
+	size, cols, rows, err := opts.layout(len(colours))
+	if err != nil {
+		return nil, err
+	}
+	return drawGrid(colours, size, cols, rows, opts.Labels)
+}
+
+// layout sizes a grid of n swatches.
+func (o Options) layout(n int) (size, cols, rows int, err error) {
+	if size, err = o.size(); err != nil {
+		return 0, 0, 0, err
+	}
+	cols = o.Columns
+	if cols < 0 {
+		return 0, 0, 0, errors.Errorf("columns must be positive, got %d", cols)
+	}
+	if cols == 0 {
+		cols = int(math.Ceil(math.Sqrt(float64(n))))
+	}
+	if cols > n {
+		cols = n
+	}
+	return size, cols, (n + cols - 1) / cols, nil
+}
+
+func drawGrid(colours []colour.Colour, size, cols, rows int, labels bool) (*image.NRGBA, error) {
+	img, err := newImage(cols*size, rows*size)
+	if err != nil {
+		return nil, err
+	}
+	for i, c := range colours {
+		cell := image.Rect(0, 0, size, size).Add(image.Pt(i%cols*size, i/cols*size))
+		draw.Draw(img, cell, image.NewUniform(c), image.ZP, draw.Src)
+		if labels {
+			label(img, cell, c)
+		}
+	}
+	return img, nil
+}
+
+// newImage allocates a transparent image, refusing ones larger than MaxPixels.
+func newImage(w, h int) (*image.NRGBA, error) {
+	if int64(w)*int64(h) > MaxPixels {
+		return nil, errors.Errorf("a %d by %d pixel image is larger than the %d pixels allowed", w, h, MaxPixels)
+	}
+	return image.NewNRGBA(image.Rect(0, 0, w, h)), nil
+}
+
+// Encode writes img to w as a PNG.
+func Encode(w io.Writer, img image.Image) error {
+	return errors.Wrap(png.Encode(w, img), "problem encoding png")
+}
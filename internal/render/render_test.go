@@ -0,0 +1,125 @@
+package render_test
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/hexbot"
+	"hexbot/internal/render"
+)
+
+var (
+	red   = colour.MustParse("#D62728")
+	green = colour.MustParse("#2CA02C")
+	blue  = colour.MustParse("#1F77B4")
+)
+
+func TestGrid(t *testing.T) {
+	img, err := render.Grid([]colour.Colour{red, green, blue}, render.Options{Size: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 20 || b.Dy() != 20 {
+		t.Fatalf("expected a 20x20 two column grid, got %v", b)
+	}
+	for _, tt := range []struct {
+		X, Y int
+		Want colour.Colour
+	}{{0, 0, red}, {19, 9, green}, {5, 15, blue}, {15, 15, colour.Colour{}}} {
+		if got := img.NRGBAAt(tt.X, tt.Y); colour.RGBA(got.R, got.G, got.B, got.A) != tt.Want {
+			t.Errorf("pixel %d,%d is %v, want %s", tt.X, tt.Y, got, tt.Want)
+		}
+	}
+
+	if _, err := render.Grid(nil, render.Options{}); err == nil {
+		t.Error("expected an error drawing no colours")
+	}
+	if _, err := render.Grid([]colour.Colour{red}, render.Options{Size: render.MaxSize + 1}); err == nil {
+		t.Error("expected an error for an oversized swatch")
+	}
+	if _, err := render.Strip(make([]colour.Colour, 100), render.Options{Size: render.MaxSize}); err == nil {
+		t.Error("expected an error for an image over MaxPixels")
+	}
+}
+
+func TestStrip_Labels(t *testing.T) {
+	plain, err := render.Strip([]colour.Colour{red, colour.MustParse("#FAFAFA")}, render.Options{Size: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := plain.Bounds(); b.Dx() != 200 || b.Dy() != 100 {
+		t.Fatalf("expected a 200x100 strip, got %v", b)
+	}
+	labelled, err := render.Strip([]colour.Colour{red, colour.MustParse("#FAFAFA")}, render.Options{Size: 100, Labels: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The label on the light swatch is dark and the one on the red swatch light.
+	var dark, light int
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 200; x++ {
+			if plain.NRGBAAt(x, y) == labelled.NRGBAAt(x, y) {
+				continue
+			}
+			switch p := labelled.NRGBAAt(x, y); {
+			case x >= 100 && p.R == 0:
+				dark++
+			case x < 100 && p.R == 0xFF:
+				light++
+			default:
+				t.Fatalf("unexpected label pixel %v at %d,%d", p, x, y)
+			}
+		}
+	}
+	if dark == 0 || light == 0 {
+		t.Errorf("expected both swatches labelled, got %d dark and %d light pixels", dark, light)
+	}
+
+	tiny, err := render.Swatch(red, render.Options{Size: 8, Labels: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := tiny.NRGBAAt(4, 6); colour.RGB(p.R, p.G, p.B) != red {
+		t.Errorf("expected swatches too small for a label to be left plain, got %v", p)
+	}
+}
+
+func TestCanvas(t *testing.T) {
+	colours := []hexbot.Colour{
+		{Value: red, Coordinates: &hexbot.Coordinates{X: 0, Y: 0}},
+		{Value: blue, Coordinates: &hexbot.Coordinates{X: 19, Y: 9}},
+	}
+	img, err := render.Canvas(colours, 20, 10, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 40 || b.Dy() != 20 {
+		t.Fatalf("expected a 40x20 canvas, got %v", b)
+	}
+	for _, tt := range []struct {
+		X, Y int
+		Want colour.Colour
+	}{{0, 0, red}, {1, 1, red}, {38, 18, blue}, {39, 19, blue}, {20, 10, colour.Colour{}}} {
+		if got := img.NRGBAAt(tt.X, tt.Y); colour.RGBA(got.R, got.G, got.B, got.A) != tt.Want {
+			t.Errorf("pixel %d,%d is %v, want %s", tt.X, tt.Y, got, tt.Want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := render.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := png.Decode(&buf); err != nil {
+		t.Errorf("expected a valid png: %v", err)
+	}
+
+	if _, err := render.Canvas([]hexbot.Colour{{Value: red}}, 20, 10, 0); err == nil {
+		t.Error("expected an error for a colour without coordinates")
+	}
+	if _, err := render.Canvas(colours, 10, 10, 0); err == nil {
+		t.Error("expected an error for a colour outside the canvas")
+	}
+}
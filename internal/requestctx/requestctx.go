@@ -0,0 +1,88 @@
+// Package requestctx carries per request metadata, such as the request id and the
+// source of a colour, through a context.Context.
+package requestctx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+type key int
+
+const (
+	requestIDKey key = iota
+	sourceKey
+	fencingTokenKey
+)
+
+// NewRequestID returns a random 128 bit id, hex encoded.
+func NewRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// WithRequestID returns a copy of ctx carrying id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the request id carried by ctx, or "" if there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithSource returns a copy of ctx recording where the colours being handled came from.
+func WithSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceKey, source)
+}
+
+// Source returns the source carried by ctx, or "" if there is none.
+func Source(ctx context.Context) string {
+	s, _ := ctx.Value(sourceKey).(string)
+	return s
+}
+
+// fence is the lease work is done under and its token.
+type fence struct {
+	lease string
+	token int64
+}
+
+// WithFencingToken returns a copy of ctx carrying the name and token of the lease the work is done under,
+// so that writes can be refused once the lease has passed to another holder.
+func WithFencingToken(ctx context.Context, lease string, token int64) context.Context {
+	return context.WithValue(ctx, fencingTokenKey, fence{lease: lease, token: token})
+}
+
+// FencingToken returns the lease token carried by ctx, or 0 if there is none.
+func FencingToken(ctx context.Context) int64 {
+	f, _ := ctx.Value(fencingTokenKey).(fence)
+	return f.token
+}
+
+// FencingLease returns the name of the lease whose token ctx carries, or "" if there is none.
+func FencingLease(ctx context.Context) string {
+	f, _ := ctx.Value(fencingTokenKey).(fence)
+	return f.lease
+}
+
+// Detach returns a context carrying ctx's values that is never cancelled, for work that must finish
+// even though ctx has ended, such as recording how it ended.
+func Detach(ctx context.Context) context.Context {
+	return detached{parent: ctx}
+}
+
+type detached struct {
+	parent context.Context
+}
+
+func (detached) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detached) Done() <-chan struct{}               { return nil }
+func (detached) Err() error                          { return nil }
+func (d detached) Value(key interface{}) interface{} { return d.parent.Value(key) }
@@ -0,0 +1,149 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/hexbot"
+)
+
+// CatchUp is what a job does about runs its schedule called for while the scheduler was stopped or
+// the job paused.
+type CatchUp string
+
+const (
+	// CatchUpSkip drops missed runs and waits for the next scheduled one.
+	CatchUpSkip CatchUp = "skip"
+	// CatchUpOnce makes a single run straight away for however many were missed.
+	CatchUpOnce CatchUp = "once"
+	// CatchUpAll makes every missed run, oldest first, up to MaxCatchUp of them.
+	CatchUpAll CatchUp = "all"
+)
+
+// MaxCatchUp bounds how many missed runs CatchUpAll makes.
+const MaxCatchUp = 10
+
+// DefaultTimeout bounds a run when its job sets no timeout.
+const DefaultTimeout = time.Minute
+
+// Job fetches a batch from Hexbot on a schedule.
+type Job struct {
+	Name string
+	// Schedule is parsed by Parse.
+	Schedule string
+	Options  hexbot.FetchOptions
+	// Jitter delays the job's start by a random duration up to this long, so replicas started together
+	// don't all call Hexbot at once.
+	Jitter time.Duration
+	// CatchUp is CatchUpSkip when empty.
+	CatchUp CatchUp
+	// Timeout bounds each run, DefaultTimeout when zero.
+	Timeout time.Duration
+}
+
+// jobJSON is how a job is written in a schedule file, with durations such as "30s".
+type jobJSON struct {
+	Name     string   `json:"name"`
+	Schedule string   `json:"schedule"`
+	Count    int      `json:"count,omitempty"`
+	Width    int      `json:"width,omitempty"`
+	Height   int      `json:"height,omitempty"`
+	Seed     []string `json:"seed,omitempty"`
+	Jitter   string   `json:"jitter,omitempty"`
+	CatchUp  CatchUp  `json:"catch_up,omitempty"`
+	Timeout  string   `json:"timeout,omitempty"`
+}
+
+// MarshalJSON writes the job as it appears in a schedule file.
+func (j Job) MarshalJSON() ([]byte, error) {
+	v := jobJSON{
+		Name:     j.Name,
+		Schedule: j.Schedule,
+		Count:    j.Options.Count,
+		Width:    j.Options.Width,
+		Height:   j.Options.Height,
+		Seed:     j.Options.Seed,
+		CatchUp:  j.CatchUp,
+	}
+	if j.Jitter > 0 {
+		v.Jitter = j.Jitter.String()
+	}
+	if j.Timeout > 0 {
+		v.Timeout = j.Timeout.String()
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON reads a job from a schedule file.
+func (j *Job) UnmarshalJSON(b []byte) error {
+	var v jobJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*j = Job{
+		Name:     v.Name,
+		Schedule: v.Schedule,
+		Options:  hexbot.FetchOptions{Count: v.Count, Width: v.Width, Height: v.Height, Seed: v.Seed},
+		CatchUp:  v.CatchUp,
+	}
+	var err error
+	if v.Jitter != "" {
+		if j.Jitter, err = time.ParseDuration(v.Jitter); err != nil {
+			return errors.Wrapf(err, "problem parsing jitter of job %q", v.Name)
+		}
+	}
+	if v.Timeout != "" {
+		if j.Timeout, err = time.ParseDuration(v.Timeout); err != nil {
+			return errors.Wrapf(err, "problem parsing timeout of job %q", v.Name)
+		}
+	}
+	return nil
+}
+
+// LoadFile reads a JSON array of jobs, such as
+//
+//	[{"name": "minutely", "schedule": "@every 1m", "count": 5, "jitter": "10s", "catch_up": "once"}]
+func LoadFile(path string) ([]Job, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem opening schedule file")
+	}
+	defer f.Close()
+
+	var jobs []Job
+	if err := json.NewDecoder(f).Decode(&jobs); err != nil {
+		return nil, errors.Wrapf(err, "problem reading schedule file %s", path)
+	}
+	return jobs, nil
+}
+
+// validate checks everything New needs from a job, returning its parsed schedule.
+func (j *Job) validate(loc *time.Location) (Schedule, error) {
+	if j.Name == "" {
+		return nil, errors.New("every job needs a name")
+	}
+	sched, err := Parse(j.Schedule, loc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "job %q", j.Name)
+	}
+	if err := j.Options.Validate(); err != nil {
+		return nil, errors.Wrapf(err, "job %q", j.Name)
+	}
+	switch j.CatchUp {
+	case "":
+		j.CatchUp = CatchUpSkip
+	case CatchUpSkip, CatchUpOnce, CatchUpAll:
+	default:
+		return nil, errors.Errorf("job %q: unknown catch up policy %q, expected skip, once or all", j.Name, j.CatchUp)
+	}
+	if j.Jitter < 0 || j.Timeout < 0 {
+		return nil, errors.Errorf("job %q: jitter and timeout can't be negative", j.Name)
+	}
+	if j.Timeout == 0 {
+		j.Timeout = DefaultTimeout
+	}
+	return sched, nil
+}
@@ -0,0 +1,203 @@
+package scheduler
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Schedule decides when a job runs.
+type Schedule interface {
+	// Next returns the first time strictly after t the job should run, or the zero time if it never will.
+	Next(t time.Time) time.Time
+}
+
+// every runs at fixed intervals, aligned to multiples of the interval since the zero time so that
+// restarts don't shift the timetable.
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	d := time.Duration(e)
+	return t.Truncate(d).Add(d)
+}
+
+// cron is a parsed five field cron expression. Each field is a bit set of the values it allows.
+type cron struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar record a * day field; when only one day field is restricted, it alone applies.
+	domStar, dowStar bool
+	loc              *time.Location
+}
+
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6, "jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse reads a schedule: "@every <duration>" for fixed intervals, a descriptor such as @hourly or
+// @daily, or a five field cron expression "minute hour day-of-month month day-of-week" supporting *,
+// lists, ranges, steps and three letter month and day names. Cron expressions are evaluated in loc.
+func Parse(spec string, loc *time.Location) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, errors.Wrapf(err, "problem parsing schedule %q", spec)
+		}
+		if d < time.Second {
+			return nil, errors.Errorf("schedule %q runs more than once a second", spec)
+		}
+		return every(d), nil
+	}
+	if d, ok := descriptors[spec]; ok {
+		spec = d
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("schedule %q should be @every <duration>, a descriptor or five cron fields, got %d fields", spec, len(fields))
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	c := &cron{loc: loc, domStar: fields[2] == "*", dowStar: fields[4] == "*"}
+	var err error
+	for i, f := range []struct {
+		bits  *uint64
+		field field
+	}{{&c.minute, minuteField}, {&c.hour, hourField}, {&c.dom, domField}, {&c.month, monthField}, {&c.dow, dowField}} {
+		if *f.bits, err = f.field.parse(fields[i]); err != nil {
+			return nil, errors.Wrapf(err, "problem parsing schedule %q", spec)
+		}
+	}
+	// Sunday is 0 or 7.
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	return c, nil
+}
+
+// parse turns a comma separated list of *, values, ranges and steps into a bit set.
+func (f field) parse(s string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		rng, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, errors.Errorf("%s step %q is not a positive number", f.name, part[i+1:])
+			}
+			rng, step = part[:i], n
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			i := strings.IndexByte(rng, '-')
+			var err error
+			if lo, err = f.value(rng[:i]); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(rng[i+1:]); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, errors.Errorf("%s range %q runs backwards", f.name, rng)
+			}
+		default:
+			v, err := f.value(rng)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			// A single value with a step, such as 5/15, runs from the value to the end of the range.
+			if hi = v; step > 1 {
+				hi = f.max
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (f field) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, errors.Errorf("%s %q is not a number from %d to %d", f.name, s, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next finds the next matching minute by moving to the start of the next allowed month, then day,
+// hour and minute, giving up after five years for expressions such as February 30th that never match.
+func (c *cron) Next(t time.Time) time.Time {
+	t = t.In(c.loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + 5
+
+	for t.Year() <= limit {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, c.loc)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, c.loc)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, c.loc)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches follows cron: when both day fields are restricted a day matching either runs.
+func (c *cron) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.domStar && c.dowStar:
+		return true
+	case c.domStar:
+		return dow
+	case c.dowStar:
+		return dom
+	}
+	return dom || dow
+}
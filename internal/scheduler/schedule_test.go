@@ -0,0 +1,95 @@
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+
+	"hexbot/internal/scheduler"
+)
+
+func TestParse_Next(t *testing.T) {
+	// A Saturday.
+	from := time.Date(2026, 10, 17, 10, 7, 30, 0, time.UTC)
+	tests := []struct {
+		Spec string
+		Want []string
+	}{
+		{"@every 5m", []string{"10:10", "10:15", "10:20"}},
+		{"@every 90s", []string{"10:09", "10:10", "10:12"}},
+		{"*/15 * * * *", []string{"10:15", "10:30", "10:45"}},
+		{"5,35 9-10 * * *", []string{"10:35", "Sun 09:05", "Sun 09:35"}},
+		{"@hourly", []string{"11:00", "12:00", "13:00"}},
+		{"30 8 * * mon-fri", []string{"Mon 08:30", "Tue 08:30", "Wed 08:30"}},
+		{"0 0 1 * 7", []string{"Sun 00:00", "Sun 00:00", "Sun 00:00"}},
+		{"0 12 29 feb *", []string{"2028-02-29 12:00"}},
+		{"10/20 23 * * *", []string{"23:10", "23:30", "23:50"}},
+	}
+	layouts := []string{"15:04", "Mon 15:04", "2006-01-02 15:04"}
+
+	for _, tt := range tests {
+		s, err := scheduler.Parse(tt.Spec, time.UTC)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.Spec, err)
+			continue
+		}
+		at := from
+		for i, want := range tt.Want {
+			at = s.Next(at)
+			var got string
+			for _, layout := range layouts {
+				if len(layout) == len(want) {
+					got = at.Format(layout)
+				}
+			}
+			if got != want {
+				t.Errorf("Parse(%q) run %d at %s, want %s", tt.Spec, i+1, at, want)
+				break
+			}
+		}
+	}
+}
+
+func TestParse_Location(t *testing.T) {
+	loc := time.FixedZone("UTC+5:30", 5*3600+1800)
+	s, err := scheduler.Parse("0 9 * * *", loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := s.Next(time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC))
+	if want := time.Date(2026, 10, 17, 3, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("expected 09:00 in %s to be %s, got %s", loc, want, got.UTC())
+	}
+}
+
+func TestParse_Never(t *testing.T) {
+	s, err := scheduler.Parse("0 0 30 feb *", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := s.Next(time.Now()); !next.IsZero() {
+		t.Errorf("expected February 30th never to come, got %s", next)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"10-5 * * * *",
+		"*/0 * * * *",
+		"* * * smarch *",
+		"@every",
+		"@every soon",
+		"@every 10ms",
+		"@fortnightly",
+	} {
+		if _, err := scheduler.Parse(spec, time.UTC); err == nil {
+			t.Errorf("Parse(%q) expected an error", spec)
+		}
+	}
+}
@@ -0,0 +1,473 @@
+// Package scheduler fetches batches of colours from Hexbot on fixed intervals or cron schedules,
+// recording every run.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/pkg/errors"
+
+	"hexbot/internal/db"
+	"hexbot/internal/hexbot"
+	"hexbot/internal/requestctx"
+	"hexbot/internal/service"
+)
+
+// ErrUnknownJob is returned when no job has the name asked for.
+var ErrUnknownJob = errors.New("unknown job")
+
+// Fetcher fetches and saves a batch of colours. It is called from several goroutines at once.
+type Fetcher interface {
+	FetchAndSave(ctx context.Context, opts hexbot.FetchOptions) (service.SaveResult, error)
+}
+
+// RunStore records runs.
+type RunStore interface {
+	SaveRun(ctx context.Context, r *db.RunDocument) error
+	LastRun(ctx context.Context, job string) (*db.RunDocument, error)
+	FindRuns(ctx context.Context, job string, limit int) ([]db.RunDocument, error)
+}
+
+// DefaultJobPoll is how often a scheduler with a JobStore checks on a paused job, see WithJobStore.
+const DefaultJobPoll = 5 * time.Second
+
+// JobStore shares jobs' paused state between replicas, such as a db.DB.
+type JobStore interface {
+	SetJobPaused(ctx context.Context, job string, paused bool) (*db.JobState, error)
+	FindJobState(ctx context.Context, job string) (*db.JobState, error)
+}
+
+// Observer is told how every run ended, such as a metrics.Metrics; see WithObserver.
+type Observer interface {
+	ObserveRun(r db.RunDocument)
+}
+
+// Scheduler runs jobs until its context is cancelled. A job whose previous run is still going when the
+// next is due records a skipped run instead of starting another.
+type Scheduler struct {
+	log      *logging.Logger
+	fetcher  Fetcher
+	runs     RunStore
+	jobs     []*job
+	observer Observer
+	jobStore JobStore
+	jobPoll  time.Duration
+
+	rndMu sync.Mutex
+	rnd   *rand.Rand
+	// inflight counts runs in progress, so Run can wait for them on the way out.
+	inflight sync.WaitGroup
+}
+
+type job struct {
+	Job
+	schedule Schedule
+
+	mu       sync.Mutex
+	paused   bool
+	pausedAt time.Time
+	running  bool
+	next     time.Time
+	last     *db.RunDocument
+	// wake interrupts the job's wait when it is paused or resumed.
+	wake chan struct{}
+}
+
+// New checks every job and parses its schedule, cron expressions being evaluated in loc.
+func New(log *logging.Logger, f Fetcher, runs RunStore, loc *time.Location, jobs []Job) (*Scheduler, error) {
+	s := &Scheduler{log: log, fetcher: f, runs: runs, rnd: rand.New(rand.NewSource(time.Now().UnixNano()))}
+	seen := map[string]bool{}
+	for _, j := range jobs {
+		sched, err := j.validate(loc)
+		if err != nil {
+			return nil, err
+		}
+		if seen[j.Name] {
+			return nil, errors.Errorf("more than one job is named %q", j.Name)
+		}
+		seen[j.Name] = true
+		s.jobs = append(s.jobs, &job{Job: j, schedule: sched, wake: make(chan struct{}, 1)})
+	}
+	return s, nil
+}
+
+// WithObserver tells o about every run once it has ended or been skipped. It must be called before Run.
+func (s *Scheduler) WithObserver(o Observer) *Scheduler {
+	s.observer = o
+	return s
+}
+
+// WithJobStore keeps jobs' paused state in js, so that a pause outlives the process and reaches whichever
+// replica runs the schedule, wherever it was made. Jobs read their state as they start, before every run
+// and, while paused, every poll, DefaultJobPoll when not positive. It must be called before Run.
+func (s *Scheduler) WithJobStore(js JobStore, poll time.Duration) *Scheduler {
+	if poll <= 0 {
+		poll = DefaultJobPoll
+	}
+	s.jobStore, s.jobPoll = js, poll
+	return s
+}
+
+// Run starts every job and blocks until ctx is cancelled and the runs in progress have finished.
+// Cancelling ctx also cancels those runs.
+func (s *Scheduler) Run(ctx context.Context) {
+	var loops sync.WaitGroup
+	for _, j := range s.jobs {
+		loops.Add(1)
+		go func(j *job) {
+			defer loops.Done()
+			s.loop(ctx, j)
+		}(j)
+	}
+	loops.Wait()
+	s.inflight.Wait()
+}
+
+// loop waits out the job's jitter, catches up on runs missed since its last recorded one, then starts
+// a run every time the schedule comes round.
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	if j.Jitter > 0 && !sleep(ctx, s.jitter(j.Jitter)) {
+		return
+	}
+
+	paused := s.sync(ctx, j)
+	last, err := s.runs.LastRun(ctx, j.Name)
+	if err != nil && errors.Cause(err) != db.ErrNotFound {
+		s.log.Warn(fmt.Sprintf("job %s: problem finding last run, not catching up: %v", j.Name, err))
+	}
+	if last != nil {
+		j.mu.Lock()
+		j.last = last
+		j.mu.Unlock()
+		// A paused job catches up when it is resumed.
+		if !paused {
+			s.catchUp(ctx, j, last.ScheduledAt)
+		}
+	}
+
+	for {
+		now := time.Now()
+		j.mu.Lock()
+		paused, pausedAt := j.paused, j.pausedAt
+		j.next = time.Time{}
+		if !paused {
+			j.next = j.schedule.Next(now)
+		}
+		next := j.next
+		j.mu.Unlock()
+
+		if paused {
+			// Another replica may resume the job through the store, so check on it now and then.
+			var poll *time.Ticker
+			var polled <-chan time.Time
+			if s.jobStore != nil {
+				poll = time.NewTicker(s.jobPoll)
+				polled = poll.C
+			}
+			select {
+			case <-ctx.Done():
+			case <-j.wake:
+			case <-polled:
+				s.sync(ctx, j)
+			}
+			if poll != nil {
+				poll.Stop()
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			j.mu.Lock()
+			resumed := !j.paused
+			j.mu.Unlock()
+			if resumed {
+				s.catchUp(ctx, j, pausedAt)
+			}
+			continue
+		}
+		if next.IsZero() {
+			s.log.Warn(fmt.Sprintf("job %s: schedule %q never runs again", j.Name, j.Schedule))
+			<-ctx.Done()
+			return
+		}
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-j.wake:
+			timer.Stop()
+		case <-timer.C:
+			// Another replica may have paused the job since the last run.
+			if !s.sync(ctx, j) {
+				s.start(ctx, j, next)
+			}
+		}
+	}
+}
+
+// catchUp applies the job's CatchUp policy to the runs scheduled after since and before now.
+func (s *Scheduler) catchUp(ctx context.Context, j *job, since time.Time) {
+	var missed []time.Time
+	now := time.Now()
+	for t := j.schedule.Next(since); !t.IsZero() && !t.After(now); t = j.schedule.Next(t) {
+		missed = append(missed, t)
+		// Skip and once only need to know whether anything was missed, and when last.
+		if j.CatchUp != CatchUpAll && len(missed) > 1 {
+			missed = missed[1:]
+		}
+	}
+	if len(missed) == 0 {
+		return
+	}
+
+	switch j.CatchUp {
+	case CatchUpSkip:
+		s.log.Info(fmt.Sprintf("job %s: skipping runs missed since %s", j.Name, since.Format(time.RFC3339)))
+		return
+	case CatchUpAll:
+		if len(missed) > MaxCatchUp {
+			s.log.Warn(fmt.Sprintf("job %s: missed %d runs, catching up on the last %d", j.Name, len(missed), MaxCatchUp))
+			missed = missed[len(missed)-MaxCatchUp:]
+		}
+	}
+	for _, at := range missed {
+		if ctx.Err() != nil {
+			return
+		}
+		if !s.begin(ctx, j, at) {
+			continue
+		}
+		s.inflight.Add(1)
+		s.run(ctx, j, at)
+	}
+}
+
+// start begins a run in the background.
+func (s *Scheduler) start(ctx context.Context, j *job, at time.Time) {
+	if !s.begin(ctx, j, at) {
+		return
+	}
+	s.inflight.Add(1)
+	go s.run(ctx, j, at)
+}
+
+// begin marks the job running, or records a skipped run and returns false if it already is.
+func (s *Scheduler) begin(ctx context.Context, j *job, at time.Time) bool {
+	j.mu.Lock()
+	running := j.running
+	j.running = true
+	j.mu.Unlock()
+	if !running {
+		return true
+	}
+
+	now := time.Now().UTC()
+	s.log.Warn(fmt.Sprintf("job %s: skipping run at %s, the last one is still going", j.Name, at.Format(time.RFC3339)))
+	s.record(ctx, &db.RunDocument{
+		Job:          j.Name,
+		ScheduledAt:  at.UTC(),
+		StartedAt:    now,
+		EndedAt:      now,
+		Outcome:      db.RunSkipped,
+		FencingToken: requestctx.FencingToken(ctx),
+	})
+	return false
+}
+
+// run fetches and saves the job's batch, recording the run as it starts and again as it ends.
+// The caller has called begin and added to inflight.
+func (s *Scheduler) run(ctx context.Context, j *job, at time.Time) {
+	defer s.inflight.Done()
+
+	r := &db.RunDocument{
+		Job:         j.Name,
+		ScheduledAt: at.UTC(),
+		StartedAt:   time.Now().UTC(),
+		Outcome:     db.RunRunning,
+		RequestID:   requestctx.NewRequestID(),
+		// Set when the scheduler runs under an elected leader.
+		FencingToken: requestctx.FencingToken(ctx),
+	}
+	s.record(ctx, r)
+
+	fetchCtx, cancel := context.WithTimeout(requestctx.WithRequestID(ctx, r.RequestID), j.Timeout)
+	res, err := s.fetcher.FetchAndSave(fetchCtx, j.Options)
+	cancel()
+
+	r.EndedAt = time.Now().UTC()
+	r.Saved, r.Rejected, r.Merged = res.Saved, res.Rejected, res.Merged
+	if err != nil {
+		r.Outcome, r.Error = db.RunFailed, err.Error()
+		s.log.Error("job "+j.Name+": run failed", err)
+	} else {
+		r.Outcome = db.RunSucceeded
+		s.log.Info(fmt.Sprintf("job %s: saved %d colours, rejected %d, merged %d", j.Name, res.Saved, res.Rejected, res.Merged))
+	}
+	s.record(ctx, r)
+
+	j.mu.Lock()
+	j.running = false
+	j.last = r
+	j.mu.Unlock()
+}
+
+// record saves r, even while shutting down, logging rather than failing the run when it can't. The save
+// carries ctx's fencing token, so a deposed leader can't record runs.
+func (s *Scheduler) record(ctx context.Context, r *db.RunDocument) {
+	if s.observer != nil && r.Outcome != db.RunRunning {
+		s.observer.ObserveRun(*r)
+	}
+	ctx, cancel := context.WithTimeout(requestctx.Detach(ctx), 10*time.Second)
+	defer cancel()
+	err := s.runs.SaveRun(ctx, r)
+	switch {
+	case errors.Cause(err) == db.ErrFenced:
+		s.log.Warn("job " + r.Job + ": not recording run, " + err.Error())
+	case err != nil:
+		s.log.Error("job "+r.Job+": problem recording run", err)
+	}
+}
+
+func (s *Scheduler) jitter(max time.Duration) time.Duration {
+	s.rndMu.Lock()
+	defer s.rndMu.Unlock()
+	return time.Duration(s.rnd.Int63n(int64(max)))
+}
+
+// sleep waits for d, returning false if ctx is cancelled first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// Pause stops the job starting new runs until Resume; a run in progress carries on. With a JobStore the
+// pause is saved there, for every replica.
+func (s *Scheduler) Pause(ctx context.Context, name string) error {
+	return s.setPaused(ctx, name, true)
+}
+
+// Resume restarts a paused job, applying its CatchUp policy to the runs missed while paused.
+func (s *Scheduler) Resume(ctx context.Context, name string) error {
+	return s.setPaused(ctx, name, false)
+}
+
+func (s *Scheduler) setPaused(ctx context.Context, name string, paused bool) error {
+	j := s.job(name)
+	if j == nil {
+		return errors.Wrap(ErrUnknownJob, name)
+	}
+	pausedAt := time.Now()
+	if s.jobStore != nil {
+		st, err := s.jobStore.SetJobPaused(ctx, name, paused)
+		if err != nil {
+			return errors.Wrap(err, "problem saving job state")
+		}
+		pausedAt = st.PausedAt
+	}
+	j.mu.Lock()
+	if j.paused != paused {
+		j.paused = paused
+		if paused {
+			j.pausedAt = pausedAt
+		}
+	}
+	j.mu.Unlock()
+
+	select {
+	case j.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// sync brings the job's paused state in line with the JobStore, if there is one, and reports whether
+// the job is paused. When the store can't be read the job carries on as it was.
+func (s *Scheduler) sync(ctx context.Context, j *job) bool {
+	if s.jobStore == nil {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		return j.paused
+	}
+
+	var paused bool
+	var pausedAt time.Time
+	st, err := s.jobStore.FindJobState(ctx, j.Name)
+	switch {
+	case err == nil:
+		paused, pausedAt = st.Paused, st.PausedAt
+	case errors.Cause(err) != db.ErrNotFound:
+		s.log.Warn(fmt.Sprintf("job %s: problem reading its state, carrying on as it was: %v", j.Name, err))
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		return j.paused
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if paused && !j.paused {
+		s.log.Info(fmt.Sprintf("job %s: paused by another replica", j.Name))
+	}
+	j.paused = paused
+	if paused && !pausedAt.IsZero() {
+		j.pausedAt = pausedAt
+	}
+	return paused
+}
+
+func (s *Scheduler) job(name string) *job {
+	for _, j := range s.jobs {
+		if j.Name == name {
+			return j
+		}
+	}
+	return nil
+}
+
+// JobStatus is the state of a job.
+type JobStatus struct {
+	Job     Job  `json:"job"`
+	Paused  bool `json:"paused"`
+	Running bool `json:"running"`
+	// Next is when the job next runs; nil while paused or before the job has started.
+	Next    *time.Time      `json:"next,omitempty"`
+	LastRun *db.RunDocument `json:"last_run,omitempty"`
+}
+
+// Jobs reports the state of every job, in the order they were given to New.
+func (s *Scheduler) Jobs() []JobStatus {
+	statuses := make([]JobStatus, len(s.jobs))
+	for i, j := range s.jobs {
+		j.mu.Lock()
+		statuses[i] = JobStatus{Job: j.Job, Paused: j.paused, Running: j.running, LastRun: j.last}
+		if !j.next.IsZero() {
+			next := j.next.UTC()
+			statuses[i].Next = &next
+		}
+		j.mu.Unlock()
+	}
+	return statuses
+}
+
+// Runs returns up to limit of the named job's runs, most recent first.
+func (s *Scheduler) Runs(ctx context.Context, name string, limit int) ([]db.RunDocument, error) {
+	if s.job(name) == nil {
+		return nil, errors.Wrap(ErrUnknownJob, name)
+	}
+	runs, err := s.runs.FindRuns(ctx, name, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding runs")
+	}
+	return runs, nil
+}
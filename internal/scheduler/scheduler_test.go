@@ -0,0 +1,381 @@
+package scheduler_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/db"
+	"hexbot/internal/hexbot"
+	"hexbot/internal/requestctx"
+	"hexbot/internal/scheduler"
+	"hexbot/internal/service"
+)
+
+// fetcher counts calls, each taking delay.
+type fetcher struct {
+	delay time.Duration
+	mu    sync.Mutex
+	calls []hexbot.FetchOptions
+}
+
+func (f *fetcher) FetchAndSave(ctx context.Context, opts hexbot.FetchOptions) (service.SaveResult, error) {
+	if requestctx.RequestID(ctx) == "" {
+		return service.SaveResult{}, errors.New("run has no request id")
+	}
+	f.mu.Lock()
+	f.calls = append(f.calls, opts)
+	f.mu.Unlock()
+	select {
+	case <-time.After(f.delay):
+		return service.SaveResult{Saved: opts.Count}, nil
+	case <-ctx.Done():
+		return service.SaveResult{}, ctx.Err()
+	}
+}
+
+func (f *fetcher) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.calls)
+}
+
+// store keeps runs in memory, latest version of each, and counts the outcomes observed.
+type store struct {
+	mu       sync.Mutex
+	last     *db.RunDocument
+	runs     map[string]db.RunDocument
+	observed map[db.Outcome]int
+	// tokens are the fencing tokens every save was made with.
+	tokens []int64
+}
+
+func (s *store) ObserveRun(r db.RunDocument) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.observed == nil {
+		s.observed = map[db.Outcome]int{}
+	}
+	s.observed[r.Outcome]++
+}
+
+func (s *store) observedCount(o db.Outcome) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.observed[o]
+}
+
+func (s *store) SaveRun(ctx context.Context, r *db.RunDocument) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = append(s.tokens, requestctx.FencingToken(ctx))
+	if s.runs == nil {
+		s.runs = map[string]db.RunDocument{}
+	}
+	if r.ID.IsZero() {
+		r.ID = primitive.NewObjectID()
+	}
+	s.runs[r.ID.Hex()] = *r
+	return nil
+}
+
+func (s *store) LastRun(_ context.Context, job string) (*db.RunDocument, error) {
+	if s.last == nil {
+		return nil, errors.Wrap(db.ErrNotFound, job)
+	}
+	return s.last, nil
+}
+
+func (s *store) FindRuns(_ context.Context, job string, limit int) ([]db.RunDocument, error) {
+	return s.outcomes(), nil
+}
+
+func (s *store) outcomes() []db.RunDocument {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var runs []db.RunDocument
+	for _, r := range s.runs {
+		runs = append(runs, r)
+	}
+	return runs
+}
+
+func (s *store) count(o db.Outcome) int {
+	var n int
+	for _, r := range s.outcomes() {
+		if r.Outcome == o {
+			n++
+		}
+	}
+	return n
+}
+
+// jobStore keeps jobs' state in memory, for schedulers to share.
+type jobStore struct {
+	mu     sync.Mutex
+	states map[string]db.JobState
+}
+
+func (js *jobStore) SetJobPaused(_ context.Context, job string, paused bool) (*db.JobState, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	if js.states == nil {
+		js.states = map[string]db.JobState{}
+	}
+	st := js.states[job]
+	if paused && !st.Paused {
+		st.PausedAt = time.Now()
+	}
+	st.Job, st.Paused, st.UpdatedAt = job, paused, time.Now()
+	js.states[job] = st
+	return &st, nil
+}
+
+func (js *jobStore) FindJobState(_ context.Context, job string) (*db.JobState, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	st, ok := js.states[job]
+	if !ok {
+		return nil, errors.Wrap(db.ErrNotFound, job)
+	}
+	return &st, nil
+}
+
+// start runs jobs until the returned func is called.
+func start(t *testing.T, f scheduler.Fetcher, st *store, jobs ...scheduler.Job) (*scheduler.Scheduler, func()) {
+	t.Helper()
+	s, err := scheduler.New(logging.NopLogger, f, st, time.UTC, jobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.WithObserver(st)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	return s, func() {
+		cancel()
+		<-done
+	}
+}
+
+func TestScheduler_SkipIfRunning(t *testing.T) {
+	f := &fetcher{delay: time.Minute}
+	st := &store{}
+	_, stop := start(t, f, st, scheduler.Job{Name: "slow", Schedule: "@every 1s", Options: hexbot.FetchOptions{Count: 3}})
+	time.Sleep(2500 * time.Millisecond)
+	stop()
+
+	if f.count() != 1 {
+		t.Errorf("expected a single fetch while the first was still going, got %d", f.count())
+	}
+	if st.count(db.RunSkipped) < 1 {
+		t.Errorf("expected skipped runs, got %+v", st.outcomes())
+	}
+	// The first run ends when the scheduler is stopped, cancelled.
+	if st.count(db.RunFailed) != 1 || st.count(db.RunRunning) != 0 {
+		t.Errorf("expected the cancelled run recorded as failed, got %+v", st.outcomes())
+	}
+	if st.observedCount(db.RunFailed) != 1 || st.observedCount(db.RunSkipped) != st.count(db.RunSkipped) || st.observedCount(db.RunRunning) != 0 {
+		t.Errorf("expected every ended run observed once, got %v", st.observed)
+	}
+}
+
+func TestScheduler_FencingToken(t *testing.T) {
+	f := &fetcher{delay: time.Minute}
+	st := &store{}
+	s, err := scheduler.New(logging.NopLogger, f, st, time.UTC, []scheduler.Job{{Name: "slow", Schedule: "@every 1s"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(requestctx.WithFencingToken(context.Background(), "scheduler", 7))
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	time.Sleep(1500 * time.Millisecond)
+	cancel()
+	<-done
+
+	// The run ends cancelled, and is still recorded under the token so a deposed leader can be told apart.
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if len(st.tokens) < 2 {
+		t.Fatalf("expected the run recorded as it started and ended, got %d saves", len(st.tokens))
+	}
+	for i, token := range st.tokens {
+		if token != 7 {
+			t.Errorf("save %d: expected fencing token 7, got %d", i, token)
+		}
+	}
+}
+
+func TestScheduler_CatchUp(t *testing.T) {
+	last := &db.RunDocument{Job: "minutely", ScheduledAt: time.Now().Add(-5*time.Minute - 30*time.Second).Truncate(time.Minute)}
+	tests := []struct {
+		CatchUp scheduler.CatchUp
+		Runs    int
+	}{
+		{scheduler.CatchUpSkip, 0},
+		{scheduler.CatchUpOnce, 1},
+		{scheduler.CatchUpAll, 5},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.CatchUp), func(t *testing.T) {
+			f := &fetcher{}
+			st := &store{last: last}
+			s, stop := start(t, f, st, scheduler.Job{Name: "minutely", Schedule: "@every 1m", CatchUp: tt.CatchUp})
+			time.Sleep(200 * time.Millisecond)
+			jobs := s.Jobs()
+			stop()
+
+			// The run due in the current minute may or may not have come round yet.
+			if n := st.count(db.RunSucceeded); n != tt.Runs && n != tt.Runs+1 {
+				t.Errorf("expected %d runs to catch up, got %d", tt.Runs, n)
+			}
+			if jobs[0].Next == nil || jobs[0].LastRun == nil {
+				t.Errorf("expected the next and last runs reported, got %+v", jobs[0])
+			}
+		})
+	}
+}
+
+func TestScheduler_PauseResume(t *testing.T) {
+	f := &fetcher{}
+	st := &store{}
+	s, stop := start(t, f, st, scheduler.Job{Name: "fast", Schedule: "@every 1s", CatchUp: scheduler.CatchUpOnce})
+	defer stop()
+
+	if err := s.Pause(context.Background(), "fast"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(1500 * time.Millisecond)
+	if n := f.count(); n > 1 {
+		t.Errorf("expected no runs while paused, got %d", n)
+	}
+	if st := s.Jobs()[0]; !st.Paused || st.Next != nil {
+		t.Errorf("expected the job reported paused, got %+v", st)
+	}
+
+	// Resume just after a tick, so the next can't land before the check.
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second + 100*time.Millisecond)))
+	before := f.count()
+	if err := s.Resume(context.Background(), "fast"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if f.count() != before+1 {
+		t.Errorf("expected one run catching up on the pause, got %d", f.count()-before)
+	}
+
+	if err := s.Pause(context.Background(), "nope"); errors.Cause(err) != scheduler.ErrUnknownJob {
+		t.Errorf("expected ErrUnknownJob, got %v", err)
+	}
+}
+
+func TestScheduler_SharedPause(t *testing.T) {
+	ctx := context.Background()
+	js := &jobStore{}
+	fast := scheduler.Job{Name: "fast", Schedule: "@every 1s", CatchUp: scheduler.CatchUpOnce}
+
+	// A replica that isn't running the schedule pauses the job before the leader starts.
+	follower, err := scheduler.New(logging.NopLogger, &fetcher{}, &store{}, time.UTC, []scheduler.Job{fast})
+	if err != nil {
+		t.Fatal(err)
+	}
+	follower.WithJobStore(js, 50*time.Millisecond)
+	if err := follower.Pause(ctx, "fast"); err != nil {
+		t.Fatal(err)
+	}
+
+	f := &fetcher{}
+	leader, err := scheduler.New(logging.NopLogger, f, &store{}, time.UTC, []scheduler.Job{fast})
+	if err != nil {
+		t.Fatal(err)
+	}
+	leader.WithJobStore(js, 50*time.Millisecond)
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		leader.Run(runCtx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	time.Sleep(1500 * time.Millisecond)
+	if n := f.count(); n != 0 {
+		t.Errorf("expected no runs while paused elsewhere, got %d", n)
+	}
+	if st := leader.Jobs()[0]; !st.Paused {
+		t.Errorf("expected the leader to report the job paused, got %+v", st)
+	}
+
+	// Resume just after a tick, so the next can't land before the check.
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second + 100*time.Millisecond)))
+	if err := follower.Resume(ctx, "fast"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(300 * time.Millisecond)
+	if n := f.count(); n != 1 {
+		t.Errorf("expected one run catching up once resumed elsewhere, got %d", n)
+	}
+
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second + 100*time.Millisecond)))
+	if err := follower.Pause(ctx, "fast"); err != nil {
+		t.Fatal(err)
+	}
+	before := f.count()
+	time.Sleep(1500 * time.Millisecond)
+	if n := f.count() - before; n != 0 {
+		t.Errorf("expected no runs once paused elsewhere again, got %d", n)
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	for _, jobs := range [][]scheduler.Job{
+		{{Schedule: "@hourly"}},
+		{{Name: "a", Schedule: "hourly"}},
+		{{Name: "a", Schedule: "@hourly", Options: hexbot.FetchOptions{Count: 5000}}},
+		{{Name: "a", Schedule: "@hourly", CatchUp: "sometimes"}},
+		{{Name: "a", Schedule: "@hourly"}, {Name: "a", Schedule: "@daily"}},
+	} {
+		if _, err := scheduler.New(logging.NopLogger, &fetcher{}, &store{}, nil, jobs); err == nil {
+			t.Errorf("New(%+v) expected an error", jobs)
+		}
+	}
+}
+
+func TestJob_JSON(t *testing.T) {
+	in := `[{"name":"canvas","schedule":"*/5 * * * *","count":20,"width":100,"height":50,"seed":["FF7F50"],"jitter":"30s","catch_up":"once","timeout":"2m"}]`
+	var jobs []scheduler.Job
+	if err := json.Unmarshal([]byte(in), &jobs); err != nil {
+		t.Fatal(err)
+	}
+	j := jobs[0]
+	if j.Options.Count != 20 || j.Options.Width != 100 || j.Options.Seed[0] != "FF7F50" || j.Jitter != 30*time.Second || j.Timeout != 2*time.Minute || j.CatchUp != scheduler.CatchUpOnce {
+		t.Errorf("unexpected job %+v", j)
+	}
+	out, err := json.Marshal(jobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `[{"name":"canvas","schedule":"*/5 * * * *","count":20,"width":100,"height":50,"seed":["FF7F50"],"jitter":"30s","catch_up":"once","timeout":"2m0s"}]` {
+		t.Errorf("unexpected json %s", out)
+	}
+
+	if err := json.Unmarshal([]byte(`[{"name":"x","jitter":"a while"}]`), &jobs); err == nil {
+		t.Error("expected an error for a bad jitter")
+	}
+}
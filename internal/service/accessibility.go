@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/db"
+)
+
+// ErrNotFound is returned when a colour asked about has never been stored.
+var ErrNotFound = errors.New("colour not found")
+
+// Contrast scores a colour used as text on a background.
+type Contrast struct {
+	Background colour.Colour `json:"background"`
+	// Ratio is the WCAG 2.x contrast ratio, from 1 to 21.
+	Ratio float64     `json:"ratio"`
+	WCAG  colour.WCAG `json:"wcag"`
+	// APCA is the APCA lightness contrast Lc.
+	APCA float64 `json:"apca"`
+}
+
+// Accessibility describes how usable a colour is for text.
+type Accessibility struct {
+	Colour colour.Colour `json:"colour"`
+	// Luminance is the WCAG 2.x relative luminance.
+	Luminance float64    `json:"luminance"`
+	Black     Contrast   `json:"black"`
+	White     Contrast   `json:"white"`
+	Others    []Contrast `json:"others,omitempty"`
+}
+
+// Accessibility scores c as text on black, on white and on each of against, which must all be stored
+// colours. It returns ErrNotFound when one isn't.
+func (c *ColourService) Accessibility(ctx context.Context, col colour.Colour, against ...colour.Colour) (*Accessibility, error) {
+	a := &Accessibility{
+		Colour:    col,
+		Luminance: col.Luminance(),
+		Black:     contrast(col, colour.RGB(0, 0, 0)),
+		White:     contrast(col, colour.RGB(255, 255, 255)),
+	}
+	for _, bg := range against {
+		bg := bg
+		page, err := c.database.FindColours(ctx, db.ColourQuery{Colour: &bg, Limit: 1})
+		if err != nil {
+			return nil, errors.Wrap(err, "problem finding colour to compare against")
+		}
+		if len(page.Colours) == 0 {
+			return nil, errors.Wrap(ErrNotFound, bg.Hex())
+		}
+		a.Others = append(a.Others, contrast(col, bg))
+	}
+	return a, nil
+}
+
+func contrast(text, background colour.Colour) Contrast {
+	ratio := colour.ContrastRatio(text, background)
+	return Contrast{
+		Background: background,
+		Ratio:      ratio,
+		WCAG:       colour.WCAGLevels(ratio),
+		APCA:       colour.APCA(text, background),
+	}
+}
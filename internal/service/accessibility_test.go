@@ -0,0 +1,47 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+	dbpkg "hexbot/internal/db"
+	"hexbot/internal/service"
+)
+
+func TestColourService_Accessibility(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := service.NewMockDatabase(ctrl)
+	grey := colour.MustParse("#949494")
+	stored := colour.MustParse("#FFFF00")
+	missing := colour.MustParse("#123456")
+	db.EXPECT().FindColours(gomock.Any(), dbpkg.ColourQuery{Colour: &stored, Limit: 1}).
+		Return(&dbpkg.ColourPage{Colours: []dbpkg.ColourDocument{{Colour: stored}}}, nil).Times(2)
+	db.EXPECT().FindColours(gomock.Any(), dbpkg.ColourQuery{Colour: &missing, Limit: 1}).
+		Return(&dbpkg.ColourPage{}, nil)
+
+	s := service.NewColourService(logging.NopLogger, db, nil)
+	a, err := s.Accessibility(context.Background(), grey, stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.White.WCAG.AALarge || a.White.WCAG.AA || !a.Black.WCAG.AA || a.Black.WCAG.AAA {
+		t.Errorf("unexpected WCAG results on white %+v and black %+v", a.White.WCAG, a.Black.WCAG)
+	}
+	if a.White.APCA <= 0 || a.Black.APCA >= 0 {
+		t.Errorf("expected positive APCA on white and negative on black, got %.1f and %.1f", a.White.APCA, a.Black.APCA)
+	}
+	if len(a.Others) != 1 || a.Others[0].Background != stored {
+		t.Errorf("expected a score against %s, got %+v", stored, a.Others)
+	}
+
+	if _, err := s.Accessibility(context.Background(), grey, stored, missing); errors.Cause(err) != service.ErrNotFound {
+		t.Errorf("expected ErrNotFound for an unstored colour, got %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/palette"
+)
+
+// Simulation is a colour as seen with each colour vision deficiency.
+type Simulation struct {
+	Colour    colour.Colour                       `json:"colour"`
+	Simulated map[colour.Deficiency]colour.Colour `json:"simulated"`
+}
+
+// SimulateCVD shows how col looks with every colour vision deficiency.
+func (c *ColourService) SimulateCVD(col colour.Colour) Simulation {
+	s := Simulation{Colour: col, Simulated: map[colour.Deficiency]colour.Colour{}}
+	for _, d := range colour.Deficiencies {
+		s.Simulated[d] = col.Simulate(d)
+	}
+	return s
+}
+
+// CheckCVD flags entries of colours that look closer than threshold with each colour vision deficiency.
+func (c *ColourService) CheckCVD(colours []colour.Colour, threshold float64) []palette.CVDReport {
+	return palette.CheckCVD(colours, threshold)
+}
+
+// CheckPaletteCVD runs CheckCVD on the stored palette with id, returning ErrPaletteNotFound if there
+// is none.
+func (c *ColourService) CheckPaletteCVD(ctx context.Context, id primitive.ObjectID, threshold float64) ([]palette.CVDReport, error) {
+	p, err := c.Palette(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return palette.CheckCVD(p.Colours, threshold), nil
+}
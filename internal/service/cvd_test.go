@@ -0,0 +1,41 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/golang/mock/gomock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/colour"
+	dbpkg "hexbot/internal/db"
+	"hexbot/internal/palette"
+	"hexbot/internal/service"
+)
+
+func TestColourService_CheckPaletteCVD(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := service.NewMockDatabase(ctrl)
+	id := primitive.NewObjectID()
+	p := palette.Palette{Colours: []colour.Colour{colour.MustParse("#D62728"), colour.MustParse("#2CA02C")}}
+	db.EXPECT().FindPalette(gomock.Any(), id).Return(&dbpkg.PaletteDocument{ID: id, Palette: p}, nil)
+
+	s := service.NewColourService(logging.NopLogger, db, nil)
+	reports, err := s.CheckPaletteCVD(context.Background(), id, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range reports {
+		if r.Deficiency == colour.Deuteranopia && len(r.Conflicts) != 1 {
+			t.Errorf("expected red and green to clash under deuteranopia, got %+v", r.Conflicts)
+		}
+	}
+
+	sim := s.SimulateCVD(colour.MustParse("#D62728"))
+	if len(sim.Simulated) != len(colour.Deficiencies) {
+		t.Errorf("expected a simulation per deficiency, got %v", sim.Simulated)
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/db"
+)
+
+// DedupMode decides what SaveColour does with a colour close to one saved recently.
+type DedupMode string
+
+const (
+	// DedupOff saves every colour.
+	DedupOff DedupMode = "off"
+	// DedupReject drops near duplicates.
+	DedupReject DedupMode = "reject"
+	// DedupMerge drops near duplicates but bumps the seen count of the colour they matched.
+	DedupMerge DedupMode = "merge"
+)
+
+// DedupConfig configures near duplicate detection in SaveColour.
+type DedupConfig struct {
+	Mode DedupMode
+	// Threshold is the largest distance, measured with Metric, at which two colours are duplicates.
+	Threshold float64
+	// Window is how far back to look for duplicates.
+	Window time.Duration
+	// Metric measures distance; CIEDE2000 when nil.
+	Metric colour.Metric
+}
+
+// ParseDedupMode validates a DedupMode read from configuration.
+func ParseDedupMode(s string) (DedupMode, error) {
+	switch m := DedupMode(s); m {
+	case DedupOff, DedupReject, DedupMerge:
+		return m, nil
+	case "":
+		return DedupOff, nil
+	}
+	return "", errors.Errorf("unknown dedup mode %q, expected off, reject or merge", s)
+}
+
+// WithDedup turns on near duplicate detection for SaveColour.
+func (c *ColourService) WithDedup(cfg DedupConfig) *ColourService {
+	if cfg.Metric == nil {
+		cfg.Metric = colour.Colour.DeltaE2000
+	}
+	c.dedup = cfg
+	return c
+}
+
+func (d DedupConfig) enabled() bool {
+	return d.Mode == DedupReject || d.Mode == DedupMerge
+}
+
+// nearest returns the closest of candidates within the threshold of col, or nil.
+func (d DedupConfig) nearest(col colour.Colour, candidates []db.ColourDocument) *db.ColourDocument {
+	var best *db.ColourDocument
+	bestDistance := d.Threshold
+	for i := range candidates {
+		if dist := d.Metric(col, candidates[i].Colour); dist <= bestDistance {
+			best, bestDistance = &candidates[i], dist
+		}
+	}
+	return best
+}
+
+// recentColours returns up to db.MaxLimit colours saved within the dedup window.
+func (c *ColourService) recentColours(ctx context.Context) ([]db.ColourDocument, error) {
+	page, err := c.database.FindColours(ctx, db.ColourQuery{
+		From:  time.Now().Add(-c.dedup.Window),
+		Limit: db.MaxLimit,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding recent colours for deduplication")
+	}
+	return page.Colours, nil
+}
+
+func (c *ColourService) resolveDuplicate(ctx context.Context, col colour.Colour, match *db.ColourDocument, res *SaveResult) error {
+	if c.dedup.Mode == DedupReject {
+		c.log.Debug("rejecting " + col.Hex() + " as a near duplicate of " + match.Colour.Hex())
+		res.Rejected++
+		return nil
+	}
+
+	c.log.Debug("merging " + col.Hex() + " into " + match.Colour.Hex())
+	if err := c.database.MarkSeen(ctx, match.ID); err != nil {
+		return errors.Wrap(err, "problem merging near duplicate colour")
+	}
+	match.SeenCount++
+	res.Merged++
+	return nil
+}
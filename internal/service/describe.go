@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/db"
+	"hexbot/internal/names"
+)
+
+// RGB is a colour's 8 bit channels.
+type RGB struct {
+	R uint8 `json:"r"`
+	G uint8 `json:"g"`
+	B uint8 `json:"b"`
+}
+
+// Description is a colour in every space the colour package converts to, along with its nearest name and,
+// when it has been fetched before, its most recently stored record.
+type Description struct {
+	Colour    colour.Colour `json:"hex"`
+	RGB       RGB           `json:"rgb"`
+	HSL       colour.HSL    `json:"hsl"`
+	HSV       colour.HSV    `json:"hsv"`
+	CMYK      colour.CMYK   `json:"cmyk"`
+	XYZ       colour.XYZ    `json:"xyz"`
+	Lab       colour.Lab    `json:"lab"`
+	OKLab     colour.OKLab  `json:"oklab"`
+	OKLCH     colour.OKLCH  `json:"oklch"`
+	Luminance float64       `json:"luminance"`
+	// Name is nil when the service has no colour names.
+	Name  *names.Match       `json:"name,omitempty"`
+	Saved *db.ColourDocument `json:"saved,omitempty"`
+}
+
+// Describe converts col to other colour spaces, names it and looks up whether it has been stored.
+func (c *ColourService) Describe(ctx context.Context, col colour.Colour) (*Description, error) {
+	d := &Description{
+		Colour:    col,
+		RGB:       RGB{R: col.R(), G: col.G(), B: col.B()},
+		HSL:       col.HSL(),
+		HSV:       col.HSV(),
+		CMYK:      col.CMYK(),
+		XYZ:       col.XYZ(),
+		Lab:       col.Lab(),
+		OKLab:     col.OKLab(),
+		OKLCH:     col.OKLCH(),
+		Luminance: col.Luminance(),
+	}
+	if c.names != nil {
+		if m := c.names.Nearest(col); m.Name != "" {
+			d.Name = &m
+		}
+	}
+
+	page, err := c.database.FindColours(ctx, db.ColourQuery{Colour: &col, Limit: 1})
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding stored colour")
+	}
+	if len(page.Colours) > 0 {
+		d.Saved = &page.Colours[0]
+	}
+	return d, nil
+}
@@ -0,0 +1,45 @@
+package service_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/golang/mock/gomock"
+
+	"hexbot/internal/colour"
+	dbpkg "hexbot/internal/db"
+	"hexbot/internal/names"
+	"hexbot/internal/service"
+)
+
+func TestColourService_Describe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	red := colour.MustParse("#FF0000")
+	db := service.NewMockDatabase(ctrl)
+	db.EXPECT().FindColours(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, q dbpkg.ColourQuery) (*dbpkg.ColourPage, error) {
+			if q.Colour == nil || *q.Colour != red {
+				t.Errorf("expected a lookup of %s, got %+v", red, q)
+			}
+			return &dbpkg.ColourPage{Colours: []dbpkg.ColourDocument{{Colour: red, SeenCount: 3}}}, nil
+		})
+
+	s := service.NewColourService(logging.NopLogger, db, nil).WithNames(names.NewDefaultLookup())
+	d, err := s.Describe(context.Background(), red)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.RGB != (service.RGB{R: 255}) || d.HSL.H != 0 || d.HSL.S != 1 || math.Abs(d.Lab.L-53.24) > 0.01 {
+		t.Errorf("unexpected conversions %+v", d)
+	}
+	if d.Name == nil || d.Name.Name != "red" || d.Name.Distance != 0 {
+		t.Errorf("expected the name red, got %+v", d.Name)
+	}
+	if d.Saved == nil || d.Saved.SeenCount != 3 {
+		t.Errorf("expected the stored record, got %+v", d.Saved)
+	}
+}
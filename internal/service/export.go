@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/db"
+	"hexbot/internal/export"
+	"hexbot/internal/palette"
+)
+
+// MaxExportColours caps how many colours ExportColours gathers from a query.
+const MaxExportColours = 10000
+
+// MaxCVDColours is the most colours a colour vision deficiency report is made for. The report compares
+// every pair of colours, so its cost grows with the square of their number.
+const MaxCVDColours = 256
+
+// ErrTooManyForCVD is returned when a colour vision deficiency report is asked for more than
+// MaxCVDColours colours.
+var ErrTooManyForCVD = errors.Errorf("a colour vision deficiency report covers at most %d colours", MaxCVDColours)
+
+// ExportOptions tune what goes into an export.
+type ExportOptions struct {
+	// CVD adds a colour vision deficiency report, written by the formats that can carry one.
+	CVD bool
+	// CVDThreshold is passed to palette.CheckCVD.
+	CVDThreshold float64
+}
+
+// ExportPalette gathers the stored palette with id for export. Colours without a name of their own are
+// named after the nearest named colour when a name lookup is set.
+func (c *ColourService) ExportPalette(ctx context.Context, id primitive.ObjectID, opts ExportOptions) (*export.Set, error) {
+	p, err := c.Palette(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &export.Set{Name: p.Name}
+	if s.Name == "" && p.Seed != nil {
+		s.Name = fmt.Sprintf("%s palette from %s", p.Harmony, p.Seed.Hex())
+	}
+	for i, col := range p.Colours {
+		name := c.name(col).Name
+		if i < len(p.Names) && p.Names[i] != "" {
+			name = p.Names[i]
+		}
+		s.Swatches = append(s.Swatches, export.Swatch{Name: name, Colour: col})
+	}
+	if err := c.addCVD(s, opts); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ExportColours gathers every colour matching q for export, following cursors from page to page up
+// to MaxExportColours. Colours are named after the nearest name stored with them. It returns
+// ErrTooManyForCVD as soon as more than MaxCVDColours match when a report is asked for.
+func (c *ColourService) ExportColours(ctx context.Context, q db.ColourQuery, opts ExportOptions) (*export.Set, error) {
+	s := &export.Set{Name: "Hexbot colours"}
+gather:
+	for {
+		page, err := c.database.FindColours(ctx, q)
+		if err != nil {
+			return nil, errors.Wrap(err, "problem finding colours to export")
+		}
+		for _, doc := range page.Colours {
+			if opts.CVD && len(s.Swatches) == MaxCVDColours {
+				return nil, errors.Wrap(ErrTooManyForCVD, "narrow the query or leave the report out")
+			}
+			if len(s.Swatches) == MaxExportColours {
+				c.log.Warn(fmt.Sprintf("export stopped at %d colours", MaxExportColours))
+				break gather
+			}
+			s.Swatches = append(s.Swatches, export.Swatch{Name: doc.Name, Colour: doc.Colour})
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+	if err := c.addCVD(s, opts); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// addCVD adds a colour vision deficiency report to s when opts ask for one, returning ErrTooManyForCVD
+// when s has more than MaxCVDColours swatches.
+func (c *ColourService) addCVD(s *export.Set, opts ExportOptions) error {
+	if !opts.CVD {
+		return nil
+	}
+	if len(s.Swatches) > MaxCVDColours {
+		return errors.Wrapf(ErrTooManyForCVD, "got %d", len(s.Swatches))
+	}
+	colours := make([]colour.Colour, len(s.Swatches))
+	for i, sw := range s.Swatches {
+		colours[i] = sw.Colour
+	}
+	s.CVD = palette.CheckCVD(colours, opts.CVDThreshold)
+	return nil
+}
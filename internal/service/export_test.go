@@ -0,0 +1,58 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+	dbpkg "hexbot/internal/db"
+	"hexbot/internal/service"
+)
+
+func TestColourService_ExportColours(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := service.NewMockDatabase(ctrl)
+	red := dbpkg.ColourDocument{Colour: colour.MustParse("#D62728"), ColourName: dbpkg.ColourName{Name: "red"}}
+	green := dbpkg.ColourDocument{Colour: colour.MustParse("#2CA02C")}
+	db.EXPECT().FindColours(gomock.Any(), dbpkg.ColourQuery{Source: "hexbot"}).
+		Return(&dbpkg.ColourPage{Colours: []dbpkg.ColourDocument{red}, NextCursor: "next"}, nil)
+	db.EXPECT().FindColours(gomock.Any(), dbpkg.ColourQuery{Source: "hexbot", Cursor: "next"}).
+		Return(&dbpkg.ColourPage{Colours: []dbpkg.ColourDocument{green}}, nil)
+
+	s := service.NewColourService(logging.NopLogger, db, nil)
+	set, err := s.ExportColours(context.Background(), dbpkg.ColourQuery{Source: "hexbot"}, service.ExportOptions{CVD: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Swatches) != 2 || set.Swatches[0].Name != "red" || set.Swatches[1].Colour != green.Colour {
+		t.Errorf("unexpected swatches %+v", set.Swatches)
+	}
+	if len(set.CVD) != len(colour.Deficiencies) {
+		t.Errorf("expected a CVD report per deficiency, got %d", len(set.CVD))
+	}
+}
+
+func TestColourService_ExportColours_TooManyForCVD(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := make([]dbpkg.ColourDocument, service.MaxCVDColours+1)
+	for i := range docs {
+		docs[i] = dbpkg.ColourDocument{Colour: colour.RGB(uint8(i), uint8(i/256), 0)}
+	}
+	db := service.NewMockDatabase(ctrl)
+	db.EXPECT().FindColours(gomock.Any(), gomock.Any()).
+		Return(&dbpkg.ColourPage{Colours: docs, NextCursor: "next"}, nil)
+
+	s := service.NewColourService(logging.NopLogger, db, nil)
+	_, err := s.ExportColours(context.Background(), dbpkg.ColourQuery{}, service.ExportOptions{CVD: true})
+	if errors.Cause(err) != service.ErrTooManyForCVD {
+		t.Fatalf("expected ErrTooManyForCVD, got %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/db"
+	"hexbot/internal/export"
+	"hexbot/internal/palette"
+)
+
+// ImportPalette stores set as a curated palette credited to origin, or returns ErrInvalidPalette when it
+// breaks the rules palettes created through the service follow.
+func (c *ColourService) ImportPalette(ctx context.Context, set *export.Set, origin palette.Origin) (*db.PaletteDocument, error) {
+	if len(set.Swatches) == 0 {
+		return nil, errors.Wrapf(ErrInvalidPalette, "%s has no colours to import", origin.Source)
+	}
+
+	p := palette.Palette{
+		Name:    set.Name,
+		Colours: make([]colour.Colour, len(set.Swatches)),
+		Origin:  &origin,
+	}
+	var named bool
+	names := make([]string, len(set.Swatches))
+	for i, sw := range set.Swatches {
+		p.Colours[i], names[i] = sw.Colour, sw.Name
+		named = named || sw.Name != ""
+	}
+	if named {
+		p.Names = names
+	}
+	if err := validatePalette(p); err != nil {
+		return nil, errors.Wrap(err, origin.Source)
+	}
+
+	doc, err := c.database.SavePalette(ctx, p)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem saving imported palette")
+	}
+	return doc, nil
+}
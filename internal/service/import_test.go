@@ -0,0 +1,52 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+	dbpkg "hexbot/internal/db"
+	"hexbot/internal/export"
+	"hexbot/internal/palette"
+	"hexbot/internal/service"
+)
+
+func TestColourService_ImportPalette(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := service.NewMockDatabase(ctrl)
+	origin := palette.Origin{Source: "brand.gpl", Format: "gpl", Author: "Design"}
+	db.EXPECT().SavePalette(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, p palette.Palette) (*dbpkg.PaletteDocument, error) {
+			if !p.Curated() || *p.Origin != origin || p.Name != "Brand" || p.Seed != nil {
+				t.Errorf("unexpected palette %+v", p)
+			}
+			if len(p.Colours) != 2 || len(p.Names) != 2 || p.Names[0] != "ink" || p.Names[1] != "" {
+				t.Errorf("unexpected colours %v named %q", p.Colours, p.Names)
+			}
+			return &dbpkg.PaletteDocument{Palette: p}, nil
+		})
+
+	s := service.NewColourService(logging.NopLogger, db, nil)
+	set := &export.Set{Name: "Brand", Swatches: []export.Swatch{
+		{Name: "ink", Colour: colour.MustParse("#1A1A1A")},
+		{Colour: colour.MustParse("#FAFAFA")},
+	}}
+	if _, err := s.ImportPalette(context.Background(), set, origin); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.ImportPalette(context.Background(), &export.Set{}, origin); errors.Cause(err) != service.ErrInvalidPalette {
+		t.Errorf("expected ErrInvalidPalette importing an empty palette, got %v", err)
+	}
+
+	large := &export.Set{Swatches: make([]export.Swatch, service.MaxPaletteColours+1)}
+	if _, err := s.ImportPalette(context.Background(), large, origin); errors.Cause(err) != service.ErrInvalidPalette {
+		t.Errorf("expected ErrInvalidPalette importing more than %d colours, got %v", service.MaxPaletteColours, err)
+	}
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/db"
+	"hexbot/internal/hexbot"
+	"hexbot/internal/palette"
+)
+
+// MaxPaletteColours is the most colours a palette created or updated through the service may hold.
+const MaxPaletteColours = 256
+
+var (
+	// ErrPaletteNotFound is returned when no palette has the id asked for.
+	ErrPaletteNotFound = errors.New("palette not found")
+	// ErrInvalidPalette is returned when a palette to store breaks the rules in validatePalette.
+	ErrInvalidPalette = errors.New("invalid palette")
+)
+
+// GeneratePalette builds the h harmony around seed and stores it.
+func (c *ColourService) GeneratePalette(ctx context.Context, seed colour.Colour, h palette.Harmony) (*db.PaletteDocument, error) {
+	p, err := palette.Generate(seed, h)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := c.database.SavePalette(ctx, p)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem saving palette")
+	}
+	return doc, nil
+}
+
+// PaletteFromHexbot fetches a single colour from Hexbot and stores the h harmony built around it.
+func (c *ColourService) PaletteFromHexbot(ctx context.Context, h palette.Harmony) (*db.PaletteDocument, error) {
+	colours, err := c.hexbot.Fetch(ctx, hexbot.FetchOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "problem getting seed colour from hexbot")
+	}
+	if len(colours) == 0 {
+		return nil, errors.New("hexbot returned no colours")
+	}
+	return c.GeneratePalette(ctx, colours[0].Value, h)
+}
+
+// Palette returns the stored palette with id, or ErrPaletteNotFound.
+func (c *ColourService) Palette(ctx context.Context, id primitive.ObjectID) (*db.PaletteDocument, error) {
+	doc, err := c.database.FindPalette(ctx, id)
+	if errors.Cause(err) == db.ErrNotFound {
+		return nil, errors.Wrap(ErrPaletteNotFound, id.Hex())
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding palette")
+	}
+	return doc, nil
+}
+
+// ListPalettes returns a page of stored palettes matching q, newest first.
+func (c *ColourService) ListPalettes(ctx context.Context, q db.PaletteQuery) (*db.PalettePage, error) {
+	page, err := c.database.FindPalettes(ctx, q)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem listing palettes from database layer")
+	}
+	return page, nil
+}
+
+// CreatePalette stores p. A palette with a seed and harmony but no colours is generated first, keeping
+// its name; otherwise p is stored as given and must pass validatePalette.
+func (c *ColourService) CreatePalette(ctx context.Context, p palette.Palette) (*db.PaletteDocument, error) {
+	p, err := completePalette(p)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := c.database.SavePalette(ctx, p)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem saving palette")
+	}
+	return doc, nil
+}
+
+// UpdatePalette replaces the stored palette with id by p, completed and checked as CreatePalette does.
+// It returns ErrPaletteNotFound when there is no such palette.
+func (c *ColourService) UpdatePalette(ctx context.Context, id primitive.ObjectID, p palette.Palette) (*db.PaletteDocument, error) {
+	p, err := completePalette(p)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := c.database.UpdatePalette(ctx, id, p)
+	if errors.Cause(err) == db.ErrNotFound {
+		return nil, errors.Wrap(ErrPaletteNotFound, id.Hex())
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "problem updating palette")
+	}
+	return doc, nil
+}
+
+// DeletePalette removes the stored palette with id, or returns ErrPaletteNotFound.
+func (c *ColourService) DeletePalette(ctx context.Context, id primitive.ObjectID) error {
+	err := c.database.DeletePalette(ctx, id)
+	if errors.Cause(err) == db.ErrNotFound {
+		return errors.Wrap(ErrPaletteNotFound, id.Hex())
+	}
+	return errors.Wrap(err, "problem deleting palette")
+}
+
+// completePalette generates p's colours from its seed when it has none, then validates it.
+func completePalette(p palette.Palette) (palette.Palette, error) {
+	if len(p.Colours) == 0 && p.Seed != nil {
+		generated, err := palette.Generate(*p.Seed, p.Harmony)
+		if err != nil {
+			return p, errors.Wrap(ErrInvalidPalette, err.Error())
+		}
+		generated.Name, generated.Origin = p.Name, p.Origin
+		p = generated
+	}
+	return p, validatePalette(p)
+}
+
+// validatePalette checks that p has between one and MaxPaletteColours colours, a name for each colour if it
+// has any names, and a known harmony if it names one.
+func validatePalette(p palette.Palette) error {
+	switch {
+	case len(p.Colours) == 0:
+		return errors.Wrap(ErrInvalidPalette, "a palette needs at least one colour, or a seed and harmony")
+	case len(p.Colours) > MaxPaletteColours:
+		return errors.Wrapf(ErrInvalidPalette, "a palette holds at most %d colours, got %d", MaxPaletteColours, len(p.Colours))
+	case len(p.Names) > 0 && len(p.Names) != len(p.Colours):
+		return errors.Wrapf(ErrInvalidPalette, "got %d names for %d colours", len(p.Names), len(p.Colours))
+	}
+	if p.Harmony != "" {
+		if _, err := palette.ParseHarmony(string(p.Harmony)); err != nil {
+			return errors.Wrap(ErrInvalidPalette, err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/colour"
+	dbpkg "hexbot/internal/db"
+	"hexbot/internal/hexbot"
+	"hexbot/internal/palette"
+	"hexbot/internal/service"
+)
+
+func TestColourService_PaletteFromHexbot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hc := service.NewMockHexbotClient(ctrl)
+	db := service.NewMockDatabase(ctrl)
+
+	seed := colour.MustParse("#3A7BD5")
+	hc.EXPECT().Fetch(gomock.Any(), hexbot.FetchOptions{}).Return([]hexbot.Colour{{Value: seed}}, nil)
+	db.EXPECT().SavePalette(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, p palette.Palette) (*dbpkg.PaletteDocument, error) {
+			return &dbpkg.PaletteDocument{ID: primitive.NewObjectID(), Palette: p}, nil
+		})
+
+	s := service.NewColourService(logging.NopLogger, db, hc)
+	doc, err := s.PaletteFromHexbot(context.Background(), palette.Complementary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *doc.Seed != seed || doc.Harmony != palette.Complementary || len(doc.Colours) != 2 || doc.Colours[0] != seed {
+		t.Errorf("unexpected palette %+v", doc)
+	}
+}
+
+func TestColourService_Palette_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := service.NewMockDatabase(ctrl)
+	id := primitive.NewObjectID()
+	db.EXPECT().FindPalette(gomock.Any(), id).Return(nil, errors.Wrap(dbpkg.ErrNotFound, "palette"))
+
+	s := service.NewColourService(logging.NopLogger, db, nil)
+	if _, err := s.Palette(context.Background(), id); errors.Cause(err) != service.ErrPaletteNotFound {
+		t.Errorf("expected ErrPaletteNotFound, got %v", err)
+	}
+}
+
+func TestColourService_CreatePalette(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := service.NewMockDatabase(ctrl)
+	db.EXPECT().SavePalette(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, p palette.Palette) (*dbpkg.PaletteDocument, error) {
+			return &dbpkg.PaletteDocument{ID: primitive.NewObjectID(), Palette: p}, nil
+		})
+
+	s := service.NewColourService(logging.NopLogger, db, nil)
+	seed := colour.MustParse("#3A7BD5")
+	doc, err := s.CreatePalette(context.Background(), palette.Palette{Name: "brand", Seed: &seed, Harmony: palette.Triadic})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Name != "brand" || len(doc.Colours) != 3 || doc.Colours[0] != seed {
+		t.Errorf("expected a named triadic palette generated from the seed, got %+v", doc)
+	}
+
+	for _, p := range []palette.Palette{
+		{},
+		{Seed: &seed},
+		{Colours: []colour.Colour{seed}, Names: []string{"a", "b"}},
+		{Colours: []colour.Colour{seed}, Harmony: "clashing"},
+		{Colours: make([]colour.Colour, service.MaxPaletteColours+1)},
+	} {
+		if _, err := s.CreatePalette(context.Background(), p); errors.Cause(err) != service.ErrInvalidPalette {
+			t.Errorf("CreatePalette(%+v) error = %v, want ErrInvalidPalette", p, err)
+		}
+	}
+}
+
+func TestColourService_UpdateAndDeletePalette_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := service.NewMockDatabase(ctrl)
+	id := primitive.NewObjectID()
+	db.EXPECT().UpdatePalette(gomock.Any(), id, gomock.Any()).Return(nil, errors.Wrap(dbpkg.ErrNotFound, "palette"))
+	db.EXPECT().DeletePalette(gomock.Any(), id).Return(errors.Wrap(dbpkg.ErrNotFound, "palette"))
+
+	s := service.NewColourService(logging.NopLogger, db, nil)
+	p := palette.Palette{Colours: []colour.Colour{colour.MustParse("#3A7BD5")}}
+	if _, err := s.UpdatePalette(context.Background(), id, p); errors.Cause(err) != service.ErrPaletteNotFound {
+		t.Errorf("expected ErrPaletteNotFound updating, got %v", err)
+	}
+	if err := s.DeletePalette(context.Background(), id); errors.Cause(err) != service.ErrPaletteNotFound {
+		t.Errorf("expected ErrPaletteNotFound deleting, got %v", err)
+	}
+}
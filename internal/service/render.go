@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"image"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/hexbot"
+	"hexbot/internal/render"
+)
+
+// Canvas fetches a batch of colours that Hexbot places on an opts.Width by opts.Height area and draws
+// each at its coordinates, scaled so the longest side is size pixels. The colours are not saved. A failed
+// fetch is returned as an *UpstreamError.
+func (c *ColourService) Canvas(ctx context.Context, opts hexbot.FetchOptions, size int) (*image.NRGBA, error) {
+	if opts.Width == 0 || opts.Height == 0 {
+		return nil, errors.New("a canvas needs a width and height")
+	}
+	colours, err := c.hexbot.Fetch(ctx, opts)
+	if err != nil {
+		return nil, &UpstreamError{Err: err}
+	}
+	return render.Canvas(colours, opts.Width, opts.Height, size)
+}
@@ -0,0 +1,48 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/hexbot"
+	"hexbot/internal/service"
+)
+
+func TestColourService_Canvas(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hc := service.NewMockHexbotClient(ctrl)
+	opts := hexbot.FetchOptions{Count: 2, Width: 10, Height: 20}
+	hc.EXPECT().Fetch(gomock.Any(), opts).Return([]hexbot.Colour{
+		{Value: colour.MustParse("#D62728"), Coordinates: &hexbot.Coordinates{X: 1, Y: 2}},
+		{Value: colour.MustParse("#1F77B4"), Coordinates: &hexbot.Coordinates{X: 9, Y: 19}},
+	}, nil)
+
+	s := service.NewColourService(logging.NopLogger, nil, hc)
+	img, err := s.Canvas(context.Background(), opts, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 20 || b.Dy() != 40 {
+		t.Errorf("expected a 20x40 canvas, got %v", b)
+	}
+	if p := img.NRGBAAt(2, 4); colour.RGB(p.R, p.G, p.B) != colour.MustParse("#D62728") {
+		t.Errorf("expected the first colour at 2,4, got %v", p)
+	}
+
+	if _, err := s.Canvas(context.Background(), hexbot.FetchOptions{Count: 2}, 0); err == nil || service.IsUpstream(err) {
+		t.Errorf("expected a non-upstream error without a width and height, got %v", err)
+	}
+
+	hc.EXPECT().Fetch(gomock.Any(), opts).Return(nil, hexbot.ErrCircuitOpen)
+	_, err = s.Canvas(context.Background(), opts, 40)
+	if !service.IsUpstream(err) || errors.Cause(err) != hexbot.ErrCircuitOpen {
+		t.Errorf("expected an upstream error wrapping ErrCircuitOpen, got %v", err)
+	}
+}
@@ -4,58 +4,265 @@ package service
 
 import (
 	"context"
+	"sync"
+	"time"
+
 	"github.com/River-Island/product-backbone-v2/logging"
 	"github.com/pkg/errors"
-	"io/ioutil"
-	"net/http"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/db"
+	"hexbot/internal/hexbot"
+	"hexbot/internal/names"
+	"hexbot/internal/palette"
+	"hexbot/internal/requestctx"
 )
 
 type ColourService struct {
 	log       *logging.Logger
-	hexStream []byte
+	colours   []hexbot.Colour
+	requestID string
 	database  Database
+	hexbot    HexbotClient
+	dedup     DedupConfig
+	names     *names.Lookup
+	publisher Publisher
+	observer  Observer
+
+	lastMu    sync.Mutex
+	lastFetch *FetchOutcome
+}
+
+// FetchOutcome is how a call to FetchAndSave went.
+type FetchOutcome struct {
+	RequestID string    `json:"request_id"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+	SaveResult
+	Error string `json:"error,omitempty"`
+}
+
+// Publisher is told about every colour once it has been saved, see WithPublisher.
+type Publisher interface {
+	Publish(docs ...db.ColourDocument)
+}
+
+// Observer is told what happened to every batch saved, such as a metrics.Metrics; see WithObserver.
+type Observer interface {
+	ObserveSave(res SaveResult)
 }
 
 type HexbotClient interface {
-	GetHexString(ctx context.Context) (string, error)
+	Fetch(ctx context.Context, opts hexbot.FetchOptions) ([]hexbot.Colour, error)
 }
 
 type Database interface {
-	Save(ctx context.Context, colourHex string) error
+	Save(ctx context.Context, c colour.Colour, name db.ColourName) (*db.ColourDocument, error)
+	MarkSeen(ctx context.Context, id primitive.ObjectID) error
+	FindColours(ctx context.Context, q db.ColourQuery) (*db.ColourPage, error)
+	FindColoursSince(ctx context.Context, id primitive.ObjectID, limit int) ([]db.ColourDocument, error)
+	EachColour(ctx context.Context, q db.ColourQuery, fn func(db.ColourDocument) error) error
+	SavePalette(ctx context.Context, p palette.Palette) (*db.PaletteDocument, error)
+	FindPalette(ctx context.Context, id primitive.ObjectID) (*db.PaletteDocument, error)
+	FindPalettes(ctx context.Context, q db.PaletteQuery) (*db.PalettePage, error)
+	UpdatePalette(ctx context.Context, id primitive.ObjectID, p palette.Palette) (*db.PaletteDocument, error)
+	DeletePalette(ctx context.Context, id primitive.ObjectID) error
+}
+
+// UpstreamError is returned when fetching from Hexbot fails, as opposed to saving what was fetched.
+// errors.Cause sees through it to the Hexbot error, such as hexbot.ErrCircuitOpen.
+type UpstreamError struct {
+	Err error
+}
+
+func (e *UpstreamError) Error() string {
+	return "problem getting hex from hexbot: " + e.Err.Error()
+}
+
+// Cause returns the Hexbot error.
+func (e *UpstreamError) Cause() error {
+	return e.Err
+}
+
+// IsUpstream reports whether err, or an error it wraps, is an *UpstreamError.
+func IsUpstream(err error) bool {
+	for err != nil {
+		if _, ok := err.(*UpstreamError); ok {
+			return true
+		}
+		cause, ok := err.(interface{ Cause() error })
+		if !ok {
+			return false
+		}
+		err = cause.Cause()
+	}
+	return false
+}
+
+// SaveResult counts what happened to each colour of a batch passed to SaveColour.
+type SaveResult struct {
+	Saved    int `json:"saved"`
+	Rejected int `json:"rejected"`
+	Merged   int `json:"merged"`
 }
 
 func NewColourService(log *logging.Logger, db Database, hc HexbotClient) *ColourService {
-	return &ColourService{log: log, hexStream: []byte{}, database: db}
+	return &ColourService{log: log, database: db, hexbot: hc}
 }
 
-func (c *ColourService) FetchColourFromHexbot(ctx context.Context) (err error) {
-	//create interface for this
-	//pass in as environmental variable or something
-	resp, err := http.Get("https://api.noopschallenge.com/hexbot")
+// FetchColourFromHexbot fetches a batch of colours described by opts, ready to be saved with SaveColour.
+func (c *ColourService) FetchColourFromHexbot(ctx context.Context, opts hexbot.FetchOptions) (err error) {
+	if c.requestID = requestctx.RequestID(ctx); c.requestID == "" {
+		c.requestID = requestctx.NewRequestID()
+	}
+
+	c.colours, err = c.hexbot.Fetch(ctx, opts)
 	if err != nil {
-		return errors.Wrap(err, "problem getting hex from hexbot")
+		return &UpstreamError{Err: err}
 	}
 
-	defer resp.Body.Close()
+	return nil
+}
+
+// Colours returns the batch fetched by the last call to FetchColourFromHexbot.
+func (c *ColourService) Colours() []hexbot.Colour {
+	return c.colours
+}
+
+// SaveColour persists every colour from the last fetch, tagged with the id of the request that fetched them.
+// When deduplication is on, colours close to one saved within the window are rejected or merged instead.
+func (c *ColourService) SaveColour(ctx context.Context) (SaveResult, error) {
+	return c.saveColours(requestctx.WithRequestID(ctx, c.requestID), c.colours)
+}
+
+// FetchAndSave fetches a batch of colours described by opts and saves it as SaveColour would. It keeps
+// no state between calls, so unlike FetchColourFromHexbot and SaveColour it is safe to call from several
+// goroutines. The batch is tagged with the request id carried by ctx, or a new one.
+func (c *ColourService) FetchAndSave(ctx context.Context, opts hexbot.FetchOptions) (res SaveResult, err error) {
+	if requestctx.RequestID(ctx) == "" {
+		ctx = requestctx.WithRequestID(ctx, requestctx.NewRequestID())
+	}
+	started := time.Now().UTC()
+	defer func() {
+		c.recordFetch(FetchOutcome{RequestID: requestctx.RequestID(ctx), StartedAt: started, SaveResult: res}, err)
+	}()
 
-	c.hexStream, err = ioutil.ReadAll(resp.Body)
+	colours, err := c.hexbot.Fetch(ctx, opts)
 	if err != nil {
-		return errors.Wrap(err, "problem reading body of http response from hexbot")
+		return SaveResult{}, &UpstreamError{Err: err}
 	}
+	return c.saveColours(ctx, colours)
+}
 
-	return nil
+func (c *ColourService) recordFetch(o FetchOutcome, err error) {
+	o.EndedAt = time.Now().UTC()
+	if err != nil {
+		o.Error = err.Error()
+	}
+	c.lastMu.Lock()
+	defer c.lastMu.Unlock()
+	c.lastFetch = &o
+}
 
+// LastFetch reports how the most recent FetchAndSave went, nil before the first.
+func (c *ColourService) LastFetch() *FetchOutcome {
+	c.lastMu.Lock()
+	defer c.lastMu.Unlock()
+	if c.lastFetch == nil {
+		return nil
+	}
+	o := *c.lastFetch
+	return &o
 }
 
-func (c *ColourService) SaveColour(ctx context.Context) (err error) {
-	if c.hexStream == nil {
-		return errors.New("trying to save an empty colour string")
+func (c *ColourService) saveColours(ctx context.Context, colours []hexbot.Colour) (res SaveResult, err error) {
+	if c.observer != nil {
+		defer func() { c.observer.ObserveSave(res) }()
 	}
-	colourHex := string(c.hexStream)
+	if len(colours) == 0 {
+		return res, errors.New("trying to save an empty batch of colours")
+	}
+
+	var recent []db.ColourDocument
+	if c.dedup.enabled() {
+		recent, err = c.recentColours(ctx)
+		if err != nil {
+			return res, err
+		}
+	}
+
+	for _, fetched := range colours {
+		if c.dedup.enabled() {
+			if match := c.dedup.nearest(fetched.Value, recent); match != nil {
+				if err := c.resolveDuplicate(ctx, fetched.Value, match, &res); err != nil {
+					return res, err
+				}
+				continue
+			}
+		}
+
+		doc, err := c.database.Save(ctx, fetched.Value, c.name(fetched.Value))
+		if err != nil {
+			return res, errors.Wrap(err, "problem passing colour to database layer")
+		}
+		res.Saved++
+		recent = append(recent, *doc)
+		if c.publisher != nil {
+			c.publisher.Publish(*doc)
+		}
+	}
+	return res, nil
+}
+
+// WithNames makes SaveColour store the nearest name from l alongside every colour.
+func (c *ColourService) WithNames(l *names.Lookup) *ColourService {
+	c.names = l
+	return c
+}
 
-	err = c.database.Save(ctx, colourHex)
+// WithObserver tells o how many colours of every batch were saved, rejected and merged, including
+// those saved before a batch failed part way.
+func (c *ColourService) WithObserver(o Observer) *ColourService {
+	c.observer = o
+	return c
+}
+
+// WithPublisher hands every newly saved colour to p, such as a stream.Broadcaster. Near duplicates
+// rejected or merged by deduplication are not published.
+func (c *ColourService) WithPublisher(p Publisher) *ColourService {
+	c.publisher = p
+	return c
+}
+
+// ColoursSince returns up to limit colours saved after the one with id, oldest first.
+func (c *ColourService) ColoursSince(ctx context.Context, id primitive.ObjectID, limit int) ([]db.ColourDocument, error) {
+	docs, err := c.database.FindColoursSince(ctx, id, limit)
 	if err != nil {
-		return errors.Wrap(err, "problem passing colour string to database layer")
+		return nil, errors.Wrap(err, "problem finding colours from database layer")
 	}
-	return nil
+	return docs, nil
+}
+
+// EachColour calls fn with every saved colour matching q, streaming them from the database rather than
+// loading them all; a zero q.Limit means every one. It stops at the first error from fn.
+func (c *ColourService) EachColour(ctx context.Context, q db.ColourQuery, fn func(db.ColourDocument) error) error {
+	return errors.Wrap(c.database.EachColour(ctx, q, fn), "problem streaming colours from database layer")
+}
+
+func (c *ColourService) name(col colour.Colour) db.ColourName {
+	if c.names == nil {
+		return db.ColourName{}
+	}
+	m := c.names.Nearest(col)
+	return db.ColourName{Name: m.Name, Dictionary: m.Dictionary, Distance: m.Distance}
+}
+
+// ListColours returns a page of saved colours matching q.
+func (c *ColourService) ListColours(ctx context.Context, q db.ColourQuery) (*db.ColourPage, error) {
+	page, err := c.database.FindColours(ctx, q)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem listing colours from database layer")
+	}
+	return page, nil
 }
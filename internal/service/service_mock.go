@@ -7,9 +7,52 @@ package service
 import (
 	context "context"
 	gomock "github.com/golang/mock/gomock"
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+	colour "hexbot/internal/colour"
+	db "hexbot/internal/db"
+	hexbot "hexbot/internal/hexbot"
+	palette "hexbot/internal/palette"
 	reflect "reflect"
 )
 
+// MockHexbotClient is a mock of HexbotClient interface
+type MockHexbotClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockHexbotClientMockRecorder
+}
+
+// MockHexbotClientMockRecorder is the mock recorder for MockHexbotClient
+type MockHexbotClientMockRecorder struct {
+	mock *MockHexbotClient
+}
+
+// NewMockHexbotClient creates a new mock instance
+func NewMockHexbotClient(ctrl *gomock.Controller) *MockHexbotClient {
+	mock := &MockHexbotClient{ctrl: ctrl}
+	mock.recorder = &MockHexbotClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockHexbotClient) EXPECT() *MockHexbotClientMockRecorder {
+	return m.recorder
+}
+
+// Fetch mocks base method
+func (m *MockHexbotClient) Fetch(ctx context.Context, opts hexbot.FetchOptions) ([]hexbot.Colour, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fetch", ctx, opts)
+	ret0, _ := ret[0].([]hexbot.Colour)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Fetch indicates an expected call of Fetch
+func (mr *MockHexbotClientMockRecorder) Fetch(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fetch", reflect.TypeOf((*MockHexbotClient)(nil).Fetch), ctx, opts)
+}
+
 // MockDatabase is a mock of Database interface
 type MockDatabase struct {
 	ctrl     *gomock.Controller
@@ -34,15 +77,148 @@ func (m *MockDatabase) EXPECT() *MockDatabaseMockRecorder {
 }
 
 // Save mocks base method
-func (m *MockDatabase) Save(ctx context.Context, data string) error {
+func (m *MockDatabase) Save(ctx context.Context, c colour.Colour, name db.ColourName) (*db.ColourDocument, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Save", ctx, data)
+	ret := m.ctrl.Call(m, "Save", ctx, c, name)
+	ret0, _ := ret[0].(*db.ColourDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save
+func (mr *MockDatabaseMockRecorder) Save(ctx, c, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockDatabase)(nil).Save), ctx, c, name)
+}
+
+// MarkSeen mocks base method
+func (m *MockDatabase) MarkSeen(ctx context.Context, id primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSeen", ctx, id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Save indicates an expected call of Save
-func (mr *MockDatabaseMockRecorder) Save(ctx, data interface{}) *gomock.Call {
+// MarkSeen indicates an expected call of MarkSeen
+func (mr *MockDatabaseMockRecorder) MarkSeen(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSeen", reflect.TypeOf((*MockDatabase)(nil).MarkSeen), ctx, id)
+}
+
+// FindColours mocks base method
+func (m *MockDatabase) FindColours(ctx context.Context, q db.ColourQuery) (*db.ColourPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindColours", ctx, q)
+	ret0, _ := ret[0].(*db.ColourPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindColours indicates an expected call of FindColours
+func (mr *MockDatabaseMockRecorder) FindColours(ctx, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindColours", reflect.TypeOf((*MockDatabase)(nil).FindColours), ctx, q)
+}
+
+// SavePalette mocks base method
+func (m *MockDatabase) SavePalette(ctx context.Context, p palette.Palette) (*db.PaletteDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePalette", ctx, p)
+	ret0, _ := ret[0].(*db.PaletteDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SavePalette indicates an expected call of SavePalette
+func (mr *MockDatabaseMockRecorder) SavePalette(ctx, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePalette", reflect.TypeOf((*MockDatabase)(nil).SavePalette), ctx, p)
+}
+
+// FindPalette mocks base method
+func (m *MockDatabase) FindPalette(ctx context.Context, id primitive.ObjectID) (*db.PaletteDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPalette", ctx, id)
+	ret0, _ := ret[0].(*db.PaletteDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPalette indicates an expected call of FindPalette
+func (mr *MockDatabaseMockRecorder) FindPalette(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPalette", reflect.TypeOf((*MockDatabase)(nil).FindPalette), ctx, id)
+}
+
+// FindPalettes mocks base method
+func (m *MockDatabase) FindPalettes(ctx context.Context, q db.PaletteQuery) (*db.PalettePage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPalettes", ctx, q)
+	ret0, _ := ret[0].(*db.PalettePage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPalettes indicates an expected call of FindPalettes
+func (mr *MockDatabaseMockRecorder) FindPalettes(ctx, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPalettes", reflect.TypeOf((*MockDatabase)(nil).FindPalettes), ctx, q)
+}
+
+// UpdatePalette mocks base method
+func (m *MockDatabase) UpdatePalette(ctx context.Context, id primitive.ObjectID, p palette.Palette) (*db.PaletteDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePalette", ctx, id, p)
+	ret0, _ := ret[0].(*db.PaletteDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePalette indicates an expected call of UpdatePalette
+func (mr *MockDatabaseMockRecorder) UpdatePalette(ctx, id, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePalette", reflect.TypeOf((*MockDatabase)(nil).UpdatePalette), ctx, id, p)
+}
+
+// DeletePalette mocks base method
+func (m *MockDatabase) DeletePalette(ctx context.Context, id primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePalette", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePalette indicates an expected call of DeletePalette
+func (mr *MockDatabaseMockRecorder) DeletePalette(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePalette", reflect.TypeOf((*MockDatabase)(nil).DeletePalette), ctx, id)
+}
+
+// FindColoursSince mocks base method
+func (m *MockDatabase) FindColoursSince(ctx context.Context, id primitive.ObjectID, limit int) ([]db.ColourDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindColoursSince", ctx, id, limit)
+	ret0, _ := ret[0].([]db.ColourDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindColoursSince indicates an expected call of FindColoursSince
+func (mr *MockDatabaseMockRecorder) FindColoursSince(ctx, id, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindColoursSince", reflect.TypeOf((*MockDatabase)(nil).FindColoursSince), ctx, id, limit)
+}
+
+// EachColour mocks base method
+func (m *MockDatabase) EachColour(ctx context.Context, q db.ColourQuery, fn func(db.ColourDocument) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EachColour", ctx, q, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EachColour indicates an expected call of EachColour
+func (mr *MockDatabaseMockRecorder) EachColour(ctx, q, fn interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockDatabase)(nil).Save), ctx, data)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EachColour", reflect.TypeOf((*MockDatabase)(nil).EachColour), ctx, q, fn)
 }
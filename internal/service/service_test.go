@@ -1,12 +1,247 @@
 package service_test
 
-import "testing"
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/colour"
+	dbpkg "hexbot/internal/db"
+	"hexbot/internal/hexbot"
+	"hexbot/internal/names"
+	"hexbot/internal/requestctx"
+	"hexbot/internal/service"
+)
 
 func TestColourService_FetchColourFromHexbot(t *testing.T) {
-	for tt := range tests {
-		t.run(tt.Desc, func (t *testing.T))
+	tests := []struct {
+		Desc      string
+		Opts      hexbot.FetchOptions
+		Colours   []hexbot.Colour
+		HexbotErr error
+		WantErr   bool
+	}{
+		{
+			Desc:    "saves the fetched colour",
+			Colours: []hexbot.Colour{{Value: colour.MustParse("#A1B2C3")}},
+		},
+		{
+			Desc: "saves every colour in a batch",
+			Opts: hexbot.FetchOptions{Count: 3, Width: 100, Height: 100},
+			Colours: []hexbot.Colour{
+				{Value: colour.MustParse("#A1B2C3"), Coordinates: &hexbot.Coordinates{X: 1, Y: 2}},
+				{Value: colour.MustParse("#000000"), Coordinates: &hexbot.Coordinates{X: 3, Y: 4}},
+				{Value: colour.MustParse("#FFFFFF"), Coordinates: &hexbot.Coordinates{X: 5, Y: 6}},
+			},
+		},
+		{Desc: "returns hexbot errors", HexbotErr: errors.New("boom"), WantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			hc := service.NewMockHexbotClient(ctrl)
+			db := service.NewMockDatabase(ctrl)
+			hc.EXPECT().Fetch(gomock.Any(), tt.Opts).Return(tt.Colours, tt.HexbotErr)
+			for _, c := range tt.Colours {
+				db.EXPECT().Save(gomock.Any(), c.Value, dbpkg.ColourName{}).Return(&dbpkg.ColourDocument{Colour: c.Value}, nil)
+			}
+
+			s := service.NewColourService(logging.NopLogger, db, hc)
+			err := s.FetchColourFromHexbot(context.Background(), tt.Opts)
+			if (err != nil) != tt.WantErr {
+				t.Fatalf("FetchColourFromHexbot() error = %v, wantErr %v", err, tt.WantErr)
+			}
+			if tt.WantErr {
+				return
+			}
+			res, err := s.SaveColour(context.Background())
+			if err != nil {
+				t.Fatalf("SaveColour() error = %v", err)
+			}
+			if res.Saved != len(tt.Colours) {
+				t.Errorf("expected %d colours saved, got %d", len(tt.Colours), res.Saved)
+			}
+		})
+	}
+}
+
+func TestColourService_SaveColour_Empty(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := service.NewColourService(logging.NopLogger, service.NewMockDatabase(ctrl), service.NewMockHexbotClient(ctrl))
+	if _, err := s.SaveColour(context.Background()); err == nil {
+		t.Fatal("expected an error saving before any colour was fetched")
+	}
+}
+
+func TestColourService_SaveColour_Dedup(t *testing.T) {
+	red := colour.MustParse("#FF0000")
+	nearRed := colour.MustParse("#FE0101")
+	blue := colour.MustParse("#0000FF")
+	existing := dbpkg.ColourDocument{ID: primitive.NewObjectID(), Colour: red}
+
+	tests := []struct {
+		Desc string
+		Mode service.DedupMode
+		Want service.SaveResult
+	}{
+		{Desc: "reject", Mode: service.DedupReject, Want: service.SaveResult{Saved: 1, Rejected: 2}},
+		{Desc: "merge", Mode: service.DedupMerge, Want: service.SaveResult{Saved: 1, Merged: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			hc := service.NewMockHexbotClient(ctrl)
+			db := service.NewMockDatabase(ctrl)
+
+			// The second blue is a duplicate of the first, saved earlier in the same batch.
+			batch := []hexbot.Colour{{Value: nearRed}, {Value: blue}, {Value: blue}}
+			hc.EXPECT().Fetch(gomock.Any(), gomock.Any()).Return(batch, nil)
+			db.EXPECT().FindColours(gomock.Any(), gomock.Any()).Return(&dbpkg.ColourPage{Colours: []dbpkg.ColourDocument{existing}}, nil)
+
+			savedBlue := &dbpkg.ColourDocument{ID: primitive.NewObjectID(), Colour: blue}
+			db.EXPECT().Save(gomock.Any(), blue, dbpkg.ColourName{}).Return(savedBlue, nil)
+			if tt.Mode == service.DedupMerge {
+				db.EXPECT().MarkSeen(gomock.Any(), existing.ID).Return(nil)
+				db.EXPECT().MarkSeen(gomock.Any(), savedBlue.ID).Return(nil)
+			}
+
+			pub := &recordingPublisher{}
+			obs := &recordingObserver{}
+			s := service.NewColourService(logging.NopLogger, db, hc).WithDedup(service.DedupConfig{
+				Mode:      tt.Mode,
+				Threshold: 2,
+				Window:    time.Hour,
+			}).WithPublisher(pub).WithObserver(obs)
+			if err := s.FetchColourFromHexbot(context.Background(), hexbot.FetchOptions{Count: 3}); err != nil {
+				t.Fatal(err)
+			}
+			res, err := s.SaveColour(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res != tt.Want {
+				t.Errorf("SaveColour() = %+v, want %+v", res, tt.Want)
+			}
+			if len(pub.docs) != 1 || pub.docs[0].ID != savedBlue.ID {
+				t.Errorf("expected only the saved blue to be published, got %+v", pub.docs)
+			}
+			if len(obs.results) != 1 || obs.results[0] != tt.Want {
+				t.Errorf("expected the batch observed as %+v, got %+v", tt.Want, obs.results)
+			}
+		})
 	}
+}
 
+// recordingPublisher remembers everything published to it.
+type recordingPublisher struct {
+	docs []dbpkg.ColourDocument
 }
 
+func (p *recordingPublisher) Publish(docs ...dbpkg.ColourDocument) {
+	p.docs = append(p.docs, docs...)
+}
+
+// recordingObserver remembers every batch observed.
+type recordingObserver struct {
+	results []service.SaveResult
+}
 
+func (o *recordingObserver) ObserveSave(res service.SaveResult) {
+	o.results = append(o.results, res)
+}
+
+func TestColourService_SaveColour_Names(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hc := service.NewMockHexbotClient(ctrl)
+	db := service.NewMockDatabase(ctrl)
+
+	nearRed := colour.MustParse("#FE0000")
+	hc.EXPECT().Fetch(gomock.Any(), gomock.Any()).Return([]hexbot.Colour{{Value: nearRed}}, nil)
+	db.EXPECT().Save(gomock.Any(), nearRed, gomock.Any()).DoAndReturn(
+		func(_ context.Context, c colour.Colour, name dbpkg.ColourName) (*dbpkg.ColourDocument, error) {
+			if name.Name != "red" || name.Dictionary != names.CSS4 || name.Distance <= 0 {
+				t.Errorf("unexpected name %+v", name)
+			}
+			return &dbpkg.ColourDocument{Colour: c, ColourName: name}, nil
+		})
+
+	css4, err := names.Builtin(names.CSS4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := service.NewColourService(logging.NopLogger, db, hc).WithNames(names.NewLookup(css4))
+	if err := s.FetchColourFromHexbot(context.Background(), hexbot.FetchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.SaveColour(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestColourService_FetchAndSave(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hc := service.NewMockHexbotClient(ctrl)
+	db := service.NewMockDatabase(ctrl)
+	opts := hexbot.FetchOptions{Count: 2}
+	hc.EXPECT().Fetch(gomock.Any(), opts).Return([]hexbot.Colour{
+		{Value: colour.MustParse("#D62728")},
+		{Value: colour.MustParse("#2CA02C")},
+	}, nil)
+	db.EXPECT().Save(gomock.Any(), gomock.Any(), gomock.Any()).Times(2).DoAndReturn(
+		func(ctx context.Context, c colour.Colour, _ dbpkg.ColourName) (*dbpkg.ColourDocument, error) {
+			if id := requestctx.RequestID(ctx); id != "run-1" {
+				t.Errorf("expected the batch tagged run-1, got %q", id)
+			}
+			return &dbpkg.ColourDocument{Colour: c}, nil
+		})
+
+	s := service.NewColourService(logging.NopLogger, db, hc)
+	if s.LastFetch() != nil {
+		t.Errorf("expected no last fetch before the first, got %+v", s.LastFetch())
+	}
+	res, err := s.FetchAndSave(requestctx.WithRequestID(context.Background(), "run-1"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := s.LastFetch(); last == nil || last.RequestID != "run-1" || last.Saved != 2 || last.Error != "" {
+		t.Errorf("expected the last fetch to record run-1 saving 2, got %+v", last)
+	}
+	if res.Saved != 2 {
+		t.Errorf("expected 2 colours saved, got %+v", res)
+	}
+	if len(s.Colours()) != 0 {
+		t.Errorf("expected FetchAndSave to leave the service's batch alone, got %v", s.Colours())
+	}
+
+	hc.EXPECT().Fetch(gomock.Any(), opts).Return(nil, hexbot.ErrCircuitOpen)
+	_, err = s.FetchAndSave(context.Background(), opts)
+	if errors.Cause(err) != hexbot.ErrCircuitOpen || !service.IsUpstream(err) {
+		t.Fatalf("expected an upstream ErrCircuitOpen while the breaker is open, got %v", err)
+	}
+	if last := s.LastFetch(); last == nil || last.Error == "" || last.RequestID == "" {
+		t.Errorf("expected the last fetch to record the failure, got %+v", last)
+	}
+
+	hc.EXPECT().Fetch(gomock.Any(), opts).Return([]hexbot.Colour{{Value: colour.MustParse("#D62728")}}, nil)
+	db.EXPECT().Save(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("connection reset"))
+	if _, err := s.FetchAndSave(context.Background(), opts); err == nil || service.IsUpstream(err) {
+		t.Errorf("expected a failed save not to count as an upstream error, got %v", err)
+	}
+}
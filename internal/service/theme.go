@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/hexbot"
+	"hexbot/internal/theme"
+)
+
+// Theme builds light and dark UI themes from seed whose text meets level.
+func (c *ColourService) Theme(seed colour.Colour, level theme.Level) (*theme.Theme, error) {
+	t, err := theme.Generate(seed, level)
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem building theme from %s", seed)
+	}
+	return t, nil
+}
+
+// ThemeFromHexbot fetches a single colour from Hexbot and builds a theme from it.
+func (c *ColourService) ThemeFromHexbot(ctx context.Context, level theme.Level) (*theme.Theme, error) {
+	colours, err := c.hexbot.Fetch(ctx, hexbot.FetchOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "problem getting seed colour from hexbot")
+	}
+	if len(colours) == 0 {
+		return nil, errors.New("hexbot returned no colours")
+	}
+	return c.Theme(colours[0].Value, level)
+}
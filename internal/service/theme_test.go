@@ -0,0 +1,35 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/golang/mock/gomock"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/hexbot"
+	"hexbot/internal/service"
+	"hexbot/internal/theme"
+)
+
+func TestColourService_ThemeFromHexbot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hc := service.NewMockHexbotClient(ctrl)
+	seed := colour.MustParse("#FFD700")
+	hc.EXPECT().Fetch(gomock.Any(), hexbot.FetchOptions{}).Return([]hexbot.Colour{{Value: seed}}, nil)
+
+	s := service.NewColourService(logging.NopLogger, nil, hc)
+	th, err := s.ThemeFromHexbot(context.Background(), theme.AAA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if th.Seed != seed || th.Level != theme.AAA {
+		t.Errorf("unexpected theme %+v", th)
+	}
+	if r := colour.ContrastRatio(th.Light.OnPrimary, th.Light.Primary); r < colour.ContrastAAA {
+		t.Errorf("light on-primary contrast %.2f is below AAA", r)
+	}
+}
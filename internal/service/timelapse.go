@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"image/gif"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/db"
+	"hexbot/internal/render"
+)
+
+// Grouping decides which saved colours share a timelapse frame.
+type Grouping string
+
+const (
+	// GroupFetch gives every batch fetched from Hexbot its own frame.
+	GroupFetch Grouping = "fetch"
+	// GroupHour gives every hour, in UTC, its own frame.
+	GroupHour Grouping = "hour"
+	// GroupDay gives every day, in UTC, its own frame.
+	GroupDay Grouping = "day"
+)
+
+// Groupings lists every Grouping.
+var Groupings = []Grouping{GroupFetch, GroupHour, GroupDay}
+
+// ParseGrouping validates a grouping name.
+func ParseGrouping(s string) (Grouping, error) {
+	for _, g := range Groupings {
+		if string(g) == s {
+			return g, nil
+		}
+	}
+	return "", errors.Errorf("unknown grouping %q, expected one of %v", s, Groupings)
+}
+
+const (
+	// MaxTimelapseColours caps how many colours Timelapse gathers from its window.
+	MaxTimelapseColours = 10000
+	// DefaultTimelapseWindow is how far back a timelapse reaches when no start is given.
+	DefaultTimelapseWindow = 24 * time.Hour
+)
+
+// ErrInvalidTimelapse is returned when the options can't make a timelapse, such as an empty window or
+// more frames than can be drawn at the size asked for.
+var ErrInvalidTimelapse = errors.New("invalid timelapse")
+
+// TimelapseOptions choose the colours of a timelapse and how it plays.
+type TimelapseOptions struct {
+	// From and To bound when the colours were fetched. To defaults to now and From to
+	// DefaultTimelapseWindow before To.
+	From, To time.Time
+	// Source only animates colours from this source when set.
+	Source string
+	// Group is GroupFetch when empty.
+	Group Grouping
+	// Delay is how long each frame shows, render.DefaultFrameDelay when zero.
+	Delay time.Duration
+	render.Options
+}
+
+// Timelapse animates the colours saved in a time window, oldest first, one frame per group.
+func (c *ColourService) Timelapse(ctx context.Context, opts TimelapseOptions) (*gif.GIF, error) {
+	if opts.To.IsZero() {
+		opts.To = time.Now().UTC()
+	}
+	if opts.From.IsZero() {
+		opts.From = opts.To.Add(-DefaultTimelapseWindow)
+	}
+	if !opts.From.Before(opts.To) {
+		return nil, errors.Wrapf(ErrInvalidTimelapse, "window from %s to %s is empty", opts.From.Format(time.RFC3339), opts.To.Format(time.RFC3339))
+	}
+	if opts.Group == "" {
+		opts.Group = GroupFetch
+	}
+
+	frames, err := c.timelapseFrames(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(frames) == 0 {
+		return nil, errors.Wrapf(ErrNotFound, "no colours saved from %s to %s", opts.From.Format(time.RFC3339), opts.To.Format(time.RFC3339))
+	}
+	g, err := render.Timelapse(frames, opts.Options, opts.Delay)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidTimelapse, err.Error())
+	}
+	return g, nil
+}
+
+// timelapseFrames pages through the window in fetch order, starting a frame whenever the group changes.
+func (c *ColourService) timelapseFrames(ctx context.Context, opts TimelapseOptions) ([][]colour.Colour, error) {
+	q := db.ColourQuery{
+		From:      opts.From,
+		To:        opts.To,
+		Source:    opts.Source,
+		SortBy:    db.SortFetchedAt,
+		Ascending: true,
+		Limit:     db.MaxLimit,
+	}
+
+	var frames [][]colour.Colour
+	var last string
+	var n int
+	for {
+		page, err := c.database.FindColours(ctx, q)
+		if err != nil {
+			return nil, errors.Wrap(err, "problem finding colours for timelapse")
+		}
+		for _, doc := range page.Colours {
+			if n == MaxTimelapseColours {
+				c.log.Warn(fmt.Sprintf("timelapse stopped at %d colours", MaxTimelapseColours))
+				return frames, nil
+			}
+			n++
+			if key := opts.Group.key(doc); frames == nil || key != last {
+				frames = append(frames, nil)
+				last = key
+			}
+			frames[len(frames)-1] = append(frames[len(frames)-1], doc.Colour)
+		}
+		if page.NextCursor == "" {
+			return frames, nil
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+// key identifies the frame doc belongs in. Colours saved without a request id are each a fetch of their own.
+func (g Grouping) key(doc db.ColourDocument) string {
+	switch g {
+	case GroupHour:
+		return doc.FetchedAt.UTC().Truncate(time.Hour).Format(time.RFC3339)
+	case GroupDay:
+		return doc.FetchedAt.UTC().Format("2006-01-02")
+	}
+	if doc.RequestID == "" {
+		return doc.ID.Hex()
+	}
+	return doc.RequestID
+}
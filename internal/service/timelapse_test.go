@@ -0,0 +1,94 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/River-Island/product-backbone-v2/logging"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/colour"
+	dbpkg "hexbot/internal/db"
+	"hexbot/internal/render"
+	"hexbot/internal/service"
+)
+
+func TestColourService_Timelapse(t *testing.T) {
+	day := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	doc := func(hex, requestID string, at time.Duration) dbpkg.ColourDocument {
+		return dbpkg.ColourDocument{ID: primitive.NewObjectID(), Colour: colour.MustParse(hex), RequestID: requestID, FetchedAt: day.Add(at)}
+	}
+	pages := []*dbpkg.ColourPage{
+		{Colours: []dbpkg.ColourDocument{
+			doc("#D62728", "a", 10*time.Minute),
+			doc("#2CA02C", "a", 10*time.Minute),
+			doc("#1F77B4", "b", 50*time.Minute),
+		}, NextCursor: "next"},
+		{Colours: []dbpkg.ColourDocument{
+			doc("#FF7F0E", "c", 70*time.Minute),
+			doc("#9467BD", "", 26*time.Hour),
+		}},
+	}
+
+	tests := []struct {
+		Group  service.Grouping
+		Frames int
+	}{
+		{service.GroupFetch, 4},
+		{service.GroupHour, 3},
+		{service.GroupDay, 2},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.Group), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			db := service.NewMockDatabase(ctrl)
+			from, to := day, day.Add(48*time.Hour)
+			db.EXPECT().FindColours(gomock.Any(), gomock.Any()).Times(2).DoAndReturn(
+				func(_ context.Context, q dbpkg.ColourQuery) (*dbpkg.ColourPage, error) {
+					if !q.From.Equal(from) || !q.To.Equal(to) || !q.Ascending || q.SortBy != dbpkg.SortFetchedAt {
+						t.Errorf("unexpected query %+v", q)
+					}
+					if q.Cursor == "" {
+						return pages[0], nil
+					}
+					return pages[1], nil
+				})
+
+			s := service.NewColourService(logging.NopLogger, db, nil)
+			g, err := s.Timelapse(context.Background(), service.TimelapseOptions{
+				From: from, To: to, Group: tt.Group, Delay: time.Second, Options: render.Options{Size: 4},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(g.Image) != tt.Frames || g.Delay[0] != 100 {
+				t.Errorf("expected %d frames of 100 hundredths, got %d delayed %v", tt.Frames, len(g.Image), g.Delay)
+			}
+		})
+	}
+}
+
+func TestColourService_Timelapse_Empty(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := service.NewMockDatabase(ctrl)
+	db.EXPECT().FindColours(gomock.Any(), gomock.Any()).Return(&dbpkg.ColourPage{}, nil)
+
+	s := service.NewColourService(logging.NopLogger, db, nil)
+	if _, err := s.Timelapse(context.Background(), service.TimelapseOptions{}); errors.Cause(err) != service.ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	now := time.Now()
+	if _, err := s.Timelapse(context.Background(), service.TimelapseOptions{From: now, To: now}); errors.Cause(err) != service.ErrInvalidTimelapse {
+		t.Errorf("expected ErrInvalidTimelapse for an empty window, got %v", err)
+	}
+	if _, err := service.ParseGrouping("week"); err == nil {
+		t.Error("expected an error for an unknown grouping")
+	}
+}
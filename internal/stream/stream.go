@@ -0,0 +1,176 @@
+// Package stream fans freshly saved colours out to live subscribers, such as dashboards following
+// the colours as they arrive.
+package stream
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/db"
+)
+
+// DefaultBuffer is how many colours a subscriber may fall behind by before it is cut off.
+const DefaultBuffer = 256
+
+var (
+	// ErrSlowConsumer ends a subscription that fell more than its buffer behind. The subscriber can
+	// resume from the last colour it received, see db.DB.FindColoursSince.
+	ErrSlowConsumer = errors.New("subscriber fell too far behind")
+	// ErrClosed ends every subscription when the broadcaster closes.
+	ErrClosed = errors.New("stream closed")
+)
+
+// Filter selects the colours a subscriber wants. Zero fields do not filter.
+type Filter struct {
+	// Hue is in degrees. A Min greater than Max wraps through 360, as in db.ColourQuery.
+	Hue    *db.Range
+	Source string
+	// Near and MaxDistance keep colours within a CIEDE2000 ΔE of MaxDistance from Near.
+	Near        *colour.Colour
+	MaxDistance float64
+}
+
+// Match reports whether doc passes f.
+func (f Filter) Match(doc db.ColourDocument) bool {
+	if f.Source != "" && doc.Source != f.Source {
+		return false
+	}
+	if f.Hue != nil {
+		if f.Hue.Min <= f.Hue.Max && (doc.Hue < f.Hue.Min || doc.Hue > f.Hue.Max) {
+			return false
+		}
+		if f.Hue.Min > f.Hue.Max && doc.Hue < f.Hue.Min && doc.Hue > f.Hue.Max {
+			return false
+		}
+	}
+	if f.Near != nil && f.Near.DeltaE2000(doc.Colour) > f.MaxDistance {
+		return false
+	}
+	return true
+}
+
+// Subscription receives the colours matching its filter, in the order they were published.
+type Subscription struct {
+	// C delivers colours until the subscription ends, when it is closed; Err then says why.
+	C <-chan db.ColourDocument
+
+	c      chan db.ColourDocument
+	filter Filter
+	b      *Broadcaster
+
+	mu  sync.Mutex
+	err error
+}
+
+// Err is nil while the subscription is live, then ErrSlowConsumer, ErrClosed or nil after Close.
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close unsubscribes. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.b.remove(s, nil)
+}
+
+// Broadcaster publishes colours to every matching subscription without ever blocking the publisher:
+// a subscriber whose buffer is full is cut off with ErrSlowConsumer instead.
+type Broadcaster struct {
+	buffer int
+
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+// NewBroadcaster returns a broadcaster giving every subscription a buffer of that many colours,
+// DefaultBuffer when zero.
+func NewBroadcaster(buffer int) *Broadcaster {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	return &Broadcaster{buffer: buffer, subs: map[*Subscription]struct{}{}}
+}
+
+// Subscribe starts delivering colours matching f.
+func (b *Broadcaster) Subscribe(f Filter) *Subscription {
+	c := make(chan db.ColourDocument, b.buffer)
+	s := &Subscription{C: c, c: c, filter: f, b: b}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		s.err = ErrClosed
+		close(c)
+		return s
+	}
+	b.subs[s] = struct{}{}
+	return s
+}
+
+// Publish hands docs to every subscription they match.
+func (b *Broadcaster) Publish(docs ...db.ColourDocument) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subs {
+		for _, doc := range docs {
+			if !s.filter.Match(doc) {
+				continue
+			}
+			select {
+			case s.c <- doc:
+			default:
+				b.removeLocked(s, ErrSlowConsumer)
+			}
+			if _, ok := b.subs[s]; !ok {
+				break
+			}
+		}
+	}
+}
+
+// Subscribers is the number of live subscriptions.
+func (b *Broadcaster) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+// Close ends every subscription with ErrClosed and turns later ones away.
+func (b *Broadcaster) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for s := range b.subs {
+		b.removeLocked(s, ErrClosed)
+	}
+}
+
+func (b *Broadcaster) remove(s *Subscription, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.removeLocked(s, err)
+}
+
+func (b *Broadcaster) removeLocked(s *Subscription, err error) {
+	if _, ok := b.subs[s]; !ok {
+		return
+	}
+	delete(b.subs, s)
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+	close(s.c)
+}
+
+// After reports whether the colour with id a was saved after the one with id b. Object ids start with
+// their creation time in seconds, then a per process counter, so the order is exact for colours saved by
+// one replica and to the second across replicas.
+func After(a, b primitive.ObjectID) bool {
+	return bytes.Compare(a[:], b[:]) > 0
+}
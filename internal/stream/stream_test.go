@@ -0,0 +1,101 @@
+package stream_test
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/db"
+	"hexbot/internal/stream"
+)
+
+func doc(hex, source string) db.ColourDocument {
+	c := colour.MustParse(hex)
+	return db.ColourDocument{ID: primitive.NewObjectID(), Colour: c, Hue: c.HSL().H, Source: source}
+}
+
+func TestFilter_Match(t *testing.T) {
+	red := colour.MustParse("#FF0000")
+	tests := []struct {
+		Desc   string
+		Filter stream.Filter
+		Doc    db.ColourDocument
+		Want   bool
+	}{
+		{Desc: "no filter", Doc: doc("#123456", "hexbot"), Want: true},
+		{Desc: "source", Filter: stream.Filter{Source: "offline"}, Doc: doc("#123456", "hexbot"), Want: false},
+		{Desc: "hue inside", Filter: stream.Filter{Hue: &db.Range{Min: 90, Max: 150}}, Doc: doc("#00FF00", ""), Want: true},
+		{Desc: "hue outside", Filter: stream.Filter{Hue: &db.Range{Min: 90, Max: 150}}, Doc: doc("#0000FF", ""), Want: false},
+		{Desc: "hue wrapping", Filter: stream.Filter{Hue: &db.Range{Min: 330, Max: 30}}, Doc: doc("#FF0000", ""), Want: true},
+		{Desc: "hue wrapping outside", Filter: stream.Filter{Hue: &db.Range{Min: 330, Max: 30}}, Doc: doc("#00FF00", ""), Want: false},
+		{Desc: "near", Filter: stream.Filter{Near: &red, MaxDistance: 5}, Doc: doc("#FE0101", ""), Want: true},
+		{Desc: "far", Filter: stream.Filter{Near: &red, MaxDistance: 5}, Doc: doc("#FF8000", ""), Want: false},
+	}
+	for _, tt := range tests {
+		if got := tt.Filter.Match(tt.Doc); got != tt.Want {
+			t.Errorf("%s: Match() = %v, want %v", tt.Desc, got, tt.Want)
+		}
+	}
+}
+
+func TestBroadcaster(t *testing.T) {
+	b := stream.NewBroadcaster(2)
+	all := b.Subscribe(stream.Filter{})
+	offline := b.Subscribe(stream.Filter{Source: "offline"})
+	gone := b.Subscribe(stream.Filter{})
+	gone.Close()
+	gone.Close()
+	if b.Subscribers() != 2 {
+		t.Fatalf("expected 2 subscribers, got %d", b.Subscribers())
+	}
+	if _, ok := <-gone.C; ok || gone.Err() != nil {
+		t.Errorf("expected a closed subscription with no error, got %v", gone.Err())
+	}
+
+	first, second := doc("#FF0000", "hexbot"), doc("#00FF00", "offline")
+	b.Publish(first, second)
+	if got := <-all.C; got.ID != first.ID {
+		t.Errorf("expected %s first, got %s", first.ID.Hex(), got.ID.Hex())
+	}
+	if got := <-all.C; got.ID != second.ID {
+		t.Errorf("expected %s second, got %s", second.ID.Hex(), got.ID.Hex())
+	}
+	if got := <-offline.C; got.ID != second.ID {
+		t.Errorf("expected only the offline colour, got %s", got.ID.Hex())
+	}
+
+	// Neither subscriber reads on, so the third colour overflows both buffers, cutting them off without
+	// blocking the publisher.
+	b.Publish(doc("#000001", "offline"), doc("#000002", "offline"), doc("#000003", "offline"))
+	for range offline.C {
+	}
+	if offline.Err() != stream.ErrSlowConsumer {
+		t.Errorf("expected ErrSlowConsumer, got %v", offline.Err())
+	}
+	if b.Subscribers() != 0 {
+		t.Errorf("expected both subscribers to be cut off, got %d left", b.Subscribers())
+	}
+
+	b.Close()
+	late := b.Subscribe(stream.Filter{})
+	if _, ok := <-late.C; ok || late.Err() != stream.ErrClosed {
+		t.Errorf("expected subscribing after Close to fail with ErrClosed, got %v", late.Err())
+	}
+}
+
+func TestBroadcaster_Close(t *testing.T) {
+	b := stream.NewBroadcaster(0)
+	s := b.Subscribe(stream.Filter{})
+	b.Close()
+	if _, ok := <-s.C; ok || s.Err() != stream.ErrClosed {
+		t.Errorf("expected ErrClosed, got %v", s.Err())
+	}
+}
+
+func TestAfter(t *testing.T) {
+	a, b := primitive.NewObjectID(), primitive.NewObjectID()
+	if !stream.After(b, a) || stream.After(a, b) || stream.After(a, a) {
+		t.Errorf("expected %s to come after %s", b.Hex(), a.Hex())
+	}
+}
@@ -0,0 +1,135 @@
+package theme
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Format is a way of writing a theme out.
+type Format string
+
+const (
+	// FormatJSON writes design tokens in the W3C Design Tokens Community Group format.
+	FormatJSON Format = "json"
+	// FormatCSS writes custom properties, with the dark roles behind prefers-color-scheme.
+	FormatCSS Format = "css"
+	// FormatTailwind writes a tailwind.config.js fragment whose roles refer to the FormatCSS properties.
+	FormatTailwind Format = "tailwind"
+)
+
+// ParseFormat validates a format name.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatJSON, FormatCSS, FormatTailwind:
+		return f, nil
+	}
+	return "", errors.Errorf("unknown theme format %q, expected json, css or tailwind", s)
+}
+
+// Write writes t to w in format f.
+func (t *Theme) Write(w io.Writer, f Format) error {
+	switch f {
+	case FormatJSON:
+		return t.writeTokens(w)
+	case FormatCSS:
+		return t.writeCSS(w)
+	case FormatTailwind:
+		return t.writeTailwind(w)
+	}
+	return errors.Errorf("unknown theme format %q", f)
+}
+
+// scaleNames are the scales in the order they are written out.
+var scaleNames = []string{ScalePrimary, ScaleSecondary, ScaleNeutral}
+
+type token struct {
+	Type  string `json:"$type"`
+	Value string `json:"$value"`
+}
+
+func (t *Theme) writeTokens(w io.Writer) error {
+	colours := map[string]map[string]token{}
+	for _, name := range scaleNames {
+		tones := map[string]token{}
+		for i, step := range Steps {
+			tones[fmt.Sprint(step)] = token{Type: "color", Value: t.Scales[name][i].Hex()}
+		}
+		colours[name] = tones
+	}
+	modes := func(r Roles) map[string]token {
+		m := map[string]token{}
+		for _, ro := range r.list() {
+			m[ro.Name] = token{Type: "color", Value: ro.Colour.Hex()}
+		}
+		return m
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return errors.Wrap(enc.Encode(map[string]interface{}{
+		"color": colours,
+		"light": modes(t.Light),
+		"dark":  modes(t.Dark),
+	}), "problem writing design tokens")
+}
+
+func (t *Theme) writeCSS(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "/* Generated from %s, every text colour meets WCAG %s. */\n", t.Seed.Hex(), t.Level)
+	fmt.Fprintln(bw, ":root {")
+	for _, name := range scaleNames {
+		for i, step := range Steps {
+			fmt.Fprintf(bw, "  --color-%s-%d: %s;\n", name, step, strings.ToLower(t.Scales[name][i].Hex()))
+		}
+	}
+	writeRoles(bw, "  ", t.Light)
+	fmt.Fprintln(bw, "}")
+	fmt.Fprintln(bw)
+	fmt.Fprintln(bw, "@media (prefers-color-scheme: dark) {")
+	fmt.Fprintln(bw, "  :root {")
+	writeRoles(bw, "    ", t.Dark)
+	fmt.Fprintln(bw, "  }")
+	fmt.Fprintln(bw, "}")
+	return errors.Wrap(bw.Flush(), "problem writing css")
+}
+
+func writeRoles(w io.Writer, indent string, r Roles) {
+	for _, ro := range r.list() {
+		fmt.Fprintf(w, "%s--%s: %s;\n", indent, ro.Name, strings.ToLower(ro.Colour.Hex()))
+	}
+}
+
+func (t *Theme) writeTailwind(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "// Roles use the custom properties from the css theme, so they follow prefers-color-scheme.")
+	fmt.Fprintln(bw, "module.exports = {")
+	fmt.Fprintln(bw, "  theme: {")
+	fmt.Fprintln(bw, "    extend: {")
+	fmt.Fprintln(bw, "      colors: {")
+	for _, name := range scaleNames {
+		fmt.Fprintf(bw, "        %s: {\n", name)
+		for i, step := range Steps {
+			fmt.Fprintf(bw, "          %d: '%s',\n", step, strings.ToLower(t.Scales[name][i].Hex()))
+		}
+		if name != ScaleNeutral {
+			fmt.Fprintf(bw, "          DEFAULT: 'var(--%s)',\n", name)
+		}
+		fmt.Fprintln(bw, "        },")
+	}
+	for _, ro := range t.Light.list() {
+		if ro.Name == ScalePrimary || ro.Name == ScaleSecondary {
+			continue
+		}
+		fmt.Fprintf(bw, "        '%s': 'var(--%s)',\n", ro.Name, ro.Name)
+	}
+	fmt.Fprintln(bw, "      },")
+	fmt.Fprintln(bw, "    },")
+	fmt.Fprintln(bw, "  },")
+	fmt.Fprintln(bw, "}")
+	return errors.Wrap(bw.Flush(), "problem writing tailwind config")
+}
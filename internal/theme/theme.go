@@ -0,0 +1,247 @@
+// Package theme turns a single colour into light and dark UI themes whose text colours meet a WCAG
+// contrast level.
+package theme
+
+import (
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"hexbot/internal/colour"
+)
+
+// Level is the WCAG 2.x contrast every foreground and background pair of a theme must meet.
+type Level string
+
+const (
+	AA       Level = "AA"
+	AALarge  Level = "AA-large"
+	AAA      Level = "AAA"
+	AAALarge Level = "AAA-large"
+)
+
+var ratios = map[Level]float64{
+	AA:       colour.ContrastAA,
+	AALarge:  colour.ContrastAALarge,
+	AAA:      colour.ContrastAAA,
+	AAALarge: colour.ContrastAAALarge,
+}
+
+// ParseLevel validates a level, ignoring case.
+func ParseLevel(s string) (Level, error) {
+	for l := range ratios {
+		if strings.EqualFold(string(l), s) {
+			return l, nil
+		}
+	}
+	return "", errors.Errorf("unknown WCAG level %q, expected AA, AA-large, AAA or AAA-large", s)
+}
+
+// Ratio is the minimum contrast ratio the level requires.
+func (l Level) Ratio() float64 {
+	return ratios[l]
+}
+
+// Steps are the tones of every scale, from lightest to darkest.
+var Steps = []int{50, 100, 200, 300, 400, 500, 600, 700, 800, 900}
+
+// stepLightness is the OKLCH lightness of each of Steps.
+var stepLightness = []float64{0.97, 0.93, 0.87, 0.79, 0.70, 0.62, 0.54, 0.46, 0.38, 0.30}
+
+// Scale holds one colour for each of Steps.
+type Scale []colour.Colour
+
+// Tone returns the colour for step, one of Steps.
+func (s Scale) Tone(step int) colour.Colour {
+	for i, st := range Steps {
+		if st == step {
+			return s[i]
+		}
+	}
+	panic(errors.Errorf("no tone %d in scale", step))
+}
+
+// Names of the scales in a theme.
+const (
+	ScalePrimary   = "primary"
+	ScaleSecondary = "secondary"
+	ScaleNeutral   = "neutral"
+)
+
+// Roles are the colours a UI is painted with. Each On colour is for text and icons drawn on the
+// role it is named after.
+type Roles struct {
+	Primary      colour.Colour `json:"primary"`
+	OnPrimary    colour.Colour `json:"on-primary"`
+	Secondary    colour.Colour `json:"secondary"`
+	OnSecondary  colour.Colour `json:"on-secondary"`
+	Surface      colour.Colour `json:"surface"`
+	OnSurface    colour.Colour `json:"on-surface"`
+	Background   colour.Colour `json:"background"`
+	OnBackground colour.Colour `json:"on-background"`
+}
+
+// role is a named colour, in the order themes are written out.
+type role struct {
+	Name   string
+	Colour colour.Colour
+}
+
+func (r Roles) list() []role {
+	return []role{
+		{"primary", r.Primary},
+		{"on-primary", r.OnPrimary},
+		{"secondary", r.Secondary},
+		{"on-secondary", r.OnSecondary},
+		{"surface", r.Surface},
+		{"on-surface", r.OnSurface},
+		{"background", r.Background},
+		{"on-background", r.OnBackground},
+	}
+}
+
+// Colours returns each distinct role colour, in role order, for auditing.
+func (r Roles) Colours() []colour.Colour {
+	var colours []colour.Colour
+	seen := map[colour.Colour]bool{}
+	for _, ro := range r.list() {
+		if !seen[ro.Colour] {
+			seen[ro.Colour] = true
+			colours = append(colours, ro.Colour)
+		}
+	}
+	return colours
+}
+
+// Theme is a light and a dark set of roles built from one seed colour, with the tonal scales
+// they were drawn from.
+type Theme struct {
+	Seed   colour.Colour    `json:"seed"`
+	Level  Level            `json:"level"`
+	Scales map[string]Scale `json:"scales"`
+	Light  Roles            `json:"light"`
+	Dark   Roles            `json:"dark"`
+}
+
+// Secondary colours are the seed rotated by secondaryHue degrees with secondaryChroma of its chroma;
+// neutrals keep the seed's hue with only neutralChroma, so greys are faintly tinted.
+const (
+	secondaryHue    = 60
+	secondaryChroma = 0.6
+	neutralChroma   = 0.015
+)
+
+// Generate builds a theme from seed. Primary and secondary keep their hue and chroma but move in
+// lightness, darker in the light theme and lighter in the dark one, until text on them and they on
+// the background meet level. Text on surfaces and backgrounds moves the same way.
+func Generate(seed colour.Colour, level Level) (*Theme, error) {
+	min := level.Ratio()
+	if min == 0 {
+		return nil, errors.Errorf("unknown WCAG level %q", level)
+	}
+
+	lch := seed.OKLCH()
+	secondary := colour.OKLCH{L: lch.L, C: lch.C * secondaryChroma, H: math.Mod(lch.H+secondaryHue, 360)}
+	neutral := colour.OKLCH{L: lch.L, C: math.Min(lch.C, neutralChroma), H: lch.H}
+
+	t := &Theme{
+		Seed:  seed,
+		Level: level,
+		Scales: map[string]Scale{
+			ScalePrimary:   scale(lch),
+			ScaleSecondary: scale(secondary),
+			ScaleNeutral:   scale(neutral),
+		},
+	}
+
+	var err error
+	if t.Light, err = roles(lch, secondary, neutral, min, false); err != nil {
+		return nil, errors.Wrap(err, "problem building light theme")
+	}
+	if t.Dark, err = roles(lch, secondary, neutral, min, true); err != nil {
+		return nil, errors.Wrap(err, "problem building dark theme")
+	}
+	return t, nil
+}
+
+func scale(c colour.OKLCH) Scale {
+	s := make(Scale, len(Steps))
+	for i, l := range stepLightness {
+		c.L = l
+		s[i] = c.Colour()
+	}
+	return s
+}
+
+// roles picks each role's starting lightness for a light or dark theme, then pushes foregrounds
+// away from their backgrounds until they meet min.
+func roles(primary, secondary, neutral colour.OKLCH, min float64, dark bool) (r Roles, err error) {
+	at := func(c colour.OKLCH, l float64) colour.Colour {
+		c.L = l
+		return c.Colour()
+	}
+
+	if dark {
+		r.Background = at(neutral, 0.16)
+		r.Surface = at(neutral, 0.21)
+		r.OnPrimary = at(neutral, 0.2)
+		r.OnSecondary = r.OnPrimary
+		primary.L = math.Max(primary.L, 0.75)
+		secondary.L = math.Max(secondary.L, 0.79)
+	} else {
+		r.Background = at(neutral, 0.99)
+		r.Surface = at(neutral, 0.96)
+		r.OnPrimary = colour.RGB(255, 255, 255)
+		r.OnSecondary = r.OnPrimary
+		primary.L = math.Min(primary.L, 0.62)
+		secondary.L = math.Min(secondary.L, 0.54)
+	}
+	text := at(neutral, 0.25)
+	if dark {
+		text = at(neutral, 0.93)
+	}
+
+	// In a light theme foregrounds get darker; in a dark one, lighter.
+	if r.Primary, err = push(primary.Colour(), dark, min, r.OnPrimary, r.Background, r.Surface); err != nil {
+		return r, errors.Wrap(err, "primary")
+	}
+	if r.Secondary, err = push(secondary.Colour(), dark, min, r.OnSecondary, r.Background, r.Surface); err != nil {
+		return r, errors.Wrap(err, "secondary")
+	}
+	if r.OnSurface, err = push(text, dark, min, r.Surface, r.Background); err != nil {
+		return r, errors.Wrap(err, "on-surface")
+	}
+	r.OnBackground = r.OnSurface
+	return r, nil
+}
+
+// lightnessStep is how far push moves OKLCH lightness each time contrast falls short.
+const lightnessStep = 0.005
+
+// push moves c's lightness, up when lighter is set and down otherwise, until it meets min
+// against every one of others.
+func push(c colour.Colour, lighter bool, min float64, others ...colour.Colour) (colour.Colour, error) {
+	lch := c.OKLCH()
+	for {
+		ok := true
+		for _, o := range others {
+			if colour.ContrastRatio(c, o) < min {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return c, nil
+		}
+		if lch.L <= 0 || lch.L >= 1 {
+			return c, errors.Errorf("no lightness of %s reaches a contrast of %.1f", c, min)
+		}
+		if lighter {
+			lch.L = math.Min(lch.L+lightnessStep, 1)
+		} else {
+			lch.L = math.Max(lch.L-lightnessStep, 0)
+		}
+		c = lch.Colour()
+	}
+}
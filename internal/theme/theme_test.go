@@ -0,0 +1,104 @@
+package theme_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"hexbot/internal/colour"
+	"hexbot/internal/theme"
+)
+
+func TestGenerate(t *testing.T) {
+	seeds := []string{"#3A7BD5", "#FFFF00", "#101010", "#F0F0F0", "#FF0000", "#808080"}
+	levels := []theme.Level{theme.AA, theme.AAA, theme.AALarge}
+
+	for _, s := range seeds {
+		for _, level := range levels {
+			th, err := theme.Generate(colour.MustParse(s), level)
+			if err != nil {
+				t.Fatalf("Generate(%s, %s) error = %v", s, level, err)
+			}
+			for mode, r := range map[string]theme.Roles{"light": th.Light, "dark": th.Dark} {
+				pairs := [][2]colour.Colour{
+					{r.OnPrimary, r.Primary},
+					{r.OnSecondary, r.Secondary},
+					{r.OnSurface, r.Surface},
+					{r.OnBackground, r.Background},
+					{r.Primary, r.Background},
+				}
+				for _, p := range pairs {
+					if ratio := colour.ContrastRatio(p[0], p[1]); ratio < level.Ratio() {
+						t.Errorf("%s %s theme from %s: %s on %s has contrast %.2f, below %s", s, mode, level, p[0], p[1], ratio, level)
+					}
+				}
+			}
+			if len(th.Scales[theme.ScalePrimary]) != len(theme.Steps) {
+				t.Errorf("expected %d tones, got %d", len(theme.Steps), len(th.Scales[theme.ScalePrimary]))
+			}
+		}
+	}
+}
+
+func TestGenerate_ScaleDarkens(t *testing.T) {
+	th, err := theme.Generate(colour.MustParse("#3A7BD5"), theme.AA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range th.Scales {
+		for i := 1; i < len(s); i++ {
+			if s[i].Luminance() >= s[i-1].Luminance() {
+				t.Errorf("expected every tone darker than the last, got %v", s)
+				break
+			}
+		}
+	}
+}
+
+func TestTheme_Write(t *testing.T) {
+	th, err := theme.Generate(colour.MustParse("#3A7BD5"), theme.AA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := th.Write(&buf, theme.FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	var tokens map[string]map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &tokens); err != nil {
+		t.Fatalf("invalid JSON tokens: %v", err)
+	}
+	if _, ok := tokens["light"]["on-primary"]; !ok {
+		t.Errorf("expected a light on-primary token, got %v", tokens["light"])
+	}
+
+	buf.Reset()
+	if err := th.Write(&buf, theme.FormatCSS); err != nil {
+		t.Fatal(err)
+	}
+	css := buf.String()
+	for _, want := range []string{"--color-primary-50:", "--on-background:", "prefers-color-scheme: dark"} {
+		if !strings.Contains(css, want) {
+			t.Errorf("expected css to contain %q", want)
+		}
+	}
+
+	buf.Reset()
+	if err := th.Write(&buf, theme.FormatTailwind); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "DEFAULT: 'var(--primary)'") {
+		t.Errorf("unexpected tailwind config:\n%s", buf.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	if l, err := theme.ParseLevel("aaa"); err != nil || l != theme.AAA {
+		t.Errorf("ParseLevel(aaa) = %q, %v", l, err)
+	}
+	if _, err := theme.ParseLevel("A"); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
@@ -0,0 +1,277 @@
+// Package websocket is the server side of RFC 6455, just enough to push messages to browsers: it
+// upgrades a request, writes text messages and answers the pings and close frames clients send.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Opcodes of the frames this package handles.
+const (
+	opText  = 0x1
+	opClose = 0x8
+	opPing  = 0x9
+	opPong  = 0xA
+)
+
+// Close status codes.
+const (
+	CloseNormal        = 1000
+	CloseGoingAway     = 1001
+	CloseProtocolError = 1002
+	CloseTooBig        = 1009
+	CloseTryAgainLater = 1013
+)
+
+// MaxMessageSize bounds the messages read from clients, which this package discards anyway.
+const MaxMessageSize = 64 << 10
+
+// acceptGUID is appended to the client's key to prove the server understood the handshake.
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// ErrClosed is returned once either side has closed the connection.
+var ErrClosed = errors.New("websocket closed")
+
+// IsUpgrade reports whether r asks to switch to the WebSocket protocol.
+func IsUpgrade(r *http.Request) bool {
+	return headerHasToken(r.Header, "Connection", "upgrade") && headerHasToken(r.Header, "Upgrade", "websocket")
+}
+
+// Upgrade completes the opening handshake and takes over the connection. When it fails it has already
+// answered r with an error status. Browsers send the page's origin, which must be r's own host or one of
+// origins, such as https://example.com, so that other sites' pages can't connect; requests without an
+// Origin header come from other clients and are let through.
+func Upgrade(w http.ResponseWriter, r *http.Request, origins ...string) (*Conn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	switch {
+	case r.Method != http.MethodGet:
+		http.Error(w, "websocket handshakes must be GET requests", http.StatusMethodNotAllowed)
+		return nil, errors.New("websocket handshake is not a GET")
+	case !allowedOrigin(r, origins):
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return nil, errors.Errorf("websocket handshake from origin %q", r.Header.Get("Origin"))
+	case !IsUpgrade(r):
+		http.Error(w, "expected a websocket upgrade", http.StatusBadRequest)
+		return nil, errors.New("request does not ask for a websocket upgrade")
+	case r.Header.Get("Sec-WebSocket-Version") != "13":
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported websocket version", http.StatusUpgradeRequired)
+		return nil, errors.Errorf("unsupported websocket version %q", r.Header.Get("Sec-WebSocket-Version"))
+	case key == "":
+		http.Error(w, "missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, errors.New("websocket handshake has no key")
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websockets are not supported here", http.StatusInternalServerError)
+		return nil, errors.New("response writer can't be hijacked")
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, errors.Wrap(err, "problem hijacking connection")
+	}
+
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "problem writing websocket handshake")
+	}
+	return &Conn{conn: conn, r: rw.Reader}, nil
+}
+
+// allowedOrigin reports whether r has no Origin header, or one naming r's host or one of origins.
+func allowedOrigin(r *http.Request, origins []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, o := range origins {
+		if strings.EqualFold(strings.TrimSuffix(o, "/"), origin) {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+func acceptKey(key string) string {
+	h := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, v := range h[http.CanonicalHeaderKey(name)] {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Conn is a server side WebSocket connection. Writes may come from any goroutine; reads from one.
+type Conn struct {
+	conn net.Conn
+	r    *bufio.Reader
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// WriteText sends msg as one text frame, failing if it can't be written within timeout, zero meaning no
+// limit, so that a stalled client can't hold up its writer.
+func (c *Conn) WriteText(msg []byte, timeout time.Duration) error {
+	return c.write(opText, msg, timeout)
+}
+
+// Ping sends a ping frame, which clients answer with a pong, keeping idle connections open.
+func (c *Conn) Ping(timeout time.Duration) error {
+	return c.write(opPing, nil, timeout)
+}
+
+// Close sends a close frame with code and reason, then closes the connection.
+func (c *Conn) Close(code int, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+	if len(payload) > 125 {
+		payload = payload[:125]
+	}
+	c.write(opClose, payload, time.Second)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return c.conn.Close()
+}
+
+func (c *Conn) write(op byte, payload []byte, timeout time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | op
+	switch n := len(payload); {
+	case n <= 125:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = header[:4]
+		binary.BigEndian.PutUint16(header[2:], uint16(n))
+	default:
+		header[1] = 127
+		header = header[:10]
+		binary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	c.conn.SetWriteDeadline(deadline)
+	if _, err := c.conn.Write(append(header, payload...)); err != nil {
+		return errors.Wrap(err, "problem writing websocket frame")
+	}
+	return nil
+}
+
+// Discard reads and drops whatever the client sends, answering pings, until the client closes the
+// connection or breaks the protocol. It returns ErrClosed after a clean close.
+func (c *Conn) Discard() error {
+	for {
+		op, payload, err := c.readFrame()
+		if err != nil {
+			if pe, ok := err.(*protocolError); ok {
+				c.Close(pe.code, pe.msg)
+			}
+			return err
+		}
+		switch op {
+		case opPing:
+			if err := c.write(opPong, payload, time.Second); err != nil {
+				return err
+			}
+		case opClose:
+			code := CloseNormal
+			if len(payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(payload))
+			}
+			c.Close(code, "")
+			return ErrClosed
+		}
+	}
+}
+
+type protocolError struct {
+	code int
+	msg  string
+}
+
+func (e *protocolError) Error() string {
+	return "websocket protocol error: " + e.msg
+}
+
+// readFrame reads one frame, checking it is masked as client frames must be.
+func (c *Conn) readFrame() (byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.r, head[:]); err != nil {
+		return 0, nil, errors.Wrap(err, "problem reading websocket frame")
+	}
+	op := head[0] & 0x0F
+	if head[1]&0x80 == 0 {
+		return 0, nil, &protocolError{CloseProtocolError, "client frames must be masked"}
+	}
+
+	n := uint64(head[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return 0, nil, errors.Wrap(err, "problem reading websocket frame")
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return 0, nil, errors.Wrap(err, "problem reading websocket frame")
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if op >= opClose && n > 125 {
+		return 0, nil, &protocolError{CloseProtocolError, "control frames carry at most 125 bytes"}
+	}
+	if n > MaxMessageSize {
+		return 0, nil, &protocolError{CloseTooBig, "message too big"}
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.r, mask[:]); err != nil {
+		return 0, nil, errors.Wrap(err, "problem reading websocket frame")
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return 0, nil, errors.Wrap(err, "problem reading websocket frame")
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return op, payload, nil
+}
@@ -0,0 +1,195 @@
+package websocket_test
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"hexbot/internal/websocket"
+)
+
+// dial performs a client handshake against srv, returning the connection and its reader.
+func dial(t *testing.T, srv *httptest.Server) (net.Conn, *bufio.Reader) {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(conn, "GET / HTTP/1.1\r\nHost: example\r\nConnection: keep-alive, Upgrade\r\nUpgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+	r := bufio.NewReader(conn)
+	res, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The accept key for this nonce is the one worked through in RFC 6455.
+	if res.StatusCode != http.StatusSwitchingProtocols || res.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("unexpected handshake response %d %v", res.StatusCode, res.Header)
+	}
+	return conn, r
+}
+
+func readFrame(t *testing.T, r *bufio.Reader) (byte, []byte) {
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		t.Fatal(err)
+	}
+	n := int(head[1] & 0x7F)
+	if n == 126 {
+		var ext [2]byte
+		io.ReadFull(r, ext[:])
+		n = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatal(err)
+	}
+	return head[0] & 0x0F, payload
+}
+
+func writeFrame(w io.Writer, op byte, payload []byte, masked bool) {
+	frame := []byte{0x80 | op, byte(len(payload))}
+	if !masked {
+		w.Write(append(frame, payload...))
+		return
+	}
+	mask := []byte{1, 2, 3, 4}
+	frame[1] |= 0x80
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	w.Write(frame)
+}
+
+func TestConn(t *testing.T) {
+	done := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !websocket.IsUpgrade(r) {
+			t.Error("expected an upgrade request")
+		}
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.WriteText([]byte(`{"hex":"#FF0000"}`), time.Second)
+		conn.WriteText([]byte(strings.Repeat("x", 300)), time.Second)
+		done <- conn.Discard()
+	}))
+	defer srv.Close()
+
+	conn, r := dial(t, srv)
+	defer conn.Close()
+	if op, msg := readFrame(t, r); op != 0x1 || string(msg) != `{"hex":"#FF0000"}` {
+		t.Errorf("unexpected first message %x %q", op, msg)
+	}
+	if op, msg := readFrame(t, r); op != 0x1 || len(msg) != 300 {
+		t.Errorf("unexpected second message %x of %d bytes", op, len(msg))
+	}
+
+	writeFrame(conn, 0x9, []byte("hi"), true)
+	if op, msg := readFrame(t, r); op != 0xA || string(msg) != "hi" {
+		t.Errorf("expected a pong echoing the ping, got %x %q", op, msg)
+	}
+
+	writeFrame(conn, 0x8, []byte{0x03, 0xE8}, true)
+	if op, msg := readFrame(t, r); op != 0x8 || binary.BigEndian.Uint16(msg) != websocket.CloseNormal {
+		t.Errorf("expected the close to be echoed, got %x %v", op, msg)
+	}
+	if err := <-done; err != websocket.ErrClosed {
+		t.Errorf("expected Discard to end with ErrClosed, got %v", err)
+	}
+}
+
+func TestConn_UnmaskedFrame(t *testing.T) {
+	done := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		done <- conn.Discard()
+	}))
+	defer srv.Close()
+
+	conn, r := dial(t, srv)
+	defer conn.Close()
+	writeFrame(conn, 0x1, []byte("hello"), false)
+	if op, msg := readFrame(t, r); op != 0x8 || binary.BigEndian.Uint16(msg) != websocket.CloseProtocolError {
+		t.Errorf("expected a protocol error close, got %x %v", op, msg)
+	}
+	if err := <-done; err == nil || err == websocket.ErrClosed {
+		t.Errorf("expected a protocol error, got %v", err)
+	}
+}
+
+func TestUpgrade_Rejected(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := websocket.Upgrade(w, r); err == nil {
+			t.Error("expected the upgrade to fail")
+		}
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "8")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusUpgradeRequired || res.Header.Get("Sec-WebSocket-Version") != "13" {
+		t.Errorf("expected 426 asking for version 13, got %d %v", res.StatusCode, res.Header)
+	}
+}
+
+func TestUpgrade_Origin(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r, "https://colours.example.com")
+		if err == nil {
+			conn.Close(websocket.CloseNormal, "")
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		Origin string
+		Want   int
+	}{
+		{Origin: "", Want: http.StatusSwitchingProtocols},
+		{Origin: srv.URL, Want: http.StatusSwitchingProtocols},
+		{Origin: "https://colours.example.com", Want: http.StatusSwitchingProtocols},
+		{Origin: "https://evil.example.com", Want: http.StatusForbidden},
+		{Origin: "null", Want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Origin, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+			req.Header.Set("Connection", "Upgrade")
+			req.Header.Set("Upgrade", "websocket")
+			req.Header.Set("Sec-WebSocket-Version", "13")
+			req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+			if tt.Origin != "" {
+				req.Header.Set("Origin", tt.Origin)
+			}
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if res.StatusCode != tt.Want {
+				t.Errorf("expected %d, got %d", tt.Want, res.StatusCode)
+			}
+		})
+	}
+}